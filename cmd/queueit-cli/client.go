@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"queueit/internal/api"
+	"queueit/internal/db"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how long to wait for a server before falling back to the database
+const probeTimeout = 500 * time.Millisecond
+
+// talks to the /v1 API, of a running server or of the handlers run in
+// this process on top of the database
+type client struct {
+	base  string
+	http  *http.Client
+	local bool
+}
+
+// connects to the server at base, when it isn't running (and the server
+// wasn't asked for explicitly) the database at dbPath is opened instead
+func connect(base string, explicit, local bool, dbPath string) (*client, error) {
+	if !local {
+		c := &client{base: strings.TrimRight(base, "/"), http: &http.Client{Timeout: 30 * time.Second}}
+		probe := &http.Client{Timeout: probeTimeout}
+		resp, err := probe.Get(c.base + "/v1/health")
+		if err == nil {
+			resp.Body.Close()
+			return c, nil
+		}
+		if explicit {
+			return nil, fmt.Errorf("server %s is not reachable: %w", base, err)
+		}
+	}
+
+	if err := db.InitDB(dbPath); err != nil {
+		return nil, fmt.Errorf("opening the database: %w", err)
+	}
+	st := store.NewSQLiteStore(db.GetDBInfo())
+	handler := api.NewRouter(st, events.NewBus(st)).Handler()
+	return &client{base: "http://queueit.local", http: &http.Client{Transport: inProcess{handler}}, local: true}, nil
+}
+
+// serves requests with the API handlers of this process
+type inProcess struct {
+	handler http.Handler
+}
+
+func (t inProcess) RoundTrip(r *http.Request) (*http.Response, error) {
+	// what the server would have set on a request it received
+	r = r.Clone(r.Context())
+	r.RequestURI, r.RemoteAddr = r.URL.RequestURI(), "cli"
+
+	buf := &responseBuffer{header: http.Header{}}
+	t.handler.ServeHTTP(buf, r)
+	if buf.status == 0 {
+		buf.status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", buf.status, http.StatusText(buf.status)),
+		StatusCode:    buf.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        buf.header,
+		Body:          io.NopCloser(&buf.body),
+		ContentLength: int64(buf.body.Len()),
+		Request:       r,
+	}, nil
+}
+
+// the http.ResponseWriter of inProcess, holding the whole response in
+// memory
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+// like a server only the first status counts
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// an error response of the API
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.message, e.status, http.StatusText(e.status))
+}
+
+// sends the request and decodes the response into out (unless nil)
+func (c *client) do(method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.base+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(models.HEADER_ACTOR, "cli")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		// parameter errors are JSON, the rest plain text
+		var e models.ErrorResponse
+		msg := strings.TrimSpace(string(raw))
+		if json.Unmarshal(raw, &e) == nil && e.Error != "" {
+			msg = e.Error
+		}
+		return &apiError{status: resp.StatusCode, message: msg}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// every task of the list at path matching q, following the pages; limit 0
+// means all of them
+func (c *client) listTasks(path string, q url.Values, limit int) ([]models.GetTasksResponse, error) {
+	tasks := []models.GetTasksResponse{}
+	for {
+		// a limit asks for pages, which the server caps at 500 tasks
+		size := 500
+		if limit > 0 {
+			size = min(limit-len(tasks), size)
+		}
+		q.Set("limit", strconv.Itoa(size))
+		var page struct {
+			Items      []models.GetTasksResponse `json:"items"`
+			NextCursor string                    `json:"next_cursor"`
+		}
+		if err := c.do(http.MethodGet, path+"?"+q.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page.Items...)
+		if page.NextCursor == "" || (limit > 0 && len(tasks) >= limit) {
+			return tasks, nil
+		}
+		q.Set("cursor", page.NextCursor)
+	}
+}
+
+func (c *client) listProjects() ([]models.Project, error) {
+	var projects []models.Project
+	err := c.do(http.MethodGet, "/v1/projects?include_archived=true", nil, &projects)
+	return projects, err
+}
+
+func (c *client) getTask(id int64, children bool) (models.GetTasksResponse, error) {
+	var t models.GetTasksResponse
+	path := fmt.Sprintf("/v1/tasks/%d", id)
+	if children {
+		path += "?expand=children"
+	}
+	err := c.do(http.MethodGet, path, nil, &t)
+	return t, err
+}
+
+func (c *client) createTask(req models.CreateTaskRequest) (int64, error) {
+	var resp models.GenricTaskResponse
+	if err := c.do(http.MethodPost, "/v1/tasks", req, &resp); err != nil {
+		return 0, err
+	}
+	return resp.TaskID, nil
+}
+
+func (c *client) updateTask(id int64, req models.UpdateTaskRequest) error {
+	return c.do(http.MethodPatch, fmt.Sprintf("/v1/tasks/%d", id), req, nil)
+}
+
+func (c *client) deleteTask(id int64) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/v1/tasks/%d", id), nil, nil)
+}
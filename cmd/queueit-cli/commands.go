@@ -0,0 +1,382 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"queueit/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	statusNames   = map[int]string{models.STATUS_PENDING: "pending", models.STATUS_WIP: "wip", models.STATUS_DONE: "done", models.STATUS_ARCHIVED: "archived"}
+	priorityNames = map[int]string{models.PRIORITY_HIGH: "high", models.PRIORITY_MEDIUM: "medium", models.PRIORITY_LOW: "low"}
+)
+
+func addCommand(fs *flag.FlagSet) func(*client, *printer, []string) error {
+	desc := fs.String("d", "", "description")
+	priority := fs.String("p", "", "priority: high, medium or low (1-3)")
+	due := fs.String("due", "", "deadline: RFC3339, YYYY-MM-DD, 'YYYY-MM-DD HH:MM', today, tomorrow or +duration like +2h or +3d")
+	tags := fs.String("tag", "", "comma-separated tags")
+	project := fs.String("project", "", "project id or name (default the parent's project, else the Inbox)")
+	parent := fs.Int64("parent", 0, "id of the parent task")
+	repeat := fs.String("repeat", "", "daily, weekly, monthly, yearly or an RRULE like FREQ=WEEKLY;BYDAY=FR, needs --due")
+
+	return func(c *client, out *printer, args []string) error {
+		if len(args) == 0 {
+			return usagef("add needs a title")
+		}
+		req := models.CreateTaskRequest{Title: strings.Join(args, " "), Description: *desc}
+
+		var err error
+		if *priority != "" {
+			if req.Priority, err = parsePriority(*priority); err != nil {
+				return err
+			}
+		}
+		if *due != "" {
+			if req.DeadlineAt, err = parseDue(*due, time.Now()); err != nil {
+				return err
+			}
+		}
+		if *tags != "" {
+			req.Tags = splitList(*tags)
+		}
+		if *project != "" {
+			id, err := resolveProject(c, *project)
+			if err != nil {
+				return err
+			}
+			req.ProjectID = &id
+		}
+		if *parent != 0 {
+			req.ParentTaskID = parent
+		}
+		req.Recurrence = parseRepeat(*repeat)
+
+		id, err := c.createTask(req)
+		if err != nil {
+			return err
+		}
+		t, err := c.getTask(id, false)
+		if err != nil {
+			return err
+		}
+		return out.tasks([]models.GetTasksResponse{t})
+	}
+}
+
+func lsCommand(fs *flag.FlagSet) func(*client, *printer, []string) error {
+	all := fs.Bool("all", false, "every task, done and archived ones too")
+	status := fs.String("status", "", "comma-separated statuses: pending, wip, done, archived (default pending,wip)")
+	priority := fs.String("priority", "", "comma-separated priorities: high, medium, low")
+	tags := fs.String("tag", "", "comma-separated tags, a task needs one of them")
+	project := fs.String("project", "", "project id or name")
+	overdue := fs.Bool("overdue", false, "only tasks past their deadline")
+	sortBy := fs.String("sort", "", "comma-separated sort keys, - for descending, e.g. -priority,deadline_at")
+	limit := fs.Int("limit", 0, "show at most this many tasks (default all)")
+
+	return func(c *client, out *printer, args []string) error {
+		if len(args) > 0 {
+			return usagef("ls takes no arguments, got %q", args)
+		}
+		if *all && *status != "" {
+			return usagef("--all and --status can't be combined")
+		}
+		if *limit < 0 {
+			return usagef("--limit must not be negative")
+		}
+
+		q := url.Values{}
+		switch {
+		case *status != "":
+			statuses, err := parseNames(*status, statusNames, "status")
+			if err != nil {
+				return err
+			}
+			q.Set("status", statuses)
+		case !*all:
+			q.Set("status", fmt.Sprintf("%d,%d", models.STATUS_PENDING, models.STATUS_WIP))
+		}
+		if *priority != "" {
+			priorities, err := parseNames(*priority, priorityNames, "priority")
+			if err != nil {
+				return err
+			}
+			q.Set("priority", priorities)
+		}
+		if *tags != "" {
+			q.Set("tag", *tags)
+		}
+		if *overdue {
+			q.Set("overdue", "true")
+		}
+		if *sortBy != "" {
+			q.Set("sort", *sortBy)
+		}
+
+		path := "/v1/tasks"
+		if *project != "" {
+			id, err := resolveProject(c, *project)
+			if err != nil {
+				return err
+			}
+			path = fmt.Sprintf("/v1/projects/%d/tasks", id)
+		}
+
+		tasks, err := c.listTasks(path, q, *limit)
+		if err != nil {
+			return err
+		}
+		return out.tasks(tasks)
+	}
+}
+
+func showCommand(fs *flag.FlagSet) func(*client, *printer, []string) error {
+	return func(c *client, out *printer, args []string) error {
+		if len(args) != 1 {
+			return usagef("show needs exactly one task id")
+		}
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		t, err := c.getTask(id, true)
+		if err != nil {
+			return err
+		}
+		return out.task(t)
+	}
+}
+
+func editCommand(fs *flag.FlagSet) func(*client, *printer, []string) error {
+	title := fs.String("title", "", "new title")
+	desc := fs.String("d", "", "new description")
+	priority := fs.String("p", "", "new priority: high, medium or low (1-3)")
+	due := fs.String("due", "", "new deadline, in any format add takes")
+	tags := fs.String("tag", "", "comma-separated tags replacing the current ones, '' clears them")
+	repeat := fs.String("repeat", "", "new recurrence as for add, '' stops the task from repeating")
+
+	return func(c *client, out *printer, args []string) error {
+		if len(args) != 1 {
+			return usagef("edit needs exactly one task id")
+		}
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+
+		// only the flags given are changed, so an empty value can clear a field
+		var req models.UpdateTaskRequest
+		var ferr error
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "title":
+				req.Title = title
+			case "d":
+				req.Description = desc
+			case "p":
+				p, err := parsePriority(*priority)
+				ferr = errors.Join(ferr, err)
+				req.Priority = &p
+			case "due":
+				req.DeadlineAt, err = parseDue(*due, time.Now())
+				ferr = errors.Join(ferr, err)
+			case "tag":
+				list := splitList(*tags)
+				req.Tags = &list
+			case "repeat":
+				r := parseRepeat(*repeat)
+				req.Recurrence = &r
+			}
+		})
+		if ferr != nil {
+			return ferr
+		}
+		if req == (models.UpdateTaskRequest{}) {
+			return usagef("edit needs at least one of --title, -d, -p, --due, --tag and --repeat")
+		}
+
+		if err := c.updateTask(id, req); err != nil {
+			return err
+		}
+		t, err := c.getTask(id, false)
+		if err != nil {
+			return err
+		}
+		return out.tasks([]models.GetTasksResponse{t})
+	}
+}
+
+// done, start and archive: sets the status of every task given
+func statusCommand(status int) command {
+	return func(fs *flag.FlagSet) func(*client, *printer, []string) error {
+		return func(c *client, out *printer, args []string) error {
+			return eachTask(c, out, args, func(id int64) (models.GetTasksResponse, error) {
+				if err := c.updateTask(id, models.UpdateTaskRequest{Status: &status}); err != nil {
+					return models.GetTasksResponse{}, err
+				}
+				return c.getTask(id, false)
+			})
+		}
+	}
+}
+
+func rmCommand(fs *flag.FlagSet) func(*client, *printer, []string) error {
+	return func(c *client, out *printer, args []string) error {
+		return eachTask(c, out, args, func(id int64) (models.GetTasksResponse, error) {
+			// read first, a deleted task is gone from /v1/tasks
+			t, err := c.getTask(id, false)
+			if err != nil {
+				return t, err
+			}
+			return t, c.deleteTask(id)
+		})
+	}
+}
+
+// runs fn for every task id in args and prints the tasks it returns, a
+// failing task doesn't stop the others but makes the command fail
+func eachTask(c *client, out *printer, args []string, fn func(id int64) (models.GetTasksResponse, error)) error {
+	if len(args) == 0 {
+		return usagef("expected one or more task ids")
+	}
+	ids := make([]int64, len(args))
+	for i, arg := range args {
+		id, err := parseID(arg)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	var errs []error
+	tasks := []models.GetTasksResponse{}
+	for _, id := range ids {
+		t, err := fn(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %w", id, err))
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	if err := out.tasks(tasks); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+func parseID(raw string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(raw, "#"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid task id %q, expected a positive number", raw)
+	}
+	return id, nil
+}
+
+func parsePriority(raw string) (int, error) {
+	for p, name := range priorityNames {
+		if strings.EqualFold(raw, name) || raw == strconv.Itoa(p) {
+			return p, nil
+		}
+	}
+	return 0, usagef("invalid priority %q, expected high, medium or low", raw)
+}
+
+// turns a comma-separated list of names (or their numbers) into the
+// numbers the API filters by
+func parseNames(raw string, names map[int]string, what string) (string, error) {
+	var out []string
+	for _, v := range splitList(raw) {
+		found := false
+		for n, name := range names {
+			if strings.EqualFold(v, name) || v == strconv.Itoa(n) {
+				out = append(out, strconv.Itoa(n))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", usagef("invalid %s %q", what, v)
+		}
+	}
+	return strings.Join(out, ","), nil
+}
+
+// parses a deadline relative to now, a bare date means local midnight as
+// it does for the API's date filters
+func parseDue(raw string, now time.Time) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	switch strings.ToLower(raw) {
+	case "today":
+		return &day, nil
+	case "tomorrow":
+		t := day.AddDate(0, 0, 1)
+		return &t, nil
+	}
+
+	if rest, ok := strings.CutPrefix(raw, "+"); ok {
+		// time.ParseDuration has no days
+		if days, ok := strings.CutSuffix(rest, "d"); ok {
+			if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+				t := now.AddDate(0, 0, n)
+				return &t, nil
+			}
+		} else if d, err := time.ParseDuration(rest); err == nil && d >= 0 {
+			t := now.Add(d)
+			return &t, nil
+		}
+		return nil, usagef("invalid due %q, expected a duration like +2h or +3d", raw)
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, usagef("invalid due %q, expected RFC3339, YYYY-MM-DD, 'YYYY-MM-DD HH:MM', today, tomorrow or +duration", raw)
+}
+
+// the shorthands for the common rules, anything else is taken as an RRULE
+func parseRepeat(raw string) string {
+	switch strings.ToLower(raw) {
+	case "daily", "weekly", "monthly", "yearly":
+		return "FREQ=" + strings.ToUpper(raw)
+	}
+	return raw
+}
+
+// a project by id, or by name ignoring case
+func resolveProject(c *client, raw string) (int64, error) {
+	if id, err := strconv.ParseInt(raw, 10, 64); err == nil && id > 0 {
+		return id, nil
+	}
+	projects, err := c.listProjects()
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, raw) {
+			return int64(p.ProjectID), nil
+		}
+	}
+	return 0, fmt.Errorf("no project named %q", raw)
+}
+
+func splitList(raw string) []string {
+	list := []string{}
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,168 @@
+// queueit-cli manages tasks from the terminal, through a running queueit
+// server or, when none is running, straight on its database
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"queueit/internal/config"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"sort"
+)
+
+const usage = `usage: queueit-cli <command> [flags] [args]
+
+commands:
+  add <title>           create a task
+  ls                    list tasks, pending and in progress ones by default
+  show <id>             show a task with its subtasks
+  edit <id>             change the fields given by flags
+  done <id>...          mark tasks as done
+  start <id>...         mark tasks as in progress
+  archive <id>...       archive tasks
+  rm <id>...            move tasks to the trash
+
+every command takes:
+  --json                print JSON instead of a table
+  --format <template>   print every task with a Go template, e.g. '{{.TaskID}} {{.Title}}'
+  --server <url>        server to talk to (default $QUEUEIT_SERVER, else the one the server's config names)
+  --local               use the database even when a server is running
+  --debug               log to stderr
+
+run queueit-cli <command> -h for the flags of a command
+`
+
+// a subcommand defines its flags on fs and returns what runs once they are
+// parsed, with the arguments left
+type command func(fs *flag.FlagSet) func(c *client, out *printer, args []string) error
+
+var commands = map[string]command{
+	"add":     addCommand,
+	"ls":      lsCommand,
+	"show":    showCommand,
+	"edit":    editCommand,
+	"done":    statusCommand(models.STATUS_DONE),
+	"start":   statusCommand(models.STATUS_WIP),
+	"archive": statusCommand(models.STATUS_ARCHIVED),
+	"rm":      rmCommand,
+}
+
+// an error in how the command was invoked, exits with 2 rather than 1
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	err := run(os.Args[1], os.Args[2:])
+	var uerr *usageError
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.As(err, &uerr):
+		fmt.Fprintln(os.Stderr, "queueit-cli:", err)
+		fmt.Fprintf(os.Stderr, "run queueit-cli %s -h for usage\n", os.Args[1])
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "queueit-cli:", err)
+		os.Exit(1)
+	}
+}
+
+func run(name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return usagef("unknown command %q, expected one of %v", name, names)
+	}
+
+	fs := flag.NewFlagSet("queueit-cli "+name, flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	format := fs.String("format", "", "print every task with this Go template")
+	server := fs.String("server", "", "server URL")
+	local := fs.Bool("local", false, "use the database even when a server is running")
+	debug := fs.Bool("debug", false, "log to stderr")
+	exec := cmd(fs)
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{err.Error()}
+	}
+	if *asJSON && *format != "" {
+		return usagef("--json and --format can't be combined")
+	}
+	if *local && *server != "" {
+		return usagef("--local and --server can't be combined")
+	}
+
+	logger.SetOutput(io.Discard)
+	if *debug {
+		logger.SetOutput(os.Stderr)
+		logger.SetLevel(slog.LevelDebug)
+	}
+
+	out, err := newPrinter(os.Stdout, *asJSON, *format)
+	if err != nil {
+		return &usageError{err.Error()}
+	}
+
+	// the server's settings say where it listens and where its database is
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+
+	base, explicit := *server, *server != ""
+	if base == "" {
+		base = os.Getenv("QUEUEIT_SERVER")
+	}
+	if base == "" {
+		base = cfg.URL()
+	}
+	c, err := connect(base, explicit, *local, cfg.Database.Path)
+	if err != nil {
+		return err
+	}
+	return exec(c, out, positional)
+}
+
+// parses the flags wherever they are among the arguments, so
+// "add 'buy milk' -p high" works as well as "add -p high 'buy milk'"
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		// Parse drops a "--", everything after it is an argument
+		parsed := len(args) - len(rest)
+		if len(rest) == 0 || (parsed > 0 && args[parsed-1] == "--") {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
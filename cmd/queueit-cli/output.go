@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"queueit/internal/models"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// the longest title a table shows before cutting it short
+const maxTitleWidth = 60
+
+// prints tasks as a table, as JSON or through a template
+type printer struct {
+	w        io.Writer
+	json     bool
+	template *template.Template
+}
+
+// the functions --format templates get on top of the builtin ones
+var templateFuncs = template.FuncMap{
+	"status":   func(s int) string { return statusName(s) },
+	"priority": func(p int) string { return priorityName(p) },
+	"join":     strings.Join,
+	"due":      func(t *time.Time) string { return formatDue(t) },
+}
+
+func newPrinter(w io.Writer, asJSON bool, format string) (*printer, error) {
+	p := &printer{w: w, json: asJSON}
+	if format != "" {
+		// "\n" typed in a shell arrives as two characters
+		format = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(format)
+		tmpl, err := template.New("format").Funcs(templateFuncs).Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --format: %w", err)
+		}
+		p.template = tmpl
+	}
+	return p, nil
+}
+
+// prints a list of tasks, one row or template run each
+func (p *printer) tasks(tasks []models.GetTasksResponse) error {
+	switch {
+	case p.json:
+		return p.writeJSON(tasks)
+	case p.template != nil:
+		return p.execute(tasks)
+	}
+	if len(tasks) == 0 {
+		_, err := fmt.Fprintln(p.w, "no tasks")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tPRI\tTITLE\tDUE\tTAGS")
+	for _, t := range tasks {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			t.TaskID, statusName(t.Status), priorityName(t.Priority),
+			truncate(t.Title, maxTitleWidth), formatDue(t.DeadlineAt), strings.Join(t.Tags, ","))
+	}
+	return tw.Flush()
+}
+
+// prints every detail of one task followed by its subtasks
+func (p *printer) task(t models.GetTasksResponse) error {
+	switch {
+	case p.json:
+		return p.writeJSON(t)
+	case p.template != nil:
+		return p.execute([]models.GetTasksResponse{t})
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", label, value)
+		}
+	}
+	row("ID", strconv.Itoa(t.TaskID))
+	row("Title", t.Title)
+	row("Status", statusName(t.Status))
+	row("Priority", priorityName(t.Priority))
+	row("Project", strconv.Itoa(t.ProjectID))
+	if t.ParentTaskID != nil {
+		row("Parent", strconv.Itoa(*t.ParentTaskID))
+	}
+	row("Tags", strings.Join(t.Tags, ", "))
+	row("Due", formatDue(t.DeadlineAt))
+	row("Repeats", t.Recurrence)
+	if t.Progress != nil && t.Progress.Total > 0 {
+		row("Progress", fmt.Sprintf("%d/%d done", t.Progress.Done, t.Progress.Total))
+	}
+	row("Created", t.CreatedAt.Local().Format(time.DateTime))
+	row("Updated", t.UpdatedAt.Local().Format(time.DateTime))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if t.Description != "" {
+		fmt.Fprintf(p.w, "\n%s\n", t.Description)
+	}
+	if len(t.Children) > 0 {
+		fmt.Fprintf(p.w, "\nSubtasks:\n")
+		return p.tasks(t.Children)
+	}
+	return nil
+}
+
+func (p *printer) writeJSON(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// runs the template once per task, adding a newline unless it ends in one
+func (p *printer) execute(tasks []models.GetTasksResponse) error {
+	for _, t := range tasks {
+		var b strings.Builder
+		if err := p.template.Execute(&b, t); err != nil {
+			return err
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		if _, err := io.WriteString(p.w, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func statusName(s int) string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(s)
+}
+
+func priorityName(p int) string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return strconv.Itoa(p)
+}
+
+// a deadline in local time, without the time of day when it is midnight
+func formatDue(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	local := t.Local()
+	if local.Hour() == 0 && local.Minute() == 0 && local.Second() == 0 {
+		return local.Format(time.DateOnly)
+	}
+	return local.Format("2006-01-02 15:04")
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -1,42 +1,203 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"queueit/internal/api"
 	"queueit/internal/config"
 	"queueit/internal/db"
+	"queueit/internal/events"
+	"queueit/internal/reminder"
+	"queueit/internal/store"
+	"queueit/internal/trash"
+	"queueit/internal/webhook"
 	"queueit/pkg/logger"
-	"time"
+	"sync"
+	"syscall"
 
 	webview "github.com/webview/webview_go"
 )
 
 func main() {
-	if err := config.LoadConfig(); err != nil {
-		logger.Fatal(err)
+	headless := flag.Bool("headless", false, "only run the HTTP API, without opening the window")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [serve]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "serve is the same as --headless, for running queueit as a service")
+		fmt.Fprintln(flag.CommandLine.Output(), "flags override environment variables, which override the config file")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	switch flag.Arg(0) {
+	case "":
+	case "serve":
+		*headless = true
+		// flags may follow the subcommand too
+		flag.CommandLine.Parse(flag.Args()[1:])
+		if flag.NArg() > 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(flag.CommandLine)
+	if err != nil {
+		logger.Fatal(err, "main ~ startup failed")
+	}
+	if err := logger.Setup(loggerOptions(cfg)); err != nil {
+		logger.Fatal(err, "main ~ startup failed")
+	}
+	defer logger.Close()
+	if cfg.File != "" {
+		logger.Info("config file", cfg.File)
 	}
 
-	if err := db.InitDB(); err != nil {
-		logger.Fatal(err)
+	if err := db.InitDB(cfg.Database.Path); err != nil {
+		logger.Fatal(err, "main ~ startup failed")
+	}
+	if version, err := db.GetDBInfo().SchemaVersion(); err == nil {
+		logger.Info("database schema version", version)
 	}
 
-	router := api.NewRouter()
+	st := store.NewSQLiteStore(db.GetDBInfo())
+
+	// done on SIGINT or SIGTERM, when the window is closed or when the
+	// server fails
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// the background workers outlive the server by a bit, so the requests
+	// it drains on shutdown still have their changes delivered
+	work, stopWork := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	// the dispatcher follows the same bus the handlers publish task changes
+	// on, posting them to the registered webhooks
+	bus := events.NewBus(st)
+	if cfg.Features.Webhooks {
+		workers.Go(func() { webhook.NewDispatcher(st, bus).Run(work) })
+	}
+
+	// deleted tasks are purged for good after the trash retention
+	workers.Go(func() { trash.NewPurger(st, cfg.Trash.Retention).Run(work) })
+
+	// the API takes connections from here on, the window can load it
+	router := api.NewRouter(st, bus)
+	ln, err := router.Listen(cfg.Addr())
+	if err != nil {
+		logger.Fatal(err, "main ~ startup failed")
+	}
+	served := make(chan error, 1)
 	go func() {
-		if err := router.StartServer(); err != nil {
-			logger.Fatal(err)
-		}
+		served <- router.Serve(ctx, ln)
+		cancel()
 	}()
 
-	// Wait a moment to ensure server starts
-	time.Sleep(500 * time.Millisecond)
+	actions := reminder.NewTaskAPI(cfg.URL())
+
+	switch {
+	case *headless && !cfg.Features.Reminders:
+		logger.Info("running headless, stop with SIGINT or SIGTERM")
+		<-ctx.Done()
+	case *headless:
+		// without a window reminders go to the desktop when there is one,
+		// and to the log otherwise
+		notifier := reminder.Notifier(reminder.LogNotifier{})
+		if desktop, err := reminder.ConnectDesktop(actions); err != nil {
+			logger.Info("desktop notifications unavailable, logging reminders:", err)
+		} else {
+			defer desktop.Close()
+			notifier = reminder.Fallback{desktop, reminder.LogNotifier{}}
+		}
+		workers.Go(func() { reminder.NewScheduler(st, notifier).Run(work) })
+
+		logger.Info("running headless, stop with SIGINT or SIGTERM")
+		<-ctx.Done()
+	default:
+		runWindow(ctx, cfg, st, actions)
+	}
+	// stops the server if the window was closed, and lets a second signal
+	// kill a shutdown that hangs
+	cancel()
 
-	// Start WebView window
+	if err := <-served; err != nil {
+		logger.Error(err, "main ~ serving the API failed")
+	}
+	stopWork()
+	workers.Wait()
+
+	if err := db.GetDBInfo().Close(); err != nil {
+		logger.Error(err, "main ~ closing the database failed")
+	}
+	logger.Info("queueit stopped")
+}
+
+// the logger setup of the settings
+func loggerOptions(cfg *config.Config) logger.Options {
+	return logger.Options{
+		Level:    cfg.Log.Level,
+		Format:   cfg.Log.Format,
+		File:     cfg.Log.File,
+		MaxSize:  int64(cfg.Log.MaxSizeMB) << 20,
+		MaxFiles: cfg.Log.MaxFiles,
+	}
+}
+
+// shows the app until the window is closed or ctx is done, along with the
+// reminders of st unless they are turned off
+func runWindow(ctx context.Context, cfg *config.Config, st store.ReminderStore, actions *reminder.TaskAPI) {
 	w := webview.New(false)
 	defer w.Destroy()
 	w.SetTitle("queueit")
 	w.SetSize(1200, 800, webview.Hint(0))
 
-	w.Navigate(fmt.Sprintf("http://%s:%s", os.Getenv("SERVER_IP"), os.Getenv("SERVER_PORT")))
+	// reminders keep firing for as long as the window is open, as desktop
+	// notifications where there is a notification server and as in-app
+	// toasts otherwise
+	toast := reminder.NewToastNotifier(func(js string) {
+		w.Dispatch(func() { w.Eval(js) })
+	})
+	w.Bind(reminder.ToastReadyBinding, toast.Ready)
+	w.Bind(reminder.ToastActionBinding, func(action string, taskID int64) error {
+		return actions.Do(ctx, action, taskID)
+	})
+
+	notifier := reminder.Fallback{toast}
+	if desktop, err := reminder.ConnectDesktop(actions); err != nil {
+		logger.Info("desktop notifications unavailable, using in-app toasts:", err)
+	} else {
+		defer desktop.Close()
+		notifier = reminder.Fallback{desktop, toast}
+	}
+
+	// stopped before the window and the desktop connection go away
+	reminders, stopReminders := context.WithCancel(ctx)
+	var scheduler sync.WaitGroup
+	if cfg.Features.Reminders {
+		scheduler.Go(func() { reminder.NewScheduler(st, notifier).Run(reminders) })
+	}
+	defer func() {
+		stopReminders()
+		scheduler.Wait()
+	}()
+
+	// a signal or a failing server closes the window
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.Dispatch(w.Terminate)
+		case <-closed:
+		}
+	}()
+
+	w.Navigate(cfg.URL())
 	w.Run()
 }
@@ -15,9 +15,101 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/activity": {
+            "get": {
+                "description": "Every task created, updated or deleted through the API, newest first, one entry per task with the old and new value of each changed field. Changes cascading down a subtree (archiving, moving, deleting) have an entry for each task they touched.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Activity"
+                ],
+                "summary": "The activity log of every task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ActivityPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid paging value",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching activity failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/events": {
+            "get": {
+                "description": "Server-Sent Events stream of task.created, task.updated and task.deleted events, each carrying the full task (as it was before deletion for task.deleted) with the event id as the SSE id. Reconnect with the Last-Event-ID header (or last_event_id) to resume, without either the stream starts at the next change. A reset event means the requested events have been pruned from the log, refetch the tasks.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Stream task changes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event, for clients that can't set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One data line per event",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Streaming not supported or reading the event log failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/health": {
             "get": {
-                "description": "Returns the server health status along with version and uptime",
+                "description": "Returns the server health status along with version, uptime and database schema version",
                 "produces": [
                     "application/json"
                 ],
@@ -41,30 +133,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/tasks": {
+        "/v1/projects": {
             "get": {
-                "description": "Fetch all tasks, optionally filtering by status and/or priority",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fetch every project along with its task count, archived projects only when asked for",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Projects"
                 ],
-                "summary": "Get all tasks",
+                "summary": "Get all projects",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Comma-separated priority values to filter (1=high,2=medium,3=low,0=default(medium))",
-                        "name": "priority",
+                        "type": "boolean",
+                        "description": "Include archived projects",
+                        "name": "include_archived",
                         "in": "query"
                     }
                 ],
@@ -74,18 +157,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.GetTasksResponse"
+                                "$ref": "#/definitions/models.Project"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
                     "500": {
-                        "description": "Fetching tasks failed",
+                        "description": "Fetching projects failed",
                         "schema": {
                             "type": "string"
                         }
@@ -93,7 +170,6 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new task with title, description, priority, and optional deadline",
                 "consumes": [
                     "application/json"
                 ],
@@ -101,25 +177,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Projects"
                 ],
-                "summary": "Create a new task",
+                "summary": "Create a new project",
                 "parameters": [
                     {
-                        "description": "Task to create",
-                        "name": "task",
+                        "description": "Project to create",
+                        "name": "project",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateTaskRequest"
+                            "$ref": "#/definitions/models.CreateProjectRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task created successfully",
+                        "description": "Project created successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.GenricTaskResponse"
+                            "$ref": "#/definitions/models.GenricProjectResponse"
                         }
                     },
                     "400": {
@@ -128,14 +204,20 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "409": {
+                        "description": "Project already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "422": {
-                        "description": "Unprocessable entity (blank title)",
+                        "description": "Unprocessable entity (blank name)",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "500": {
-                        "description": "Creating task failed",
+                        "description": "Creating project failed",
                         "schema": {
                             "type": "string"
                         }
@@ -143,23 +225,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/tasks/{id}": {
+        "/v1/projects/{id}": {
             "get": {
-                "description": "Fetch a single task record from the database using its unique ID.",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Projects"
                 ],
-                "summary": "Get task details by ID",
+                "summary": "Get project details by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -167,19 +245,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task details fetched successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.GetTasksResponse"
+                            "$ref": "#/definitions/models.Project"
                         }
                     },
                     "400": {
-                        "description": "Invalid or missing task ID",
+                        "description": "Invalid or missing project ID",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Task not found",
+                        "description": "Project not found",
                         "schema": {
                             "type": "string"
                         }
@@ -193,21 +271,18 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes a task from the database using its unique ID. Returns 404 if the task does not exist.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Deletes the project, its tasks are moved to the Inbox. The Inbox itself cannot be deleted.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Projects"
                 ],
-                "summary": "Delete a task by ID",
+                "summary": "Delete a project by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -215,19 +290,25 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task deleted successfully",
+                        "description": "Project deleted successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.GenricTaskResponse"
+                            "$ref": "#/definitions/models.GenricProjectResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid or missing task ID",
+                        "description": "Invalid or missing project ID",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Task not found",
+                        "description": "Project not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "The Inbox cannot be deleted",
                         "schema": {
                             "type": "string"
                         }
@@ -241,7 +322,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Partially update one or more fields of a task (title, description, status, priority, deadline).",
+                "description": "Partially update name, color, default priority or archived flag. The Inbox cannot be archived.",
                 "consumes": [
                     "application/json"
                 ],
@@ -249,32 +330,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tasks"
+                    "Projects"
                 ],
-                "summary": "Update task fields by ID",
+                "summary": "Update project fields by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Task ID",
+                        "description": "Project ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "description": "Fields to update",
-                        "name": "task",
+                        "name": "project",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                            "$ref": "#/definitions/models.UpdateProjectRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Task updated successfully",
+                        "description": "Project updated successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.GenricTaskResponse"
+                            "$ref": "#/definitions/models.GenricProjectResponse"
                         }
                     },
                     "400": {
@@ -283,6 +364,18 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Project name already in use, or archiving the Inbox",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -291,74 +384,2826 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.healthResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "type": "string"
-                },
-                "uptime": {
-                    "type": "string"
-                },
-                "version": {
-                    "type": "string"
+        },
+        "/v1/projects/{id}/tasks": {
+            "get": {
+                "description": "Fetch the tasks of one project, accepts the same filters, paging, sort and fields as /v1/tasks and answers the same way: a JSON array of every task, or a page of them when limit or cursor is given",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Projects"
+                ],
+                "summary": "Get the tasks of a project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated priority values to filter (1=high,2=medium,3=low)",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tag names to filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deadline at or after this RFC3339 time or YYYY-MM-DD date",
+                        "name": "deadline_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deadline before this RFC3339 time or YYYY-MM-DD date",
+                        "name": "deadline_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after this RFC3339 time or YYYY-MM-DD date",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last changed at or after this RFC3339 time or YYYY-MM-DD date",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last changed before this RFC3339 time or YYYY-MM-DD date",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true for tasks past their deadline that are neither done nor archived, false for every other task",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true for tasks with a deadline, false for tasks without one",
+                        "name": "has_deadline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 100), answers with a models.TaskPage",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, answers with a models.TaskPage",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys, - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return per task",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Every matching task, or a models.TaskPage when limit or cursor is given",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GetTasksResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid project ID, filter, paging or field value",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Project not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching tasks failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 }
             }
         },
-        "models.CreateTaskRequest": {
-            "type": "object",
+        "/v1/redo": {
+            "post": {
+                "description": "Replays the operations the session undid, the most recently undone first, as long as the tasks still look the way the undo left them. The redo history is dropped as soon as the session makes a new change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Activity"
+                ],
+                "summary": "Redo the last undone operations of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client session the operations were made in",
+                        "name": "X-Queueit-Session",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of operations, 1-50 (default 1)",
+                        "name": "steps",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The operations redone",
+                        "schema": {
+                            "$ref": "#/definitions/models.UndoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing session or invalid steps",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nothing to redo, or the next operation conflicts with a later change",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Redo failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/reminders/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminders"
+                ],
+                "summary": "Delete a reminder by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reminder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reminder deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid reminder ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reminder not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/search": {
+            "get": {
+                "description": "Full-text search over task titles and descriptions, best match first. The query is made of words and \"quoted phrases\", either may end in * to match by prefix, and every term has to match. Accepts the filters, paging and fields of /v1/tasks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Search tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, e.g. milk \\",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated priority values to filter (1=high,2=medium,3=low)",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tag names to filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) or all",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, only valid with the same sort",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys, - for descending, rank (default) or any /v1/tasks sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return per result, e.g. task_id,highlight",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or empty query, invalid filter, paging or field value",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Searching tasks failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tags": {
+            "get": {
+                "description": "Fetch every tag along with the number of tasks carrying it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Get all tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tag"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching tags failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Tag names are case-insensitive and may not contain commas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Create a new tag",
+                "parameters": [
+                    {
+                        "description": "Tag to create",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Tag already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable entity (invalid name)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Creating tag failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tags/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Get tag details by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or missing tag ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the tag and detaches it from every task, the tasks themselves are kept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Delete a tag by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or missing tag ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Rename or recolor a tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or missing ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Tag name already in use",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks": {
+            "get": {
+                "description": "Fetch tasks, optionally filtering by status, priority, tags and dates. Without limit and cursor every matching task comes back as a JSON array, as it always did. Giving either of them pages through the tasks instead: the response is then a models.TaskPage object, {\"items\": [...], \"next_cursor\": \"...\"}; follow next_cursor for the next page, it is absent on the last one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get all tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated priority values to filter (1=high,2=medium,3=low)",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tag names to filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "any (default) matches tasks with at least one of the tags, all matches tasks carrying every tag",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deadline at or after this RFC3339 time or YYYY-MM-DD date",
+                        "name": "deadline_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deadline before this RFC3339 time or YYYY-MM-DD date",
+                        "name": "deadline_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after this RFC3339 time or YYYY-MM-DD date",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last changed at or after this RFC3339 time or YYYY-MM-DD date",
+                        "name": "updated_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last changed before this RFC3339 time or YYYY-MM-DD date",
+                        "name": "updated_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true for tasks past their deadline that are neither done nor archived, false for every other task",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true for tasks with a deadline, false for tasks without one",
+                        "name": "has_deadline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 100), answers with a models.TaskPage",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, only valid with the same sort, answers with a models.TaskPage",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys, - for descending (task_id, title, status, priority, project_id, created_at, updated_at, deadline_at), e.g. -priority,deadline_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return per task, e.g. task_id,title",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Every matching task, or a models.TaskPage when limit or cursor is given",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GetTasksResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, paging or field value, names the offending parameter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching tasks failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new task with title, description, priority, and optional deadline, project, parent task, tags and recurrence rule. Unknown tag names are created. Without a priority the project's default priority is used. A recurring task needs a deadline, its first occurrence.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Create a new task",
+                "parameters": [
+                    {
+                        "description": "Task to create",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable entity (blank title, unknown parent or project, invalid tag or recurrence rule)",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Creating task failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/bulk": {
+            "post": {
+                "description": "Runs a list of create, update and delete items, or applies a patch to every task a filter matches (e.g. filter \"status=3\u0026updated_before=2026-09-01\" with patch {\"status\": 4}), in one transaction. In atomic mode (the default) a failing item rolls back every other and the response has its status; in best_effort mode the items that work are kept and the response is 200. Every item gets a result with the status it would have had on its own, 424 for the items an atomic request didn't apply. The items that were applied are undone together by POST /v1/undo.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Create, update and delete tasks in one request",
+                "parameters": [
+                    {
+                        "description": "Items, or a filter and the patch for the tasks it matches",
+                        "name": "bulk",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTasksRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Results per item, in best_effort mode some may have failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTasksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON, mode, filter or patch, neither or both of items and filter, too many items; or, as a models.BulkTasksResponse, an atomic request whose failing item was invalid",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "An atomic request whose failing item named a missing task or project, nothing was applied",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTasksResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "An atomic request whose failing item would make a cycle or go under a trashed parent, nothing was applied",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTasksResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The filter matches too many tasks; or, as a models.BulkTasksResponse, an atomic request whose failing item had a blank title or an unusable parent, project, tag or recurrence",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Running the bulk request failed; or, as a models.BulkTasksResponse, an atomic request whose failing item failed in the store",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{id}": {
+            "get": {
+                "description": "Fetch a single task record from the database using its unique ID. Use expand=children to include the nested subtasks. The ETag header is the task's version, send it back as If-None-Match to get a 304 while the task is unchanged, or as If-Match on a change. The version covers the task's own fields, not the progress of its subtasks, so there is no ETag with expand=children.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get task details by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'children' to include nested subtasks",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the copy the client has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task details fetched successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetTasksResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "The task's version"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Task unchanged since the ETag in If-None-Match"
+                    },
+                    "400": {
+                        "description": "Invalid or missing task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Moves a task and all of its subtasks to the trash, from where they can be restored (POST /v1/trash/{id}/restore) until they are purged. Deleted tasks are left out of every other endpoint. Returns 404 if the task does not exist or is in the trash already.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Delete a task by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete when the task's ETag is still this one (or *)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or missing task ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "The task was changed since, the ETag header has its current version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update one or more fields of a task (title, description, status, priority, deadline, tags, recurrence). Archiving a task archives all of its subtasks. Completing a recurring task creates its next occurrence, returned as next_taskid. Send the ETag of the task as If-Match to get a 412 instead of overwriting a change made by someone else since.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Update task fields by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update when the task's ETag is still this one (or *)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "The task's new version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or missing ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "The task was changed since, the ETag header has its current version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid tag or recurrence rule, recurrence without a deadline",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{id}/history": {
+            "get": {
+                "description": "Every change made to the task through the API, newest first, with the old and new value of each changed field, when it happened and who made it (the X-Queueit-Actor header of the request) from where (http or ws). The history is kept after the task is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Activity"
+                ],
+                "summary": "The change history of a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ActivityPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or paging value",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found and without history",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching history failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{id}/occurrences": {
+            "get": {
+                "description": "Lists the deadlines the next occurrences of a recurring task will get, following its current deadline. Empty for tasks that don't repeat or whose series has ended.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Preview the next occurrences of a recurring task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of occurrences, 1-100 (default 5)",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OccurrencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID or count",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching occurrences failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{id}/parent": {
+            "put": {
+                "description": "Re-parents a task together with its whole subtree, which also follows the new parent into its project. A null parent_task_id moves it to the top level. Moving a task under itself or one of its own subtasks is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Move a task under another parent",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New parent",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MoveTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only move when the task's ETag is still this one (or *)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task moved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "The task's new version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or missing ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Move would create a cycle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "The task was changed since, the ETag header has its current version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Parent not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{id}/project": {
+            "put": {
+                "description": "Moves a task together with its whole subtree. A subtask is detached from its parent, since a subtree never spans projects.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Move a task to another project",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target project",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MoveTaskToProjectRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only move when the task's ETag is still this one (or *)",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task moved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "The task's new version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or missing ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "The task was changed since, the ETag header has its current version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Project not found or archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{id}/reminders": {
+            "get": {
+                "description": "fire_at is when the reminder goes off, it is missing for reminders relative to the deadline of a task without one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminders"
+                ],
+                "summary": "List the reminders of a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reminder"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching reminders failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Either before, a duration ahead of the deadline like \"1h\" or \"15m\", or at, an absolute time. Reminders missed while queueit was closed are delivered on the next start.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminders"
+                ],
+                "summary": "Add a reminder to a task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reminder to add",
+                        "name": "reminder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReminderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reminder created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricReminderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON or task ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Neither or both of before and at, invalid duration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Creating reminder failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash": {
+            "get": {
+                "description": "Fetch the tasks in the trash a page at a time, most recently deleted first, each with its deleted_at. Deleted tasks stay in the trash with their tags, subtasks and reminders until they are restored, purged, or older than the trash retention (TRASH_RETENTION, 30 days by default). Takes the filters and paging parameters of GET /v1/tasks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "List deleted tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated priority values to filter (1=high,2=medium,3=low)",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tag names to filter",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the tasks of this project",
+                        "name": "project_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-500 (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, only valid with the same sort",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort keys, - for descending, the GET /v1/tasks ones plus deleted_at (default -deleted_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return per task, e.g. task_id,title,deleted_at",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, paging or field value, names the offending parameter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching trash failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash/{id}": {
+            "delete": {
+                "description": "Removes a task in the trash and its subtasks permanently, along with their reminders. This can't be undone; the activity log keeps their history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Delete a task for good",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task purged",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not in the trash",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Purging task failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash/{id}/restore": {
+            "post": {
+                "description": "Takes a task out of the trash along with the subtasks deleted with it, announced as task.created. A subtask can only come back once its parent did, and the task's project must not be archived. The restore can be undone like any other change.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Restore a deleted task",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Task restored",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricTaskResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not in the trash",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Parent task still in the trash",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Project archived",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Restoring task failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/undo": {
+            "post": {
+                "description": "Reverses the last task operations (create, update, delete, move) made with the same X-Queueit-Session header, newest first, deleted tasks coming back from the trash with their old ids, fields and tags. Every field is put back only if nobody changed it since; the first operation that can't be undone cleanly stops the undo, the ones before it stay undone. Making a new change drops the operations that were undone from the redo history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Activity"
+                ],
+                "summary": "Undo the last operations of a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client session the operations were made in",
+                        "name": "X-Queueit-Session",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of operations, 1-50 (default 1)",
+                        "name": "steps",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The operations undone",
+                        "schema": {
+                            "$ref": "#/definitions/models.UndoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing session or invalid steps",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Nothing to undo, or the last operation conflicts with a later change",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Undo failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List the registered webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching webhooks failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Every task.created, task.updated and task.deleted event, or only the ones listed in events, is POSTed to url as JSON. The X-Queueit-Signature-256 header carries sha256=\u003chex HMAC-SHA256 of the body keyed with the secret\u003e. A blank secret is generated and returned once in the response. Failed deliveries are retried with exponential backoff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook to register",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid url or unknown event type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Creating webhook failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook details by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the webhook along with its delivery log, pending deliveries are dropped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "An empty events list subscribes to every event type. Deliveries to an inactive webhook are held back until it is activated again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Change the url, secret, events or active flag of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or missing ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid url, secret or event type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Newest first. Pending deliveries carry next_attempt_at, failed ones have given up after their last attempt.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "The delivery log of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of deliveries, 1-500 (default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID or limit",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Fetching deliveries failed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "description": "Queues a new delivery of the same event and payload, the original stays in the log untouched. The new one is attempted within a few seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Send a delivery again",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Redelivery queued",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenricDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook or delivery ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Sent from a page of another origin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook or delivery not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/ws": {
+            "get": {
+                "description": "Upgrades to a WebSocket speaking JSON frames (models.WSMessage). A request {\"id\": 1, \"method\": \"tasks.update\", \"params\": {...}} is answered by {\"id\": 1, \"result\": {...}} or {\"id\": 1, \"error\": {\"code\": 404, \"message\": \"...\"}}, the code being the HTTP status the same request gets over HTTP.\nMethods: tasks.list (params are the GET /v1/tasks query parameters plus project_id as a JSON object, lists may be JSON arrays), tasks.get {task_id}, tasks.create (models.CreateTaskRequest), tasks.update (task_id plus models.UpdateTaskRequest), tasks.delete {task_id}, undo and redo {steps} (like POST /v1/undo and /v1/redo, for the changes made over the connection or its session), subscribe (models.WSSubscribeRequest), unsubscribe and ping.\nAfter subscribe the connection receives {\"method\": \"event\", \"params\": models.Event} for every task change in the subscribed project and statuses, a task leaving them is still announced once. {\"method\": \"reset\"} means events were pruned before they could be sent, refetch the tasks. The server pings every 54s and drops connections that stop answering.",
+                "tags": [
+                    "Events"
+                ],
+                "summary": "WebSocket API for task operations and change broadcasts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Recorded as the actor of the changes made over the connection, like the X-Queueit-Actor header",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Undo session of the changes made over the connection, like the X-Queueit-Session header (default: one of its own)",
+                        "name": "session",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols",
+                        "schema": {
+                            "$ref": "#/definitions/models.WSMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Not a WebSocket handshake",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Origin header naming another host than the server",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.healthResponse": {
+            "type": "object",
+            "properties": {
+                "schema_version": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "uptime": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Activity": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "created, updated or deleted",
+                    "type": "string"
+                },
+                "activity_id": {
+                    "type": "integer"
+                },
+                "actor": {
+                    "description": "the X-Queueit-Actor of the request",
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "operation_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "http or ws",
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "via": {
+                    "description": "undo or redo when the entry reverts or replays its operation",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ActivityPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Activity"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkTaskItem": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "task": {
+                    "type": "object"
+                },
+                "task_id": {
+                    "description": "the task to update or delete",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkTaskResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "description": "of the item, or of the matched task for a filter",
+                    "type": "integer"
+                },
+                "next_task_id": {
+                    "description": "occurrence created by completing a recurring task",
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkTasksRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "description": "GET /v1/tasks query parameters plus project_id",
+                    "type": "string",
+                    "example": "status=3\u0026updated_before=2026-09-01"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkTaskItem"
+                    }
+                },
+                "mode": {
+                    "description": "atomic when blank",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "patch": {
+                    "$ref": "#/definitions/models.UpdateTaskRequest"
+                }
+            }
+        },
+        "models.BulkTasksResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkTaskResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateProjectRequest": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "default_priority": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateReminderRequest": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTagRequest": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTaskRequest": {
+            "type": "object",
+            "properties": {
+                "deadline_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "parent_task_id": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "project_id": {
+                    "description": "defaults to the parent's project, else the Inbox",
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "RRULE, e.g. FREQ=WEEKLY;BYDAY=FR, needs deadline_at",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "defaults to true",
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "X-Request-ID, to find the request in the log",
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Event": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "task": {
+                    "$ref": "#/definitions/models.GetTasksResponse"
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "object"
+                },
+                "old": {
+                    "type": "object"
+                }
+            }
+        },
+        "models.GenricDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "deliveryid": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GenricProjectResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "projectid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GenricReminderResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "reminderid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GenricTagResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "tagid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GenricTaskResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "next_taskid": {
+                    "description": "occurrence created by completing a recurring task",
+                    "type": "integer"
+                },
+                "taskid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GenricWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "only when it was generated",
+                    "type": "string"
+                },
+                "webhookid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetTasksResponse": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetTasksResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deadline_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "only set on tasks in the trash",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "parent_task_id": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "$ref": "#/definitions/models.TaskProgress"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "RRULE",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "task_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "bumped by every change to the task, its ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MoveTaskRequest": {
+            "type": "object",
             "properties": {
-                "deadline_at": {
+                "parent_task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MoveTaskToProjectRequest": {
+            "type": "object",
+            "properties": {
+                "project_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OccurrencesResponse": {
+            "type": "object",
+            "properties": {
+                "occurrences": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "recurrence": {
                     "type": "string"
                 },
-                "description": {
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Operation": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Activity"
+                    }
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "priority": {
+                "kind": {
+                    "description": "task.create, task.update, task.delete, task.move, task.restore or task.bulk",
+                    "type": "string"
+                },
+                "operation_id": {
                     "type": "integer"
                 },
-                "title": {
+                "state": {
+                    "description": "done, undone or discarded",
                     "type": "string"
                 }
             }
         },
-        "models.GenricTaskResponse": {
+        "models.Project": {
             "type": "object",
             "properties": {
-                "message": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "color": {
                     "type": "string"
                 },
-                "taskid": {
+                "created_at": {
+                    "type": "string"
+                },
+                "default_priority": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "task_count": {
                     "type": "integer"
                 }
             }
         },
-        "models.GetTasksResponse": {
+        "models.Reminder": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "before": {
+                    "description": "e.g. 1h30m0s",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "fire_at": {
+                    "description": "unset while a Before reminder's task has no deadline",
+                    "type": "string"
+                },
+                "reminder_id": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SearchPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SearchResult"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SearchResult": {
             "type": "object",
             "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetTasksResponse"
+                    }
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "deadline_at": {
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "only set on tasks in the trash",
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
+                "highlight": {
+                    "type": "string"
+                },
+                "parent_task_id": {
+                    "type": "integer"
+                },
                 "priority": {
                     "type": "integer"
                 },
+                "progress": {
+                    "$ref": "#/definitions/models.TaskProgress"
+                },
+                "project_id": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "description": "bm25, lower is a better match",
+                    "type": "number"
+                },
+                "recurrence": {
+                    "description": "RRULE",
+                    "type": "string"
+                },
+                "snippet": {
+                    "type": "string"
+                },
                 "status": {
                     "type": "integer"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "task_id": {
                     "type": "integer"
                 },
                 "title": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "bumped by every change to the task, its ETag",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tag_id": {
+                    "type": "integer"
+                },
+                "task_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TaskPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetTasksResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TaskProgress": {
+            "type": "object",
+            "properties": {
+                "done": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UndoResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "operations": {
+                    "description": "in the order they were undone or redone",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Operation"
+                    }
+                }
+            }
+        },
+        "models.UpdateProjectRequest": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "color": {
+                    "type": "string"
+                },
+                "default_priority": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateTagRequest": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
@@ -374,13 +3219,151 @@ const docTemplate = `{
                 "priority": {
                     "type": "integer"
                 },
+                "recurrence": {
+                    "description": "\"\" stops the task from repeating",
+                    "type": "string"
+                },
                 "status": {
                     "type": "integer"
                 },
+                "tags": {
+                    "description": "replaces the whole set, [] clears it",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
                     "type": "string"
                 }
             }
+        },
+        "models.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WSError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WSMessage": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/models.WSError"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object"
+                },
+                "result": {}
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "empty for every event type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "integer"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "redelivery_of": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending, delivered or failed",
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -3,10 +3,13 @@ module queueit
 go 1.25.1
 
 require (
+	github.com/godbus/dbus/v5 v5.2.2
 	github.com/gorilla/mux v1.8.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/joho/godotenv v1.5.1
 	github.com/swaggo/swag v1.16.6
 	github.com/webview/webview_go v0.0.0-20240831120633-6173450d4dd6
+	gopkg.in/yaml.v2 v2.4.0
 	modernc.org/sqlite v1.39.0
 )
 
@@ -32,7 +35,6 @@ require (
 	golang.org/x/sys v0.34.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
 	golang.org/x/tools v0.34.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	modernc.org/libc v1.66.3 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"strings"
+	"unicode/utf8"
+)
+
+// longer actor names are cut, longer session ids ignored
+const (
+	maxActorLen   = 64
+	maxSessionLen = 128
+)
+
+// the task fields the activity log keeps track of
+var auditedFields = []string{
+	"title", "description", "status", "priority", "project_id",
+	"parent_task_id", "tags", "deadline_at", "recurrence",
+}
+
+// who a change is recorded for in the activity log, and the session whose
+// undo history it goes to
+type origin struct {
+	actor   string
+	source  string
+	session string
+}
+
+// the origin of a change made over HTTP, the actor is whatever the client
+// put in X-Queueit-Actor and the session its X-Queueit-Session
+func httpOrigin(r *http.Request) origin {
+	return origin{
+		actor:   actorName(r.Header.Get(models.HEADER_ACTOR)),
+		source:  models.SOURCE_HTTP,
+		session: sessionName(r.Header.Get(models.HEADER_SESSION)),
+	}
+}
+
+func actorName(s string) string {
+	s = strings.TrimSpace(s)
+	for len(s) > maxActorLen {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
+
+func sessionName(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > maxSessionLen {
+		return ""
+	}
+	return s
+}
+
+// a change made by one request, its steps collect what they changed and
+// the whole is recorded as a single operation that can be undone
+type taskOp struct {
+	h       *Handler
+	ctx     context.Context // of the request, for its logger
+	caller  string
+	origin  origin
+	kind    string
+	entries []models.Activity
+}
+
+func (h *Handler) newOp(ctx context.Context, caller string, o origin, kind string) *taskOp {
+	return &taskOp{h: h, ctx: ctx, caller: caller, origin: o, kind: kind}
+}
+
+// adds what changed between before and after, as the change itself went
+// through a failure is only logged
+func (op *taskOp) diff(before, after []models.GetTasksResponse) {
+	entries, err := diffTasks(op.origin, before, after)
+	if err != nil {
+		logger.ErrorContext(op.ctx, err, op.caller, "~ diffing tasks for the activity log failed")
+		return
+	}
+	op.entries = append(op.entries, entries...)
+}
+
+// writes the operation to the activity log, a failure is only logged
+func (op *taskOp) record() {
+	if _, err := op.h.store.RecordOperation(op.origin.session, op.kind, op.entries); err != nil {
+		logger.ErrorContext(op.ctx, err, op.caller, "~ recording activity failed")
+	}
+}
+
+// one entry per task that differs between before and after, the ones only
+// in after were created and the ones only in before deleted
+func diffTasks(o origin, before, after []models.GetTasksResponse) ([]models.Activity, error) {
+	old := map[int]models.GetTasksResponse{}
+	for _, t := range before {
+		old[t.TaskID] = t
+	}
+	kept := map[int]bool{}
+
+	var entries []models.Activity
+	add := func(taskID int, action string, from, to *models.GetTasksResponse) error {
+		changes, err := diffFields(from, to)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+		entries = append(entries, models.Activity{
+			TaskID:  int64(taskID),
+			Action:  action,
+			Changes: changes,
+			Actor:   o.actor,
+			Source:  o.source,
+		})
+		return nil
+	}
+
+	for _, t := range after {
+		action := models.ACTIVITY_CREATED
+		var from *models.GetTasksResponse
+		if b, ok := old[t.TaskID]; ok {
+			action, from = models.ACTIVITY_UPDATED, &b
+			kept[t.TaskID] = true
+		}
+		if err := add(t.TaskID, action, from, &t); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range before {
+		if !kept[t.TaskID] {
+			if err := add(t.TaskID, models.ACTIVITY_DELETED, &t, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entries, nil
+}
+
+// the audited fields that differ, a nil task has none of them; fields
+// left out of the JSON (like a missing deadline) read as null
+func diffFields(from, to *models.GetTasksResponse) ([]models.FieldChange, error) {
+	oldFields, err := taskFields(from)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := taskFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.FieldChange{}
+	for _, f := range auditedFields {
+		o, n := oldFields[f], newFields[f]
+		if from != nil && o == nil {
+			o = json.RawMessage("null")
+		}
+		if to != nil && n == nil {
+			n = json.RawMessage("null")
+		}
+		if bytes.Equal(o, n) {
+			continue
+		}
+		// nothing to tell about a field a task was created or deleted without
+		if (from == nil && emptyJSON(n)) || (to == nil && emptyJSON(o)) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: f, Old: o, New: n})
+	}
+	return changes, nil
+}
+
+func emptyJSON(v json.RawMessage) bool {
+	switch string(v) {
+	case "null", `""`, "[]":
+		return true
+	}
+	return false
+}
+
+func taskFields(t *models.GetTasksResponse) (map[string]json.RawMessage, error) {
+	if t == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	return fields, json.Unmarshal(b, &fields)
+}
+
+// GetTaskHistory godoc
+// @Summary      The change history of a task
+// @Description  Every change made to the task through the API, newest first, with the old and new value of each changed field, when it happened and who made it (the X-Queueit-Actor header of the request) from where (http or ws). The history is kept after the task is deleted.
+// @Tags         Activity
+// @Produce      json
+// @Param        id      path      int     true   "Task ID"
+// @Param        limit   query     int     false  "Page size, 1-500 (default 100)"
+// @Param        cursor  query     string  false  "next_cursor of the previous page"
+// @Success      200  {object}  models.ActivityPage
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task ID or paging value"
+// @Failure      404  {string}  string  "Task not found and without history"
+// @Failure      500  {string}  string  "Fetching history failed"
+// @Router       /v1/tasks/{id}/history [get]
+func (h *Handler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetTaskHistory", "task")
+	if !ok {
+		return
+	}
+
+	entries, ok := h.listActivity(w, r, "GetTaskHistory", "fetching history failed", id)
+	if !ok {
+		return
+	}
+	if len(entries.Items) == 0 && r.URL.Query().Get("cursor") == "" {
+		if _, err := h.store.Get(id); err != nil {
+			writeStoreError(w, r, err, "GetTaskHistory", "fetching history failed")
+			return
+		}
+	}
+
+	writeActivity(w, r, "GetTaskHistory", "fetching history failed", entries)
+}
+
+// GetActivity godoc
+// @Summary      The activity log of every task
+// @Description  Every task created, updated or deleted through the API, newest first, one entry per task with the old and new value of each changed field. Changes cascading down a subtree (archiving, moving, deleting) have an entry for each task they touched.
+// @Tags         Activity
+// @Produce      json
+// @Param        limit   query     int     false  "Page size, 1-500 (default 100)"
+// @Param        cursor  query     string  false  "next_cursor of the previous page"
+// @Success      200  {object}  models.ActivityPage
+// @Failure      400  {object}  models.ErrorResponse  "Invalid paging value"
+// @Failure      500  {string}  string  "Fetching activity failed"
+// @Router       /v1/activity [get]
+func (h *Handler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	entries, ok := h.listActivity(w, r, "GetActivity", "fetching activity failed", 0)
+	if !ok {
+		return
+	}
+	writeActivity(w, r, "GetActivity", "fetching activity failed", entries)
+}
+
+// one page of the activity of a task, or of every task for taskID 0,
+// writes the error itself when it can't
+func (h *Handler) listActivity(w http.ResponseWriter, r *http.Request, caller, failure string, taskID int64) (models.ActivityPage, bool) {
+	page, perr := parsePage(r.URL.Query(), nil)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return models.ActivityPage{}, false
+	}
+
+	entries, next, err := h.store.ListActivity(taskID, page)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		writeParamError(w, r, &paramError{param: "cursor", value: page.Cursor, reason: err.Error()})
+		return models.ActivityPage{}, false
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, caller, "~ db query failed")
+		http.Error(w, failure, http.StatusInternalServerError)
+		return models.ActivityPage{}, false
+	}
+	return models.ActivityPage{Items: entries, NextCursor: next}, true
+}
+
+func writeActivity(w http.ResponseWriter, r *http.Request, caller, failure string, page models.ActivityPage) {
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(page); err != nil {
+		logger.ErrorContext(r.Context(), err, caller, "~ JSON encoding failed")
+		http.Error(w, failure, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
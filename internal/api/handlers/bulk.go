@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"strconv"
+)
+
+// the most items a bulk request, or the tasks its filter matches, can have
+const maxBulkItems = 500
+
+var (
+	errBulkAborted  = errors.New("bulk request aborted")
+	errBulkTooBroad = fmt.Errorf("filter matches more than %d tasks, narrow it down", maxBulkItems)
+)
+
+// an item of a bulk request, decoded and checked before anything runs
+type bulkItem struct {
+	op     string
+	taskID int64
+	create models.CreateTaskRequest
+	update models.UpdateTaskRequest
+
+	// the status an invalid item fails with and why, 0 for a valid one
+	status int
+	reason string
+}
+
+// BulkTasks godoc
+// @Summary      Create, update and delete tasks in one request
+// @Description  Runs a list of create, update and delete items, or applies a patch to every task a filter matches (e.g. filter "status=3&updated_before=2026-09-01" with patch {"status": 4}), in one transaction. In atomic mode (the default) a failing item rolls back every other and the response has its status; in best_effort mode the items that work are kept and the response is 200. Every item gets a result with the status it would have had on its own, 424 for the items an atomic request didn't apply. The items that were applied are undone together by POST /v1/undo.
+// @Tags         Tasks
+// @Accept       json
+// @Produce      json
+// @Param        bulk  body      models.BulkTasksRequest  true  "Items, or a filter and the patch for the tasks it matches"
+// @Success      200  {object}  models.BulkTasksResponse  "Results per item, in best_effort mode some may have failed"
+// @Failure      400  {string}  string  "Invalid JSON, mode, filter or patch, neither or both of items and filter, too many items; or, as a models.BulkTasksResponse, an atomic request whose failing item was invalid"
+// @Failure      404  {object}  models.BulkTasksResponse  "An atomic request whose failing item named a missing task or project, nothing was applied"
+// @Failure      409  {object}  models.BulkTasksResponse  "An atomic request whose failing item would make a cycle or go under a trashed parent, nothing was applied"
+// @Failure      422  {string}  string  "The filter matches too many tasks; or, as a models.BulkTasksResponse, an atomic request whose failing item had a blank title or an unusable parent, project, tag or recurrence"
+// @Failure      500  {string}  string  "Running the bulk request failed; or, as a models.BulkTasksResponse, an atomic request whose failing item failed in the store"
+// @Router       /v1/tasks/bulk [post]
+func (h *Handler) BulkTasks(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	var req models.BulkTasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), err, "BulkTasks ~ request json decoding failed")
+		http.Error(w, "invalid JSON payload in request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	items, filter, msg := parseBulk(&req)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	op := h.newOp(r.Context(), "BulkTasks", httpOrigin(r), models.OP_BULK)
+	resp := models.BulkTasksResponse{Mode: req.Mode, Results: []models.BulkTaskResult{}}
+	err := h.inTx(op.ctx, op.caller, func(tx *Handler) error {
+		if filter != nil {
+			var err error
+			if items, err = tx.matchBulkItems(*filter, *req.Patch); err != nil {
+				return err
+			}
+		}
+		for i, it := range items {
+			res := tx.applyBulkItem(op, it)
+			res.Index = i
+			resp.Results = append(resp.Results, res)
+			if res.Status != http.StatusOK && req.Mode == models.BULK_ATOMIC {
+				return errBulkAborted
+			}
+		}
+		return nil
+	})
+
+	status := http.StatusOK
+	switch {
+	case errors.Is(err, errBulkAborted):
+		status = resp.Results[len(resp.Results)-1].Status
+		notApplied(&resp, items)
+	case errors.Is(err, errBulkTooBroad):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		logger.ErrorContext(r.Context(), err, "BulkTasks ~ transaction failed")
+		http.Error(w, "bulk request failed", http.StatusInternalServerError)
+		return
+	default:
+		op.record()
+	}
+
+	for _, res := range resp.Results {
+		if res.Status == http.StatusOK {
+			resp.Succeeded++
+		}
+	}
+	resp.Failed = len(resp.Results) - resp.Succeeded
+
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "BulkTasks ~ JSON encoding failed")
+		http.Error(w, "bulk request failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(buffer.Bytes())
+}
+
+// checks the request as a whole and decodes its items, or its filter when
+// it has one; returns what is wrong with it or "" when it is fine
+func parseBulk(req *models.BulkTasksRequest) ([]bulkItem, *store.TaskFilter, string) {
+	switch req.Mode {
+	case "":
+		req.Mode = models.BULK_ATOMIC
+	case models.BULK_ATOMIC, models.BULK_BEST_EFFORT:
+	default:
+		return nil, nil, fmt.Sprintf("invalid mode %q, expected atomic or best_effort", req.Mode)
+	}
+
+	switch {
+	case len(req.Items) > 0 && (req.Filter != "" || req.Patch != nil):
+		return nil, nil, "items can't be combined with a filter and patch"
+	case len(req.Items) > maxBulkItems:
+		return nil, nil, fmt.Sprintf("at most %d items per request", maxBulkItems)
+	case len(req.Items) > 0:
+		items := make([]bulkItem, len(req.Items))
+		for i, it := range req.Items {
+			items[i] = parseBulkItem(it)
+		}
+		return items, nil, ""
+	case req.Filter == "" || req.Patch == nil:
+		return nil, nil, "expected items, or a filter along with a patch"
+	}
+
+	q, err := url.ParseQuery(req.Filter)
+	if err != nil {
+		return nil, nil, "invalid filter: " + err.Error()
+	}
+	filter, perr := parseTaskFilter(q)
+	if perr != nil {
+		return nil, nil, "invalid filter: " + perr.Error()
+	}
+	if raw := q.Get("project_id"); raw != "" {
+		if filter.ProjectID, _ = strconv.ParseInt(raw, 10, 64); filter.ProjectID <= 0 {
+			return nil, nil, "invalid filter: " + (&paramError{param: "project_id", value: raw, reason: "expected a positive project id"}).Error()
+		}
+	}
+	if msg := validateUpdate(*req.Patch); msg != "" {
+		return nil, nil, "invalid patch: " + msg
+	}
+	return nil, &filter, ""
+}
+
+// the checks CreateTask, UpdateTask and DeleteTask make on their requests
+func parseBulkItem(it models.BulkTaskItem) bulkItem {
+	b := bulkItem{op: it.Op, taskID: it.TaskID}
+	invalid := func(status int, reason string) bulkItem {
+		b.status, b.reason = status, reason
+		return b
+	}
+
+	var task any
+	switch it.Op {
+	case models.BULK_CREATE:
+		task = &b.create
+	case models.BULK_UPDATE:
+		task = &b.update
+	case models.BULK_DELETE:
+	default:
+		return invalid(http.StatusBadRequest, fmt.Sprintf("unknown op %q, expected create, update or delete", it.Op))
+	}
+
+	if it.Op != models.BULK_CREATE && it.TaskID <= 0 {
+		return invalid(http.StatusBadRequest, "expected a positive task_id")
+	}
+	if task != nil && len(it.Task) > 0 {
+		if err := json.Unmarshal(it.Task, task); err != nil {
+			return invalid(http.StatusBadRequest, "invalid task: "+err.Error())
+		}
+	}
+
+	switch it.Op {
+	case models.BULK_CREATE:
+		if b.create.Title == "" {
+			return invalid(http.StatusUnprocessableEntity, "blank title")
+		}
+	case models.BULK_UPDATE:
+		if msg := validateUpdate(b.update); msg != "" {
+			return invalid(http.StatusBadRequest, msg)
+		}
+	}
+	return b
+}
+
+// an update item for every task matching the filter
+func (h *Handler) matchBulkItems(f store.TaskFilter, patch models.UpdateTaskRequest) ([]bulkItem, error) {
+	tasks, next, err := h.store.List(f, store.Page{Limit: maxBulkItems})
+	if err != nil {
+		return nil, err
+	}
+	if next != "" {
+		return nil, errBulkTooBroad
+	}
+
+	items := make([]bulkItem, len(tasks))
+	for i, t := range tasks {
+		items[i] = bulkItem{op: models.BULK_UPDATE, taskID: int64(t.TaskID), update: patch}
+	}
+	return items, nil
+}
+
+// runs one item through the change the matching endpoint makes
+func (h *Handler) applyBulkItem(op *taskOp, it bulkItem) models.BulkTaskResult {
+	res := models.BulkTaskResult{Op: it.op, TaskID: it.taskID, Status: http.StatusOK}
+	if it.status != 0 {
+		res.Status, res.Error = it.status, it.reason
+		return res
+	}
+
+	var err error
+	switch it.op {
+	case models.BULK_CREATE:
+		res.TaskID, err = h.createTask(op, it.create)
+	case models.BULK_UPDATE:
+		res.NextTaskID, err = h.updateTask(op, it.taskID, it.update)
+	case models.BULK_DELETE:
+		err = h.deleteTask(op, it.taskID)
+	}
+	if err != nil {
+		res.Status, res.Error = storeErrorStatus(err), err.Error()
+		if res.Status == http.StatusInternalServerError {
+			logger.ErrorContext(op.ctx, err, op.caller, "~ store operation failed")
+			res.Error = "applying the item failed"
+		}
+	}
+	return res
+}
+
+// marks every item but the failing (last) one of an aborted atomic request
+// as not applied, adding the ones it never got to
+func notApplied(resp *models.BulkTasksResponse, items []bulkItem) {
+	failed := len(resp.Results) - 1
+	reason := fmt.Sprintf("not applied, item %d failed", failed)
+
+	for i := range resp.Results[:failed] {
+		res := &resp.Results[i]
+		if res.Op == models.BULK_CREATE {
+			res.TaskID = 0
+		}
+		res.NextTaskID, res.Status, res.Error = 0, http.StatusFailedDependency, reason
+	}
+	for i := failed + 1; i < len(items); i++ {
+		resp.Results = append(resp.Results, models.BulkTaskResult{
+			Index:  i,
+			Op:     items[i].op,
+			TaskID: items[i].taskID,
+			Status: http.StatusFailedDependency,
+			Error:  reason,
+		})
+	}
+}
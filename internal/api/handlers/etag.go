@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"queueit/internal/models"
+	"strconv"
+	"strings"
+)
+
+var errPreconditionFailed = errors.New("task was changed in the meantime, its ETag no longer matches If-Match")
+
+// the ETag of a task is its version, quoted
+func taskETag(t models.GetTasksResponse) string {
+	return strconv.Quote(strconv.FormatInt(t.Version, 10))
+}
+
+// whether an If-Match or If-None-Match header lists the ETag, * matches
+// any; weak and unquoted tags are taken as well since the version is all
+// there is to compare
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || strings.Trim(tag, `"`) == strings.Trim(etag, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// runs change once the task's ETag turned out to match If-Match, both in
+// one transaction so nobody changes the task in between; without If-Match
+// change just runs
+func (h *Handler) ifMatch(r *http.Request, caller string, id int64, change func(h *Handler) error) error {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return change(h)
+	}
+	return h.inTx(r.Context(), caller, func(tx *Handler) error {
+		t, err := tx.store.Get(id)
+		if err != nil {
+			return err
+		}
+		if !etagMatches(header, taskETag(t)) {
+			return errPreconditionFailed
+		}
+		return change(tx)
+	})
+}
+
+// sets the ETag header to the task's current version, left out when the
+// task can't be read
+func (h *Handler) setETag(w http.ResponseWriter, id int64) {
+	if t, err := h.store.Get(id); err == nil {
+		w.Header().Set("ETag", taskETag(t))
+	}
+}
+
+// writeStoreError for a change of the task, a 412 comes with the ETag the
+// task has now
+func (h *Handler) writeChangeError(w http.ResponseWriter, r *http.Request, id int64, err error, caller, failure string) {
+	if errors.Is(err, errPreconditionFailed) {
+		h.setETag(w, id)
+	}
+	writeStoreError(w, r, err, caller, failure)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"strconv"
+	"time"
+)
+
+// comment lines keep idle streams from being cut by proxies
+const eventKeepAlive = 15 * time.Second
+
+// GetEvents godoc
+// @Summary      Stream task changes
+// @Description  Server-Sent Events stream of task.created, task.updated and task.deleted events, each carrying the full task (as it was before deletion for task.deleted) with the event id as the SSE id. Reconnect with the Last-Event-ID header (or last_event_id) to resume, without either the stream starts at the next change. A reset event means the requested events have been pruned from the log, refetch the tasks.
+// @Tags         Events
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header  int  false  "Resume after this event"
+// @Param        last_event_id  query   int  false  "Resume after this event, for clients that can't set headers"
+// @Success      200  {object}  models.Event  "One data line per event"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid event id"
+// @Failure      500  {string}  string  "Streaming not supported or reading the event log failed"
+// @Router       /v1/events [get]
+func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		logger.ErrorContext(r.Context(), "GetEvents ~ response writer can't flush")
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	param, value := "Last-Event-ID", r.Header.Get("Last-Event-ID")
+	if value == "" {
+		param, value = "last_event_id", r.URL.Query().Get("last_event_id")
+	}
+
+	reader, err := h.events.Reader()
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, "GetEvents ~ reading the event log failed")
+		http.Error(w, "reading events failed", http.StatusInternalServerError)
+		return
+	}
+	defer reader.Close()
+
+	reset := false
+	if value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			writeParamError(w, r, &paramError{param: param, value: value, reason: "expected an event id"})
+			return
+		}
+		// an id from the future belongs to another database, or a log that was reset
+		if id <= reader.Pos() {
+			reader.ResumeAfter(id)
+		} else {
+			reset = true
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	if reset {
+		writeResetEvent(w, reader.Pos())
+	}
+	flusher.Flush()
+
+	ctx, cancel := h.streamContext(r)
+	defer cancel()
+	for {
+		batch, reset, err := reader.Next(ctx, eventKeepAlive)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorContext(r.Context(), err, "GetEvents ~ reading the event log failed")
+			}
+			return
+		}
+
+		switch {
+		case reset:
+			writeResetEvent(w, reader.Pos())
+		case len(batch) == 0:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		for _, e := range batch {
+			data, err := json.Marshal(e)
+			if err != nil {
+				logger.ErrorContext(r.Context(), err, "GetEvents ~ JSON encoding failed")
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.EventID, e.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// tells the client to refetch, the stream goes on after the given event
+func writeResetEvent(w http.ResponseWriter, after int64) {
+	fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", after)
+}
+
+// records the events for the given tasks, as the change itself went
+// through a failure is only logged; inside a transaction they are held
+// back until it commits
+func (h *Handler) publish(ctx context.Context, caller, typ string, tasks ...models.GetTasksResponse) {
+	for _, t := range tasks {
+		t.Children = nil
+		if h.held != nil {
+			*h.held = append(*h.held, heldEvent{typ: typ, task: t})
+			continue
+		}
+		if _, err := h.events.Publish(typ, t); err != nil {
+			logger.ErrorContext(ctx, err, caller, "~ publishing", typ, "event failed")
+		}
+	}
+}
+
+// publishes task.created for a new task and adds its creation to op
+func (h *Handler) created(op *taskOp, id int64) {
+	t, err := h.store.Get(id)
+	if err != nil {
+		logger.ErrorContext(op.ctx, err, op.caller, "~ fetching task", id, "for the", events.TaskCreated, "event failed")
+		return
+	}
+	h.publish(op.ctx, op.caller, events.TaskCreated, t)
+	op.diff(nil, []models.GetTasksResponse{t})
+}
+
+// the task followed by its descendants, for changes that cascade down
+func (h *Handler) withSubtree(id int64) ([]models.GetTasksResponse, error) {
+	t, err := h.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	children, err := h.store.Subtree(id)
+	if err != nil {
+		return nil, err
+	}
+	return flattenTasks(append([]models.GetTasksResponse{t}, children...)), nil
+}
+
+func flattenTasks(tasks []models.GetTasksResponse) []models.GetTasksResponse {
+	var out []models.GetTasksResponse
+	for _, t := range tasks {
+		out = append(out, t)
+		out = append(out, flattenTasks(t.Children)...)
+	}
+	return out
+}
+
+// the task and its descendants after a change went through, a failure is
+// only logged and leaves nothing to publish
+func (h *Handler) subtreeAfter(ctx context.Context, caller string, id int64) []models.GetTasksResponse {
+	tasks, err := h.withSubtree(id)
+	if err != nil {
+		logger.ErrorContext(ctx, err, caller, "~ fetching subtree of task", id, "after the change failed")
+	}
+	return tasks
+}
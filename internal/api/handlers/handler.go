@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"sync"
+)
+
+// Handler serves the queueit API on top of an injected store, so the same
+// handlers run against SQLite in the app and against memory in other tools,
+// task changes are published on the event bus
+type Handler struct {
+	store  store.Store
+	events *events.Bus
+
+	// set while the handler works on a transaction (see inTx), events are
+	// collected here instead of being published
+	held *[]heldEvent
+
+	// undo and redo of one session must not interleave
+	replayMu sync.Mutex
+
+	// done once CloseStreams is called, ends the event streams
+	streams      context.Context
+	closeStreams context.CancelFunc
+}
+
+func New(s store.Store, bus *events.Bus) *Handler {
+	streams, closeStreams := context.WithCancel(context.Background())
+	return &Handler{store: s, events: bus, streams: streams, closeStreams: closeStreams}
+}
+
+// ends the SSE streams and WebSocket connections, which never finish on
+// their own, so a server shutting down only waits for the other requests
+func (h *Handler) CloseStreams() {
+	h.closeStreams()
+}
+
+// the context of a long-lived request, done when the client goes away or
+// the streams are closed
+func (h *Handler) streamContext(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(r.Context())
+	stop := context.AfterFunc(h.streams, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+type heldEvent struct {
+	typ  string
+	task models.GetTasksResponse
+}
+
+// runs fn with a handler working on one transaction of the store, the
+// events it publishes go out once the transaction committed
+func (h *Handler) inTx(ctx context.Context, caller string, fn func(tx *Handler) error) error {
+	var held []heldEvent
+	err := h.store.Tx(func(s store.Store) error {
+		return fn(&Handler{store: s, events: h.events, held: &held})
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range held {
+		h.publish(ctx, caller, e.typ, e.task)
+	}
+	return nil
+}
+
+// maps a store error to its HTTP status, anything unknown is a 500
+func storeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrTaskNotFound),
+		errors.Is(err, store.ErrTagNotFound),
+		errors.Is(err, store.ErrProjectNotFound),
+		errors.Is(err, store.ErrReminderNotFound),
+		errors.Is(err, store.ErrWebhookNotFound),
+		errors.Is(err, store.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrCycle),
+		errors.Is(err, store.ErrParentTrashed),
+		errors.Is(err, store.ErrTagExists),
+		errors.Is(err, store.ErrProjectExists),
+		errors.Is(err, store.ErrInboxProtected):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrParentNotFound),
+		errors.Is(err, store.ErrParentProject),
+		errors.Is(err, store.ErrProjectUnavailable),
+		errors.Is(err, store.ErrInvalidTag),
+		errors.Is(err, store.ErrInvalidRecurrence),
+		errors.Is(err, store.ErrRecurrenceDeadline),
+		errors.Is(err, store.ErrInvalidReminder),
+		errors.Is(err, store.ErrInvalidWebhook):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, errPreconditionFailed):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+// writes the store error back to the client, unexpected errors are logged
+// and replaced by the generic failure message
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error, caller, failure string) {
+	status := storeErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger.ErrorContext(r.Context(), err, caller, "~ store operation failed")
+		http.Error(w, failure, status)
+		return
+	}
+	http.Error(w, err.Error(), status)
+}
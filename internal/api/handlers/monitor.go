@@ -15,31 +15,48 @@ var starttime = time.Now()
 
 // healthResponse represents the response for the health check endpoint
 type healthResponse struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
-	Uptime  string `json:"uptime"`
+	Status        string `json:"status"`
+	Version       string `json:"version"`
+	Uptime        string `json:"uptime"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+}
+
+// implemented by stores sitting on a versioned database schema
+type schemaVersioner interface {
+	SchemaVersion() (int, error)
 }
 
 // HandleHealth godoc
 // @Summary Health check
-// @Description Returns the server health status along with version and uptime
+// @Description Returns the server health status along with version, uptime and database schema version
 // @Tags Health
 // @Produce json
 // @Success 200 {object} healthResponse "Server is healthy"
 // @Failure 500 {string} string "Health check failed"
 // @Router /v1/health [get]
-func HandleHealth(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	helper.SetJSONHeader(w)
 
+	var schemaVersion int
+	if sv, ok := h.store.(schemaVersioner); ok {
+		var err error
+		if schemaVersion, err = sv.SchemaVersion(); err != nil {
+			logger.ErrorContext(r.Context(), err, "HandleHealth ~ schema version lookup failed")
+			http.Error(w, "health check failed", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	response := healthResponse{
-		Status:  "UP",
-		Version: strings.Split(r.URL.Path, "/")[1],
-		Uptime:  fmt.Sprintf("%.f (second)", time.Since(starttime).Seconds()),
+		Status:        "UP",
+		Version:       strings.Split(r.URL.Path, "/")[1],
+		Uptime:        fmt.Sprintf("%.f (second)", time.Since(starttime).Seconds()),
+		SchemaVersion: schemaVersion,
 	}
 
 	buffer := new(bytes.Buffer)
 	if err := json.NewEncoder(buffer).Encode(response); err != nil {
-		logger.Error("HandleHealth ~ JSON encoding failed: ")
+		logger.ErrorContext(r.Context(), "HandleHealth ~ JSON encoding failed: ")
 		http.Error(w, "health check failed", http.StatusInternalServerError)
 		return
 	}
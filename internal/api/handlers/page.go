@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 500
+)
+
+// parses limit, cursor and sort, shared by every paginated list
+func parsePage(q url.Values, sortable map[string]bool) (store.Page, *paramError) {
+	p := store.Page{Limit: defaultPageLimit, Cursor: q.Get("cursor")}
+
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return p, &paramError{param: "limit", value: raw, reason: fmt.Sprintf("expected an integer between 1 and %d", maxPageLimit)}
+		}
+		p.Limit = n
+	}
+
+	spec := q.Get("sort")
+	keys, err := store.ParseSort(spec, sortable)
+	if err != nil {
+		return p, &paramError{param: "sort", value: spec, reason: err.Error()}
+	}
+	p.Sort = keys
+	return p, nil
+}
+
+// parses the fields parameter against the json names of item, nil means
+// every field
+func parseFields(q url.Values, item any) ([]string, *paramError) {
+	raw := q.Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := jsonFields(reflect.TypeOf(item))
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if !slices.Contains(known, f) {
+			return nil, &paramError{param: "fields", value: f, reason: "expected any of " + strings.Join(known, ",")}
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" {
+			// embedded structs are flattened by encoding/json
+			names = append(names, jsonFields(field.Type)...)
+			continue
+		}
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// cuts every item down to fields, items are kept whole when fields is nil
+func selectFields[T any](items []T, fields []string) ([]any, error) {
+	out := make([]any, len(items))
+	for i, item := range items {
+		if fields == nil {
+			out[i] = item
+			continue
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(b, &all); err != nil {
+			return nil, err
+		}
+
+		picked := map[string]json.RawMessage{}
+		for _, f := range fields {
+			if v, ok := all[f]; ok {
+				picked[f] = v
+			}
+		}
+		out[i] = picked
+	}
+	return out, nil
+}
+
+// {"items": [...], "next_cursor": "..."}, the envelope of every paginated list
+type pageBody struct {
+	Items      []any  `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// whether the client pages through a list that predates pagination, which
+// otherwise still answers with every item as a bare JSON array
+func pageRequested(q url.Values) bool {
+	return q.Has("limit") || q.Has("cursor")
+}
+
+// writes the items cut down to fields as a pageBody
+func writePage[T any](w http.ResponseWriter, r *http.Request, caller, failure string, items []T, next string, fields []string) {
+	writeSelected(w, r, caller, failure, items, fields, func(selected []any) any {
+		return pageBody{Items: selected, NextCursor: next}
+	})
+}
+
+// writes the items cut down to fields as a JSON array
+func writeItems[T any](w http.ResponseWriter, r *http.Request, caller, failure string, items []T, fields []string) {
+	writeSelected(w, r, caller, failure, items, fields, func(selected []any) any {
+		return selected
+	})
+}
+
+// writes the body wrap makes of the items cut down to fields
+func writeSelected[T any](w http.ResponseWriter, r *http.Request, caller, failure string, items []T, fields []string, wrap func([]any) any) {
+	selected, err := selectFields(items, fields)
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, caller, "~ field selection failed")
+		http.Error(w, failure, http.StatusInternalServerError)
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(wrap(selected)); err != nil {
+		logger.ErrorContext(r.Context(), err, caller, "~ JSON encoding failed")
+		http.Error(w, failure, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// a query or path parameter that failed validation
+type paramError struct {
+	param  string
+	value  string
+	reason string
+}
+
+func (e *paramError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.param, e.value, e.reason)
+}
+
+// writes the 400 naming the offending parameter
+func writeParamError(w http.ResponseWriter, r *http.Request, e *paramError) {
+	helper.SetJSONHeader(w)
+	w.WriteHeader(http.StatusBadRequest)
+	resp := models.ErrorResponse{
+		Error: e.Error(),
+		Param: e.param,
+		Value: e.value,
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "writeParamError ~ json encoding failed")
+	}
+}
+
+// parses the {id} path variable, writes the 400 itself when it can't
+func idFromPath(w http.ResponseWriter, r *http.Request, caller, entity string) (int64, bool) {
+	return pathID(w, r, caller, "id", entity)
+}
+
+// parses a numeric path variable, writes the 400 itself when it can't
+func pathID(w http.ResponseWriter, r *http.Request, caller, name, entity string) (int64, bool) {
+	idstr, exists := mux.Vars(r)[name]
+	if !exists {
+		logger.ErrorContext(r.Context(), caller, "~", name, "missing")
+		writeParamError(w, r, &paramError{param: name, reason: entity + " id missing"})
+		return 0, false
+	}
+	id, err := strconv.ParseInt(idstr, 10, 64)
+	if err != nil || id <= 0 {
+		writeParamError(w, r, &paramError{param: name, value: idstr, reason: "expected a positive " + entity + " id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// builds the store filter out of the task list query parameters, every
+// value is checked before it gets anywhere near the store
+func parseTaskFilter(q url.Values) (store.TaskFilter, *paramError) {
+	var f store.TaskFilter
+
+	var perr *paramError
+	if f.Statuses, perr = parseEnumList(q, "status", models.ValidStatuses); perr != nil {
+		return f, perr
+	}
+	if f.Priorities, perr = parseEnumList(q, "priority", models.ValidPriorities); perr != nil {
+		return f, perr
+	}
+
+	if tag := q.Get("tag"); tag != "" {
+		var err error
+		if f.Tags, err = store.NormalizeTagNames(strings.Split(tag, ",")); err != nil {
+			return f, &paramError{param: "tag", value: tag, reason: err.Error()}
+		}
+	}
+
+	switch mode := q.Get("tag_mode"); mode {
+	case "", "any":
+	case "all":
+		f.AllTags = true
+	default:
+		return f, &paramError{param: "tag_mode", value: mode, reason: "expected any or all"}
+	}
+
+	for _, p := range []struct {
+		param string
+		dst   **time.Time
+	}{
+		{"deadline_after", &f.DeadlineAfter},
+		{"deadline_before", &f.DeadlineBefore},
+		{"created_after", &f.CreatedAfter},
+		{"updated_after", &f.UpdatedAfter},
+		{"updated_before", &f.UpdatedBefore},
+	} {
+		if *p.dst, perr = parseTimeParam(q, p.param); perr != nil {
+			return f, perr
+		}
+	}
+
+	if f.HasDeadline, perr = parseBoolParam(q, "has_deadline"); perr != nil {
+		return f, perr
+	}
+	if f.Overdue, perr = parseBoolParam(q, "overdue"); perr != nil {
+		return f, perr
+	}
+	return f, nil
+}
+
+// parses an RFC3339 timestamp, or a bare date meaning local midnight
+func parseTimeParam(q url.Values, param string) (*time.Time, *paramError) {
+	raw := q.Get(param)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		if t, err = time.ParseInLocation(time.DateOnly, raw, time.Local); err != nil {
+			return nil, &paramError{param: param, value: raw, reason: "expected an RFC3339 timestamp or a YYYY-MM-DD date"}
+		}
+	}
+	return &t, nil
+}
+
+func parseBoolParam(q url.Values, param string) (*bool, *paramError) {
+	raw := q.Get(param)
+	if raw == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, &paramError{param: param, value: raw, reason: "expected true or false"}
+	}
+	return &b, nil
+}
+
+// parses a comma-separated list of integers that must all be in valid
+func parseEnumList(q url.Values, param string, valid map[int]bool) ([]int, *paramError) {
+	raw := q.Get(param)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var out []int
+	for _, v := range strings.Split(raw, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, &paramError{param: param, value: v, reason: "expected a comma-separated list of integers"}
+		}
+		if !valid[n] {
+			return nil, &paramError{param: param, value: v, reason: fmt.Sprintf("expected one of %s", enumValues(valid))}
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func enumValues(valid map[int]bool) string {
+	var keys []int
+	for k := range valid {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = strconv.Itoa(k)
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"strings"
+)
+
+// GetAllProjects godoc
+// @Summary      Get all projects
+// @Description  Fetch every project along with its task count, archived projects only when asked for
+// @Tags         Projects
+// @Produce      json
+// @Param        include_archived query  bool  false  "Include archived projects"
+// @Success      200  {array}   models.Project
+// @Failure      500  {string}  string "Fetching projects failed"
+// @Router       /v1/projects [get]
+func (h *Handler) GetAllProjects(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	projects, err := h.store.ListProjects(r.URL.Query().Get("include_archived") == "true")
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, "GetAllProjects ~ db query failed")
+		http.Error(w, "fetching projects failed", http.StatusInternalServerError)
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(projects); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetAllProjects ~ JSON encoding failed")
+		http.Error(w, "fetching projects failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// GetProjectByID godoc
+// @Summary      Get project details by ID
+// @Tags         Projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  models.Project
+// @Failure      400  {string}  string  "Invalid or missing project ID"
+// @Failure      404  {string}  string  "Project not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/projects/{id} [get]
+func (h *Handler) GetProjectByID(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetProjectByID", "project")
+	if !ok {
+		return
+	}
+
+	p, err := h.store.GetProject(id)
+	if err != nil {
+		writeStoreError(w, r, err, "GetProjectByID", "fetching project failed")
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(p); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetProjectByID ~ JSON encoding failed")
+		http.Error(w, "fetching project failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// GetProjectTasks godoc
+// @Summary      Get the tasks of a project
+// @Description  Fetch the tasks of one project, accepts the same filters, paging, sort and fields as /v1/tasks and answers the same way: a JSON array of every task, or a page of them when limit or cursor is given
+// @Tags         Projects
+// @Produce      json
+// @Param        id       path      int     true   "Project ID"
+// @Param        status   query     string  false  "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)"
+// @Param        priority query     string  false  "Comma-separated priority values to filter (1=high,2=medium,3=low)"
+// @Param        tag      query     string  false  "Comma-separated tag names to filter"
+// @Param        tag_mode query     string  false  "any (default) or all"
+// @Param        deadline_after  query  string  false  "Deadline at or after this RFC3339 time or YYYY-MM-DD date"
+// @Param        deadline_before query  string  false  "Deadline before this RFC3339 time or YYYY-MM-DD date"
+// @Param        created_after   query  string  false  "Created at or after this RFC3339 time or YYYY-MM-DD date"
+// @Param        updated_after   query  string  false  "Last changed at or after this RFC3339 time or YYYY-MM-DD date"
+// @Param        updated_before  query  string  false  "Last changed before this RFC3339 time or YYYY-MM-DD date"
+// @Param        overdue         query  bool    false  "true for tasks past their deadline that are neither done nor archived, false for every other task"
+// @Param        has_deadline    query  bool    false  "true for tasks with a deadline, false for tasks without one"
+// @Param        limit    query     int     false  "Page size, 1-500 (default 100), answers with a models.TaskPage"
+// @Param        cursor   query     string  false  "next_cursor of the previous page, answers with a models.TaskPage"
+// @Param        sort     query     string  false  "Comma-separated sort keys, - for descending"
+// @Param        fields   query     string  false  "Comma-separated fields to return per task"
+// @Success      200  {array}   models.GetTasksResponse  "Every matching task, or a models.TaskPage when limit or cursor is given"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid project ID, filter, paging or field value"
+// @Failure      404  {string}  string  "Project not found"
+// @Failure      500  {string}  string  "Fetching tasks failed"
+// @Router       /v1/projects/{id}/tasks [get]
+func (h *Handler) GetProjectTasks(w http.ResponseWriter, r *http.Request) {
+	id, ok := idFromPath(w, r, "GetProjectTasks", "project")
+	if !ok {
+		return
+	}
+
+	if _, err := h.store.GetProject(id); err != nil {
+		writeStoreError(w, r, err, "GetProjectTasks", "fetching tasks failed")
+		return
+	}
+
+	h.listTasks(w, r, "GetProjectTasks", id)
+}
+
+// CreateProject godoc
+// @Summary      Create a new project
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Param        project  body      models.CreateProjectRequest  true  "Project to create"
+// @Success      200  {object}  models.GenricProjectResponse  "Project created successfully"
+// @Failure      400  {string}  string "Invalid JSON"
+// @Failure      409  {string}  string "Project already exists"
+// @Failure      422  {string}  string "Unprocessable entity (blank name)"
+// @Failure      500  {string}  string "Creating project failed"
+// @Router       /v1/projects [post]
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	var cpr models.CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&cpr); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateProject ~ JSON decoding failed")
+		http.Error(w, "creating project failed", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	cpr.Name = strings.TrimSpace(cpr.Name)
+	if cpr.Name == "" {
+		http.Error(w, "project name cannot be blank", http.StatusUnprocessableEntity)
+		return
+	}
+	if !(helper.IsValidPriority(cpr.DefaultPriority)) {
+		cpr.DefaultPriority = models.PRIORITY_MEDIUM
+	}
+
+	projectID, err := h.store.CreateProject(cpr)
+	if err != nil {
+		writeStoreError(w, r, err, "CreateProject", "creating project failed")
+		return
+	}
+	resp := models.GenricProjectResponse{
+		ProjectID: projectID,
+		Message:   "Project created",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateProject ~ json encoding failed")
+		http.Error(w, "creating project failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateProject godoc
+// @Summary      Update project fields by ID
+// @Description  Partially update name, color, default priority or archived flag. The Inbox cannot be archived.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                          true  "Project ID"
+// @Param        project  body      models.UpdateProjectRequest  true  "Fields to update"
+// @Success      200  {object}  models.GenricProjectResponse  "Project updated successfully"
+// @Failure      400  {string}  string  "Invalid input or missing ID"
+// @Failure      404  {string}  string  "Project not found"
+// @Failure      409  {string}  string  "Project name already in use, or archiving the Inbox"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/projects/{id} [patch]
+func (h *Handler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "UpdateProject", "project")
+	if !ok {
+		return
+	}
+
+	var p models.UpdateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		logger.ErrorContext(r.Context(), err, "UpdateProject ~ request json decoding failed")
+		http.Error(w, "invalid JSON payload in request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if p.Name != nil {
+		name := strings.TrimSpace(*p.Name)
+		if name == "" {
+			http.Error(w, "invalid/empty name", http.StatusBadRequest)
+			return
+		}
+		p.Name = &name
+	}
+
+	if p.DefaultPriority != nil && !(helper.IsValidPriority(*p.DefaultPriority)) {
+		http.Error(w, "invalid default priority", http.StatusBadRequest)
+		return
+	}
+
+	if p.Name == nil && p.Color == nil && p.DefaultPriority == nil && p.Archived == nil {
+		http.Error(w, "no fields to update", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateProject(id, p); err != nil {
+		writeStoreError(w, r, err, "UpdateProject", "project updation failed")
+		return
+	}
+
+	resp := models.GenricProjectResponse{
+		ProjectID: id,
+		Message:   "Project updated",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "UpdateProject ~ json encoding failed")
+		http.Error(w, "updating project failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteProject godoc
+// @Summary      Delete a project by ID
+// @Description  Deletes the project, its tasks are moved to the Inbox. The Inbox itself cannot be deleted.
+// @Tags         Projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  models.GenricProjectResponse  "Project deleted successfully"
+// @Failure      400  {string}  string  "Invalid or missing project ID"
+// @Failure      404  {string}  string  "Project not found"
+// @Failure      409  {string}  string  "The Inbox cannot be deleted"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/projects/{id} [delete]
+func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "DeleteProject", "project")
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteProject(id); err != nil {
+		writeStoreError(w, r, err, "DeleteProject", "deleting project failed")
+		return
+	}
+
+	resp := models.GenricProjectResponse{
+		ProjectID: id,
+		Message:   "Project deleted",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "DeleteProject ~ json encoding failed")
+		http.Error(w, "deleting project failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// MoveTaskToProject godoc
+// @Summary      Move a task to another project
+// @Description  Moves a task together with its whole subtree. A subtask is detached from its parent, since a subtree never spans projects.
+// @Tags         Tasks
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                              true  "Task ID"
+// @Param        move  body      models.MoveTaskToProjectRequest  true  "Target project"
+// @Param        If-Match  header  string  false  "Only move when the task's ETag is still this one (or *)"
+// @Success      200  {object}  models.GenricTaskResponse  "Task moved successfully"
+// @Header       200  {string}  ETag  "The task's new version"
+// @Failure      400  {string}  string  "Invalid input or missing ID"
+// @Failure      404  {string}  string  "Task not found"
+// @Failure      412  {string}  string  "The task was changed since, the ETag header has its current version"
+// @Failure      422  {string}  string  "Project not found or archived"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/tasks/{id}/project [put]
+func (h *Handler) MoveTaskToProject(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "MoveTaskToProject", "task")
+	if !ok {
+		return
+	}
+
+	var mpr models.MoveTaskToProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&mpr); err != nil {
+		logger.ErrorContext(r.Context(), err, "MoveTaskToProject ~ request json decoding failed")
+		http.Error(w, "invalid JSON payload in request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	op := h.newOp(r.Context(), "MoveTaskToProject", httpOrigin(r), models.OP_MOVE)
+	err := h.ifMatch(r, op.caller, id, func(h *Handler) error {
+		return h.moveTask(op, id, func() error {
+			return h.store.MoveToProject(id, mpr.ProjectID)
+		})
+	})
+	if err != nil {
+		h.writeChangeError(w, r, id, err, "MoveTaskToProject", "moving task failed")
+		return
+	}
+	op.record()
+	h.setETag(w, id)
+
+	resp := models.GenricTaskResponse{
+		TaskID:  id,
+		Message: "Task moved",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "MoveTaskToProject ~ json encoding failed")
+		http.Error(w, "moving task failed", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"strconv"
+)
+
+const (
+	defaultOccurrences = 5
+	maxOccurrences     = 100
+)
+
+// GetTaskOccurrences godoc
+// @Summary      Preview the next occurrences of a recurring task
+// @Description  Lists the deadlines the next occurrences of a recurring task will get, following its current deadline. Empty for tasks that don't repeat or whose series has ended.
+// @Tags         Tasks
+// @Produce      json
+// @Param        id     path      int  true   "Task ID"
+// @Param        count  query     int  false  "Number of occurrences, 1-100 (default 5)"
+// @Success      200  {object}  models.OccurrencesResponse
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task ID or count"
+// @Failure      404  {string}  string  "Task not found"
+// @Failure      500  {string}  string  "Fetching occurrences failed"
+// @Router       /v1/tasks/{id}/occurrences [get]
+func (h *Handler) GetTaskOccurrences(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetTaskOccurrences", "task")
+	if !ok {
+		return
+	}
+
+	count := defaultOccurrences
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxOccurrences {
+			writeParamError(w, r, &paramError{param: "count", value: raw, reason: fmt.Sprintf("expected an integer between 1 and %d", maxOccurrences)})
+			return
+		}
+		count = n
+	}
+
+	t, err := h.store.Get(id)
+	if err != nil {
+		writeStoreError(w, r, err, "GetTaskOccurrences", "fetching occurrences failed")
+		return
+	}
+	occurrences, err := h.store.Occurrences(id, count)
+	if err != nil {
+		writeStoreError(w, r, err, "GetTaskOccurrences", "fetching occurrences failed")
+		return
+	}
+
+	resp := models.OccurrencesResponse{
+		TaskID:      id,
+		Recurrence:  t.Recurrence,
+		Occurrences: occurrences,
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetTaskOccurrences ~ JSON encoding failed")
+		http.Error(w, "fetching occurrences failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
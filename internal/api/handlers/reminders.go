@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+)
+
+// GetTaskReminders godoc
+// @Summary      List the reminders of a task
+// @Description  fire_at is when the reminder goes off, it is missing for reminders relative to the deadline of a task without one
+// @Tags         Reminders
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {array}   models.Reminder
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task ID"
+// @Failure      404  {string}  string  "Task not found"
+// @Failure      500  {string}  string  "Fetching reminders failed"
+// @Router       /v1/tasks/{id}/reminders [get]
+func (h *Handler) GetTaskReminders(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetTaskReminders", "task")
+	if !ok {
+		return
+	}
+
+	reminders, err := h.store.ListReminders(id)
+	if err != nil {
+		writeStoreError(w, r, err, "GetTaskReminders", "fetching reminders failed")
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(reminders); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetTaskReminders ~ JSON encoding failed")
+		http.Error(w, "fetching reminders failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// CreateReminder godoc
+// @Summary      Add a reminder to a task
+// @Description  Either before, a duration ahead of the deadline like "1h" or "15m", or at, an absolute time. Reminders missed while queueit was closed are delivered on the next start.
+// @Tags         Reminders
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                           true  "Task ID"
+// @Param        reminder  body      models.CreateReminderRequest  true  "Reminder to add"
+// @Success      200  {object}  models.GenricReminderResponse  "Reminder created successfully"
+// @Failure      400  {string}  string  "Invalid JSON or task ID"
+// @Failure      404  {string}  string  "Task not found"
+// @Failure      422  {string}  string  "Neither or both of before and at, invalid duration"
+// @Failure      500  {string}  string  "Creating reminder failed"
+// @Router       /v1/tasks/{id}/reminders [post]
+func (h *Handler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "CreateReminder", "task")
+	if !ok {
+		return
+	}
+
+	var crr models.CreateReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&crr); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateReminder ~ JSON decoding failed")
+		http.Error(w, "creating reminder failed", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	reminderID, err := h.store.CreateReminder(id, crr)
+	if err != nil {
+		writeStoreError(w, r, err, "CreateReminder", "creating reminder failed")
+		return
+	}
+	resp := models.GenricReminderResponse{
+		ReminderID: reminderID,
+		Message:    "Reminder created",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateReminder ~ json encoding failed")
+		http.Error(w, "creating reminder failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteReminder godoc
+// @Summary      Delete a reminder by ID
+// @Tags         Reminders
+// @Produce      json
+// @Param        id   path      int  true  "Reminder ID"
+// @Success      200  {object}  models.GenricReminderResponse  "Reminder deleted successfully"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid reminder ID"
+// @Failure      404  {string}  string  "Reminder not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/reminders/{id} [delete]
+func (h *Handler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "DeleteReminder", "reminder")
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteReminder(id); err != nil {
+		writeStoreError(w, r, err, "DeleteReminder", "deleting reminder failed")
+		return
+	}
+
+	resp := models.GenricReminderResponse{
+		ReminderID: id,
+		Message:    "Reminder deleted",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "DeleteReminder ~ json encoding failed")
+		http.Error(w, "deleting reminder failed", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+)
+
+// SearchTasks godoc
+// @Summary      Search tasks
+// @Description  Full-text search over task titles and descriptions, best match first. The query is made of words and "quoted phrases", either may end in * to match by prefix, and every term has to match. Accepts the filters, paging and fields of /v1/tasks.
+// @Tags         Tasks
+// @Produce      json
+// @Param        q        query     string  true   "Search query, e.g. milk \"grocery list\" proj*"
+// @Param        status   query     string  false  "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)"
+// @Param        priority query     string  false  "Comma-separated priority values to filter (1=high,2=medium,3=low)"
+// @Param        tag      query     string  false  "Comma-separated tag names to filter"
+// @Param        tag_mode query     string  false  "any (default) or all"
+// @Param        limit    query     int     false  "Page size, 1-500 (default 100)"
+// @Param        cursor   query     string  false  "next_cursor of the previous page, only valid with the same sort"
+// @Param        sort     query     string  false  "Comma-separated sort keys, - for descending, rank (default) or any /v1/tasks sort key"
+// @Param        fields   query     string  false  "Comma-separated fields to return per result, e.g. task_id,highlight"
+// @Success      200  {object}  models.SearchPage
+// @Failure      400  {object}  models.ErrorResponse "Missing or empty query, invalid filter, paging or field value"
+// @Failure      500  {string}  string "Searching tasks failed"
+// @Router       /v1/search [get]
+func (h *Handler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	q := r.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		writeParamError(w, r, &paramError{param: "q", reason: "search query missing"})
+		return
+	}
+
+	filter, perr := parseTaskFilter(q)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+	page, perr := parsePage(q, store.SearchSortFields)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+	fields, perr := parseFields(q, models.SearchResult{})
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+
+	results, next, err := h.store.Search(query, filter, page)
+	switch {
+	case errors.Is(err, store.ErrInvalidSearch):
+		writeParamError(w, r, &paramError{param: "q", value: query, reason: err.Error()})
+		return
+	case errors.Is(err, store.ErrInvalidCursor):
+		writeParamError(w, r, &paramError{param: "cursor", value: page.Cursor, reason: err.Error()})
+		return
+	case err != nil:
+		logger.ErrorContext(r.Context(), err, "SearchTasks ~ db query failed")
+		http.Error(w, "searching tasks failed", http.StatusInternalServerError)
+		return
+	}
+
+	writePage(w, r, "SearchTasks", "searching tasks failed", results, next, fields)
+}
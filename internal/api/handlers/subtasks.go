@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+)
+
+// MoveTask godoc
+// @Summary      Move a task under another parent
+// @Description  Re-parents a task together with its whole subtree, which also follows the new parent into its project. A null parent_task_id moves it to the top level. Moving a task under itself or one of its own subtasks is rejected.
+// @Tags         Tasks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                     true  "Task ID"
+// @Param        move body      models.MoveTaskRequest  true  "New parent"
+// @Param        If-Match  header  string  false  "Only move when the task's ETag is still this one (or *)"
+// @Success      200  {object}  models.GenricTaskResponse  "Task moved successfully"
+// @Header       200  {string}  ETag  "The task's new version"
+// @Failure      400  {string}  string  "Invalid input or missing ID"
+// @Failure      404  {string}  string  "Task not found"
+// @Failure      409  {string}  string  "Move would create a cycle"
+// @Failure      412  {string}  string  "The task was changed since, the ETag header has its current version"
+// @Failure      422  {string}  string  "Parent not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/tasks/{id}/parent [put]
+func (h *Handler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "MoveTask", "task")
+	if !ok {
+		return
+	}
+
+	var mtr models.MoveTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&mtr); err != nil {
+		logger.ErrorContext(r.Context(), err, "MoveTask ~ request json decoding failed")
+		http.Error(w, "invalid JSON payload in request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	op := h.newOp(r.Context(), "MoveTask", httpOrigin(r), models.OP_MOVE)
+	err := h.ifMatch(r, op.caller, id, func(h *Handler) error {
+		return h.moveTask(op, id, func() error {
+			return h.store.Move(id, mtr.ParentTaskID)
+		})
+	})
+	if err != nil {
+		h.writeChangeError(w, r, id, err, "MoveTask", "moving task failed")
+		return
+	}
+	op.record()
+	h.setETag(w, id)
+
+	resp := models.GenricTaskResponse{
+		TaskID:  id,
+		Message: "Task moved",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "MoveTask ~ json encoding failed")
+		http.Error(w, "moving task failed", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+)
+
+// GetAllTags godoc
+// @Summary      Get all tags
+// @Description  Fetch every tag along with the number of tasks carrying it
+// @Tags         Tags
+// @Produce      json
+// @Success      200  {array}   models.Tag
+// @Failure      500  {string}  string "Fetching tags failed"
+// @Router       /v1/tags [get]
+func (h *Handler) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	tags, err := h.store.ListTags()
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, "GetAllTags ~ db query failed")
+		http.Error(w, "fetching tags failed", http.StatusInternalServerError)
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(tags); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetAllTags ~ JSON encoding failed")
+		http.Error(w, "fetching tags failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// GetTagByID godoc
+// @Summary      Get tag details by ID
+// @Tags         Tags
+// @Produce      json
+// @Param        id   path      int  true  "Tag ID"
+// @Success      200  {object}  models.Tag
+// @Failure      400  {string}  string  "Invalid or missing tag ID"
+// @Failure      404  {string}  string  "Tag not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/tags/{id} [get]
+func (h *Handler) GetTagByID(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetTagByID", "tag")
+	if !ok {
+		return
+	}
+
+	t, err := h.store.GetTag(id)
+	if err != nil {
+		writeStoreError(w, r, err, "GetTagByID", "fetching tag failed")
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(t); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetTagByID ~ JSON encoding failed")
+		http.Error(w, "fetching tag failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// CreateTag godoc
+// @Summary      Create a new tag
+// @Description  Tag names are case-insensitive and may not contain commas
+// @Tags         Tags
+// @Accept       json
+// @Produce      json
+// @Param        tag  body      models.CreateTagRequest  true  "Tag to create"
+// @Success      200  {object}  models.GenricTagResponse  "Tag created successfully"
+// @Failure      400  {string}  string "Invalid JSON"
+// @Failure      409  {string}  string "Tag already exists"
+// @Failure      422  {string}  string "Unprocessable entity (invalid name)"
+// @Failure      500  {string}  string "Creating tag failed"
+// @Router       /v1/tags [post]
+func (h *Handler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	var ctr models.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&ctr); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateTag ~ JSON decoding failed")
+		http.Error(w, "creating tag failed", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	tagID, err := h.store.CreateTag(ctr)
+	if err != nil {
+		writeStoreError(w, r, err, "CreateTag", "creating tag failed")
+		return
+	}
+	resp := models.GenricTagResponse{
+		TagID:   tagID,
+		Message: "Tag created",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateTag ~ json encoding failed")
+		http.Error(w, "creating tag failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateTag godoc
+// @Summary      Rename or recolor a tag
+// @Tags         Tags
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                      true  "Tag ID"
+// @Param        tag  body      models.UpdateTagRequest  true  "Fields to update"
+// @Success      200  {object}  models.GenricTagResponse  "Tag updated successfully"
+// @Failure      400  {string}  string  "Invalid input or missing ID"
+// @Failure      404  {string}  string  "Tag not found"
+// @Failure      409  {string}  string  "Tag name already in use"
+// @Failure      422  {string}  string  "Invalid name"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/tags/{id} [patch]
+func (h *Handler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "UpdateTag", "tag")
+	if !ok {
+		return
+	}
+
+	var t models.UpdateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		logger.ErrorContext(r.Context(), err, "UpdateTag ~ request json decoding failed")
+		http.Error(w, "invalid JSON payload in request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if t.Name == nil && t.Color == nil {
+		http.Error(w, "no fields to update", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateTag(id, t); err != nil {
+		writeStoreError(w, r, err, "UpdateTag", "tag updation failed")
+		return
+	}
+
+	resp := models.GenricTagResponse{
+		TagID:   id,
+		Message: "Tag updated",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "UpdateTag ~ json encoding failed")
+		http.Error(w, "updating tag failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteTag godoc
+// @Summary      Delete a tag by ID
+// @Description  Deletes the tag and detaches it from every task, the tasks themselves are kept
+// @Tags         Tags
+// @Produce      json
+// @Param        id   path      int  true  "Tag ID"
+// @Success      200  {object}  models.GenricTagResponse  "Tag deleted successfully"
+// @Failure      400  {string}  string  "Invalid or missing tag ID"
+// @Failure      404  {string}  string  "Tag not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/tags/{id} [delete]
+func (h *Handler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "DeleteTag", "tag")
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteTag(id); err != nil {
+		writeStoreError(w, r, err, "DeleteTag", "deleting tag failed")
+		return
+	}
+
+	resp := models.GenricTagResponse{
+		TagID:   id,
+		Message: "Tag deleted",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "DeleteTag ~ json encoding failed")
+		http.Error(w, "deleting tag failed", http.StatusInternalServerError)
+		return
+	}
+}
@@ -2,166 +2,149 @@ package handlers
 
 import (
 	"bytes"
-	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"queueit/internal/db"
+	"queueit/internal/events"
 	"queueit/internal/helper"
 	"queueit/internal/models"
+	"queueit/internal/store"
 	"queueit/pkg/logger"
-	"strconv"
-	"strings"
-	"time"
-
-	"github.com/gorilla/mux"
 )
 
 // GetAllTasks godoc
 // @Summary      Get all tasks
-// @Description  Fetch all tasks, optionally filtering by status and/or priority
+// @Description  Fetch tasks, optionally filtering by status, priority, tags and dates. Without limit and cursor every matching task comes back as a JSON array, as it always did. Giving either of them pages through the tasks instead: the response is then a models.TaskPage object, {"items": [...], "next_cursor": "..."}; follow next_cursor for the next page, it is absent on the last one.
 // @Tags         Tasks
 // @Accept       json
 // @Produce      json
 // @Param        status   query     string  false  "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)"
-// @Param        priority query     string  false  "Comma-separated priority values to filter (1=high,2=medium,3=low,0=default(medium))"
-// @Success      200  {array}   models.GetTasksResponse
-// @Failure      400  {string}  string "Bad request"
+// @Param        priority query     string  false  "Comma-separated priority values to filter (1=high,2=medium,3=low)"
+// @Param        tag      query     string  false  "Comma-separated tag names to filter"
+// @Param        tag_mode query     string  false  "any (default) matches tasks with at least one of the tags, all matches tasks carrying every tag"
+// @Param        deadline_after  query  string  false  "Deadline at or after this RFC3339 time or YYYY-MM-DD date"
+// @Param        deadline_before query  string  false  "Deadline before this RFC3339 time or YYYY-MM-DD date"
+// @Param        created_after   query  string  false  "Created at or after this RFC3339 time or YYYY-MM-DD date"
+// @Param        updated_after   query  string  false  "Last changed at or after this RFC3339 time or YYYY-MM-DD date"
+// @Param        updated_before  query  string  false  "Last changed before this RFC3339 time or YYYY-MM-DD date"
+// @Param        overdue         query  bool    false  "true for tasks past their deadline that are neither done nor archived, false for every other task"
+// @Param        has_deadline    query  bool    false  "true for tasks with a deadline, false for tasks without one"
+// @Param        limit    query     int     false  "Page size, 1-500 (default 100), answers with a models.TaskPage"
+// @Param        cursor   query     string  false  "next_cursor of the previous page, only valid with the same sort, answers with a models.TaskPage"
+// @Param        sort     query     string  false  "Comma-separated sort keys, - for descending (task_id, title, status, priority, project_id, created_at, updated_at, deadline_at), e.g. -priority,deadline_at"
+// @Param        fields   query     string  false  "Comma-separated fields to return per task, e.g. task_id,title"
+// @Success      200  {array}   models.GetTasksResponse  "Every matching task, or a models.TaskPage when limit or cursor is given"
+// @Failure      400  {object}  models.ErrorResponse "Invalid filter, paging or field value, names the offending parameter"
 // @Failure      500  {string}  string "Fetching tasks failed"
 // @Router       /v1/tasks [get]
-func GetAllTasks(w http.ResponseWriter, r *http.Request) {
-	helper.SetJSONHeader(w)
+func (h *Handler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
+	h.listTasks(w, r, "GetAllTasks", 0)
+}
 
-	status := r.URL.Query().Get("status")
-	priority := r.URL.Query().Get("priority")
+// writes the task list for GetAllTasks and GetProjectTasks, a zero
+// projectID lists the tasks of every project
+func (h *Handler) listTasks(w http.ResponseWriter, r *http.Request, caller string, projectID int64) {
+	helper.SetJSONHeader(w)
 
-	query := `
-		SELECT
-		task_id, title, description, priority, status, created_at, deadline_at 
-		FROM tasksmaster WHERE 1=1
-	`
-	if status != "" {
-		query = fmt.Sprintf("%s AND status IN (%s)", query, strings.Join(strings.Split(status, ","), ","))
+	q := r.URL.Query()
+	filter, perr := parseTaskFilter(q)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
 	}
+	filter.ProjectID = projectID
 
-	if priority != "" {
-		query = fmt.Sprintf("%s AND priority IN (%s)", query, strings.Join(strings.Split(priority, ","), ","))
+	page, perr := parsePage(q, store.TaskSortFields)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
 	}
-
-	rows, err := db.GetDBInfo().Q(query)
-	if err != nil {
-		logger.Error(err, "GetAllTasks ~ db query failed")
-		http.Error(w, "fetching tasks failed", http.StatusInternalServerError)
+	fields, perr := parseFields(q, models.GetTasksResponse{})
+	if perr != nil {
+		writeParamError(w, r, perr)
 		return
 	}
 
-	var deadline sql.NullString
-	defer rows.Close()
-	var tasks []models.GetTasksResponse
-	for rows.Next() {
-		var t models.GetTasksResponse
-		if err := rows.Scan(
-			&t.TaskID,
-			&t.Title,
-			&t.Description,
-			&t.Priority,
-			&t.Status,
-			&t.CreatedAt,
-			&deadline,
-		); err != nil {
-			logger.Error(err, "GetAllTasks ~ row scan failed")
+	// clients written before pagination read every task from a bare array
+	if !pageRequested(q) {
+		tasks, err := h.listAll(filter, page)
+		if err != nil {
+			logger.ErrorContext(r.Context(), err, caller, "~ db query failed")
 			http.Error(w, "fetching tasks failed", http.StatusInternalServerError)
 			return
 		}
-
-		// validate deadline (else NIL)
-		if deadline.Valid {
-			ct, err := time.Parse(time.RFC3339, deadline.String)
-			if err != nil {
-				logger.Error(err, "GetAllTasks ~ deadline validation failed")
-				http.Error(w, "fetching tasks failed", http.StatusInternalServerError)
-				return
-			}
-			t.DeadlineAt = &ct
-		} else {
-			t.DeadlineAt = nil
-		}
-
-		tasks = append(tasks, t)
+		writeItems(w, r, caller, "fetching tasks failed", tasks, fields)
+		return
 	}
 
-	buffer := new(bytes.Buffer)
-	if err = json.NewEncoder(buffer).Encode(tasks); err != nil {
-		logger.Error(err, "GetAllTasks ~ JSON encoding failed")
+	tasks, next, err := h.store.List(filter, page)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		writeParamError(w, r, &paramError{param: "cursor", value: page.Cursor, reason: err.Error()})
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, caller, "~ db query failed")
 		http.Error(w, "fetching tasks failed", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write(buffer.Bytes())
+	writePage(w, r, caller, "fetching tasks failed", tasks, next, fields)
+}
+
+// every task matching the filter in the order of p.Sort, read from the
+// store a page at a time
+func (h *Handler) listAll(f store.TaskFilter, p store.Page) ([]models.GetTasksResponse, error) {
+	p.Limit = maxPageLimit
+	all := []models.GetTasksResponse{}
+	for {
+		tasks, next, err := h.store.List(f, p)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, tasks...)
+		if next == "" {
+			return all, nil
+		}
+		p.Cursor = next
+	}
 }
 
 // CreateTask godoc
 // @Summary      Create a new task
-// @Description  Create a new task with title, description, priority, and optional deadline
+// @Description  Create a new task with title, description, priority, and optional deadline, project, parent task, tags and recurrence rule. Unknown tag names are created. Without a priority the project's default priority is used. A recurring task needs a deadline, its first occurrence.
 // @Tags         Tasks
 // @Accept       json
 // @Produce      json
 // @Param        task  body      models.CreateTaskRequest  true  "Task to create"
 // @Success      200  {object}  models.GenricTaskResponse  "Task created successfully"
 // @Failure      400  {string}  string "Invalid JSON"
-// @Failure      422  {string}  string "Unprocessable entity (blank title)"
+// @Failure      422  {string}  string "Unprocessable entity (blank title, unknown parent or project, invalid tag or recurrence rule)"
 // @Failure      500  {string}  string "Creating task failed"
 // @Router       /v1/tasks [post]
-func CreateTask(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	helper.SetJSONHeader(w)
 
 	var ctr models.CreateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&ctr); err != nil {
-		logger.Error(err, "CreateTask ~ JSON decoding failed")
+		logger.ErrorContext(r.Context(), err, "CreateTask ~ JSON decoding failed")
 		http.Error(w, "creating task failed", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
 	if ctr.Title == "" {
-		logger.Error("CreateTask ~ blank title")
+		logger.ErrorContext(r.Context(), "CreateTask ~ blank title")
 		http.Error(w, "creating task failed", http.StatusUnprocessableEntity)
 		return
 	}
-	if !(helper.IsValidPriority(ctr.Priority)) {
-		ctr.Priority = 2 // medium
-	}
-
-	query := `
-		INSERT into tasksmaster
-		(
-			title,
-			description,
-			priority,
-			status,
-			deadline_at
-		)
-		VALUES
-		(
-			?,?,?,?,?
-		);
-	`
-
-	exec_result, err := db.GetDBInfo().E(
-		query,
-		ctr.Title,
-		ctr.Description,
-		ctr.Priority,
-		models.STATUS_PENDING, // default status
-		ctr.DeadlineAt,
-	)
+
+	op := h.newOp(r.Context(), "CreateTask", httpOrigin(r), models.OP_CREATE)
+	taskID, err := h.createTask(op, ctr)
 	if err != nil {
-		logger.Error(err, "CreateTask ~ execution failed")
-		http.Error(w, "creating task failed", http.StatusInternalServerError)
+		writeStoreError(w, r, err, "CreateTask", "creating task failed")
 		return
 	}
-	taskID, _ := exec_result.LastInsertId()
+	op.record()
 	resp := models.GenricTaskResponse{
 		TaskID:  taskID,
 		Message: "Task created",
@@ -169,7 +152,7 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		logger.Error(err, "CreateTask ~ json encoding failed")
+		logger.ErrorContext(r.Context(), err, "CreateTask ~ json encoding failed")
 		http.Error(w, "creating task failed", http.StatusInternalServerError)
 		return
 	}
@@ -177,78 +160,51 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 
 // GetTaskByID godoc
 // @Summary      Get task details by ID
-// @Description  Fetch a single task record from the database using its unique ID.
+// @Description  Fetch a single task record from the database using its unique ID. Use expand=children to include the nested subtasks. The ETag header is the task's version, send it back as If-None-Match to get a 304 while the task is unchanged, or as If-Match on a change. The version covers the task's own fields, not the progress of its subtasks, so there is no ETag with expand=children.
 // @Tags         Tasks
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int     true  "Task ID"
+// @Param        id     path      int     true   "Task ID"
+// @Param        expand query     string  false  "Set to 'children' to include nested subtasks"
+// @Param        If-None-Match  header  string  false  "ETag of the copy the client has"
 // @Success      200  {object}  models.GetTasksResponse  "Task details fetched successfully"
-// @Failure      400  {string}  string  "Invalid or missing task ID"
+// @Header       200  {string}  ETag  "The task's version"
+// @Success      304  "Task unchanged since the ETag in If-None-Match"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid or missing task ID"
 // @Failure      404  {string}  string  "Task not found"
 // @Failure      500  {string}  string  "Internal server error"
 // @Router       /v1/tasks/{id} [get]
-func GetTaskByID(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetTaskByID(w http.ResponseWriter, r *http.Request) {
 	helper.SetJSONHeader(w)
 
-	idstr, exists := mux.Vars(r)["id"]
-	if !exists {
-		logger.Error("GetTaskByID ~ id missing")
-		http.Error(w, "id missing", http.StatusBadRequest)
+	id, ok := idFromPath(w, r, "GetTaskByID", "task")
+	if !ok {
 		return
 	}
 
-	var deadline sql.NullString
-	var t models.GetTasksResponse
-	query := fmt.Sprintf(`
-		SELECT
-		task_id, title, description, priority, status, created_at, deadline_at 
-		FROM tasksmaster WHERE task_id = %s
-	`, idstr)
-
-	rows, err := db.GetDBInfo().Q(query)
+	t, err := h.store.Get(id)
 	if err != nil {
-		logger.Error(err, "GetTaskByID ~ query execution failed")
-		http.Error(w, "invalid task ID or internal error", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	if !rows.Next() {
-		logger.Error("GetTaskByID ~ rows.Next-false, no rows present")
-		http.Error(w, "task id not found", http.StatusBadRequest)
-		return
-	}
-
-	if err := rows.Scan(
-		&t.TaskID,
-		&t.Title,
-		&t.Description,
-		&t.Priority,
-		&t.Status,
-		&t.CreatedAt,
-		&deadline,
-	); err != nil {
-		logger.Error(err, "GetTaskByID ~ row scan failed")
-		http.Error(w, "fetching task failed", http.StatusInternalServerError)
+		writeStoreError(w, r, err, "GetTaskByID", "fetching task failed")
 		return
 	}
 
-	// validate deadline (else NIL)
-	if deadline.Valid {
-		ct, err := time.Parse(time.RFC3339, deadline.String)
-		if err != nil {
-			logger.Error(err, "GetTaskByID ~ deadline validation failed")
-			http.Error(w, "fetching task failed", http.StatusInternalServerError)
+	if r.URL.Query().Get("expand") == "children" {
+		if t.Children, err = h.store.Subtree(id); err != nil {
+			writeStoreError(w, r, err, "GetTaskByID", "fetching task failed")
 			return
 		}
-		t.DeadlineAt = &ct
 	} else {
-		t.DeadlineAt = nil
+		etag := taskETag(t)
+		w.Header().Set("ETag", etag)
+		if header := r.Header.Get("If-None-Match"); header != "" && etagMatches(header, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
 	}
 
 	buffer := new(bytes.Buffer)
 	if err = json.NewEncoder(buffer).Encode(t); err != nil {
-		logger.Error(err, "GetTaskByID ~ JSON encoding failed")
+		logger.ErrorContext(r.Context(), err, "GetTaskByID ~ JSON encoding failed")
 		http.Error(w, "fetching tasks failed", http.StatusInternalServerError)
 		return
 	}
@@ -259,105 +215,63 @@ func GetTaskByID(w http.ResponseWriter, r *http.Request) {
 
 // UpdateTask godoc
 // @Summary      Update task fields by ID
-// @Description  Partially update one or more fields of a task (title, description, status, priority, deadline).
+// @Description  Partially update one or more fields of a task (title, description, status, priority, deadline, tags, recurrence). Archiving a task archives all of its subtasks. Completing a recurring task creates its next occurrence, returned as next_taskid. Send the ETag of the task as If-Match to get a 412 instead of overwriting a change made by someone else since.
 // @Tags         Tasks
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int                     true  "Task ID"
 // @Param        task body      models.UpdateTaskRequest  true  "Fields to update"
+// @Param        If-Match  header  string  false  "Only update when the task's ETag is still this one (or *)"
 // @Success      200  {object}  models.GenricTaskResponse  "Task updated successfully"
+// @Header       200  {string}  ETag  "The task's new version"
 // @Failure      400  {string}  string  "Invalid input or missing ID"
+// @Failure      404  {string}  string  "Task not found"
+// @Failure      412  {string}  string  "The task was changed since, the ETag header has its current version"
+// @Failure      422  {string}  string  "Invalid tag or recurrence rule, recurrence without a deadline"
 // @Failure      500  {string}  string  "Internal server error"
 // @Router       /v1/tasks/{id} [patch]
-func UpdateTask(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	helper.SetJSONHeader(w)
 
-	idstr, exists := mux.Vars(r)["id"]
-	if !exists {
-		logger.Error("UpdateTask ~ id missing")
-		http.Error(w, "id missing", http.StatusBadRequest)
-		return
-	}
-	id, err := strconv.ParseInt(idstr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid task ID", http.StatusBadRequest)
+	id, ok := idFromPath(w, r, "UpdateTask", "task")
+	if !ok {
 		return
 	}
 
 	var t models.UpdateTaskRequest
 	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
-		logger.Error(err, "UpdateTask ~ request json decoding failed")
+		logger.ErrorContext(r.Context(), err, "UpdateTask ~ request json decoding failed")
 		http.Error(w, "invalid JOSN payload in request", http.StatusBadRequest)
 		return
 	}
-	// UPDATE tasksmaster SET title = ?, status = ? WHERE task_id = ?
-	var fields []string
-	var args []interface{}
-
-	if t.Title != nil {
-		if *t.Title == "" {
-			http.Error(w, "invalid/empty title", http.StatusBadRequest)
-			return
-		}
-		fields = append(fields, "title=?")
-		args = append(args, *t.Title)
-	}
-
-	if t.Description != nil {
-		fields = append(fields, "description = ?")
-		args = append(args, *t.Description)
-	}
 
-	if t.Status != nil {
-		if !(helper.IsValidStatus(*t.Status)) {
-			http.Error(w, "invalid status", http.StatusBadRequest)
-			return
-		}
-		fields = append(fields, "status = ?")
-		args = append(args, *t.Status)
-	}
-
-	if t.Priority != nil {
-		if !(helper.IsValidPriority(*t.Priority)) {
-			http.Error(w, "invalid priority", http.StatusBadRequest)
-			return
-		}
-		fields = append(fields, "priority = ?")
-		args = append(args, *t.Priority)
-	}
-
-	if t.DeadlineAt != nil {
-		fields = append(fields, "deadline_at = ?")
-		args = append(args, t.DeadlineAt.Format(time.RFC3339))
-	}
-
-	if len(fields) == 0 {
-		http.Error(w, "no fields to update", http.StatusBadRequest)
+	if msg := validateUpdate(t); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
 		return
 	}
 
-	query := fmt.Sprintf(`
-		UPDATE tasksmaster
-		SET %s 
-		WHERE task_id = ?`,
-		strings.Join(fields, ", "),
-	)
-	args = append(args, idstr)
-
-	if _, err := db.GetDBInfo().E(query, args...); err != nil {
-		logger.Error(err, "UpdateTask ~ query execution failed")
-		http.Error(w, "task updation failed", http.StatusInternalServerError)
+	op := h.newOp(r.Context(), "UpdateTask", httpOrigin(r), models.OP_UPDATE)
+	var nextID int64
+	err := h.ifMatch(r, op.caller, id, func(h *Handler) (err error) {
+		nextID, err = h.updateTask(op, id, t)
+		return err
+	})
+	if err != nil {
+		h.writeChangeError(w, r, id, err, "UpdateTask", "task updation failed")
 		return
 	}
+	op.record()
+	h.setETag(w, id)
 
 	resp := models.GenricTaskResponse{
-		TaskID:  int64(id),
-		Message: "Task updated",
+		TaskID:     id,
+		Message:    "Task updated",
+		NextTaskID: nextID,
 	}
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		logger.Error(err, "UpdateTask ~ json encoding failed")
+		logger.ErrorContext(r.Context(), err, "UpdateTask ~ json encoding failed")
 		http.Error(w, "updating task failed", http.StatusInternalServerError)
 		return
 	}
@@ -365,58 +279,131 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 
 // DeleteTask godoc
 // @Summary      Delete a task by ID
-// @Description  Deletes a task from the database using its unique ID. Returns 404 if the task does not exist.
+// @Description  Moves a task and all of its subtasks to the trash, from where they can be restored (POST /v1/trash/{id}/restore) until they are purged. Deleted tasks are left out of every other endpoint. Returns 404 if the task does not exist or is in the trash already.
 // @Tags         Tasks
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Task ID"
+// @Param        If-Match  header  string  false  "Only delete when the task's ETag is still this one (or *)"
 // @Success      200  {object}  models.GenricTaskResponse  "Task deleted successfully"
 // @Failure      400  {string}  string  "Invalid or missing task ID"
 // @Failure      404  {string}  string  "Task not found"
+// @Failure      412  {string}  string  "The task was changed since, the ETag header has its current version"
 // @Failure      500  {string}  string  "Internal server error"
 // @Router       /v1/tasks/{id} [delete]
-func DeleteTask(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	helper.SetJSONHeader(w)
 
-	idstr, exists := mux.Vars(r)["id"]
-	if !exists {
-		logger.Error("DeleteTask ~ id missing")
-		http.Error(w, "id missing", http.StatusBadRequest)
-		return
-	}
-	id, err := strconv.ParseInt(idstr, 10, 64)
-	if err != nil {
-		http.Error(w, "invalid task ID", http.StatusBadRequest)
+	id, ok := idFromPath(w, r, "DeleteTask", "task")
+	if !ok {
 		return
 	}
 
-	query := `
-		DELETE FROM tasksmaster
-		WHERE task_id = ?
-	`
-
-	result, err := db.GetDBInfo().E(query, id)
+	op := h.newOp(r.Context(), "DeleteTask", httpOrigin(r), models.OP_DELETE)
+	err := h.ifMatch(r, op.caller, id, func(h *Handler) error {
+		return h.deleteTask(op, id)
+	})
 	if err != nil {
-		logger.Error(err, "DeleteTask ~ delete query failed")
-		http.Error(w, "deleting task failed", http.StatusInternalServerError)
-		return
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "task not found", http.StatusNotFound)
+		h.writeChangeError(w, r, id, err, "DeleteTask", "deleting task failed")
 		return
 	}
+	op.record()
 
 	resp := models.GenricTaskResponse{
-		TaskID:  int64(id),
+		TaskID:  id,
 		Message: "Task deleted",
 	}
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		logger.Error(err, "DeleteTask ~ json encoding failed")
+		logger.ErrorContext(r.Context(), err, "DeleteTask ~ json encoding failed")
 		http.Error(w, "deleting task failed", http.StatusInternalServerError)
 		return
 	}
 }
+
+// the task changes behind both the HTTP handlers and the WebSocket API,
+// once the store is done each publishes its events and adds what changed
+// to op, which the caller records
+
+func (h *Handler) createTask(op *taskOp, req models.CreateTaskRequest) (int64, error) {
+	id, err := h.store.Create(req)
+	if err != nil {
+		return 0, err
+	}
+	h.created(op, id)
+	return id, nil
+}
+
+// returns the id of the next occurrence when a recurring task was completed
+func (h *Handler) updateTask(op *taskOp, id int64, req models.UpdateTaskRequest) (int64, error) {
+	// the whole subtree is compared since archiving cascades down
+	before, err := h.withSubtree(id)
+	if err != nil {
+		return 0, err
+	}
+	nextID, err := h.store.Update(id, req)
+	if err != nil {
+		return 0, err
+	}
+
+	after := h.subtreeAfter(op.ctx, op.caller, id)
+	if req.Status != nil && *req.Status == models.STATUS_ARCHIVED {
+		h.publish(op.ctx, op.caller, events.TaskUpdated, after...)
+	} else if len(after) > 0 {
+		h.publish(op.ctx, op.caller, events.TaskUpdated, after[0])
+	}
+	op.diff(before, after)
+
+	if nextID != 0 {
+		h.created(op, nextID)
+	}
+	return nextID, nil
+}
+
+func (h *Handler) deleteTask(op *taskOp, id int64) error {
+	// the events carry the tasks as they were before the delete
+	deleted, err := h.withSubtree(id)
+	if err != nil {
+		return err
+	}
+	if err := h.store.Delete(id); err != nil {
+		return err
+	}
+	h.publish(op.ctx, op.caller, events.TaskDeleted, deleted...)
+	op.diff(deleted, nil)
+	return nil
+}
+
+// runs move, a change dragging the task's subtree along
+func (h *Handler) moveTask(op *taskOp, id int64, move func() error) error {
+	before, err := h.withSubtree(id)
+	if err != nil {
+		return err
+	}
+	if err := move(); err != nil {
+		return err
+	}
+
+	after := h.subtreeAfter(op.ctx, op.caller, id)
+	h.publish(op.ctx, op.caller, events.TaskUpdated, after...)
+	op.diff(before, after)
+	return nil
+}
+
+// checks an update before it reaches the store, returns what is wrong with
+// it or "" when it is fine
+func validateUpdate(t models.UpdateTaskRequest) string {
+	switch {
+	case t.Title != nil && *t.Title == "":
+		return "invalid/empty title"
+	case t.Status != nil && !helper.IsValidStatus(*t.Status):
+		return "invalid status"
+	case t.Priority != nil && !helper.IsValidPriority(*t.Priority):
+		return "invalid priority"
+	case t.Title == nil && t.Description == nil && t.Status == nil &&
+		t.Priority == nil && t.DeadlineAt == nil && t.Tags == nil && t.Recurrence == nil:
+		return "no fields to update"
+	}
+	return ""
+}
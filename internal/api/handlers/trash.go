@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"queueit/internal/events"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"strconv"
+)
+
+// GetTrash godoc
+// @Summary      List deleted tasks
+// @Description  Fetch the tasks in the trash a page at a time, most recently deleted first, each with its deleted_at. Deleted tasks stay in the trash with their tags, subtasks and reminders until they are restored, purged, or older than the trash retention (TRASH_RETENTION, 30 days by default). Takes the filters and paging parameters of GET /v1/tasks.
+// @Tags         Trash
+// @Produce      json
+// @Param        status   query     string  false  "Comma-separated task statuses to filter (1=pending, 2=wip, 3=done, 4=archived)"
+// @Param        priority query     string  false  "Comma-separated priority values to filter (1=high,2=medium,3=low)"
+// @Param        tag      query     string  false  "Comma-separated tag names to filter"
+// @Param        project_id query   int     false  "Only the tasks of this project"
+// @Param        limit    query     int     false  "Page size, 1-500 (default 100)"
+// @Param        cursor   query     string  false  "next_cursor of the previous page, only valid with the same sort"
+// @Param        sort     query     string  false  "Comma-separated sort keys, - for descending, the GET /v1/tasks ones plus deleted_at (default -deleted_at)"
+// @Param        fields   query     string  false  "Comma-separated fields to return per task, e.g. task_id,title,deleted_at"
+// @Success      200  {object}  models.TaskPage
+// @Failure      400  {object}  models.ErrorResponse "Invalid filter, paging or field value, names the offending parameter"
+// @Failure      500  {string}  string "Fetching trash failed"
+// @Router       /v1/trash [get]
+func (h *Handler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	q := r.URL.Query()
+	filter, perr := parseTaskFilter(q)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+	if raw := q.Get("project_id"); raw != "" {
+		if filter.ProjectID, _ = strconv.ParseInt(raw, 10, 64); filter.ProjectID <= 0 {
+			writeParamError(w, r, &paramError{param: "project_id", value: raw, reason: "expected a positive project id"})
+			return
+		}
+	}
+	page, perr := parsePage(q, store.TrashSortFields)
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+	fields, perr := parseFields(q, models.GetTasksResponse{})
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+
+	tasks, next, err := h.store.ListTrash(filter, page)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		writeParamError(w, r, &paramError{param: "cursor", value: page.Cursor, reason: err.Error()})
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, "GetTrash ~ db query failed")
+		http.Error(w, "fetching trash failed", http.StatusInternalServerError)
+		return
+	}
+
+	writePage(w, r, "GetTrash", "fetching trash failed", tasks, next, fields)
+}
+
+// RestoreTask godoc
+// @Summary      Restore a deleted task
+// @Description  Takes a task out of the trash along with the subtasks deleted with it, announced as task.created. A subtask can only come back once its parent did, and the task's project must not be archived. The restore can be undone like any other change.
+// @Tags         Trash
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  models.GenricTaskResponse  "Task restored"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task ID"
+// @Failure      404  {string}  string  "Task not in the trash"
+// @Failure      409  {string}  string  "Parent task still in the trash"
+// @Failure      422  {string}  string  "Project archived"
+// @Failure      500  {string}  string  "Restoring task failed"
+// @Router       /v1/trash/{id}/restore [post]
+func (h *Handler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "RestoreTask", "task")
+	if !ok {
+		return
+	}
+
+	op := h.newOp(r.Context(), "RestoreTask", httpOrigin(r), models.OP_RESTORE)
+	if err := h.restoreTask(op, id); err != nil {
+		writeStoreError(w, r, err, "RestoreTask", "restoring task failed")
+		return
+	}
+	op.record()
+
+	resp := models.GenricTaskResponse{
+		TaskID:  id,
+		Message: "Task restored",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "RestoreTask ~ json encoding failed")
+		http.Error(w, "restoring task failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// PurgeTask godoc
+// @Summary      Delete a task for good
+// @Description  Removes a task in the trash and its subtasks permanently, along with their reminders. This can't be undone; the activity log keeps their history.
+// @Tags         Trash
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  models.GenricTaskResponse  "Task purged"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task ID"
+// @Failure      404  {string}  string  "Task not in the trash"
+// @Failure      500  {string}  string  "Purging task failed"
+// @Router       /v1/trash/{id} [delete]
+func (h *Handler) PurgeTask(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "PurgeTask", "task")
+	if !ok {
+		return
+	}
+
+	if err := h.store.Purge(id); err != nil {
+		writeStoreError(w, r, err, "PurgeTask", "purging task failed")
+		return
+	}
+
+	resp := models.GenricTaskResponse{
+		TaskID:  id,
+		Message: "Task purged",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "PurgeTask ~ json encoding failed")
+		http.Error(w, "purging task failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// the restored tasks are new again to everyone following the changes
+func (h *Handler) restoreTask(op *taskOp, id int64) error {
+	if err := h.store.Restore(id); err != nil {
+		return err
+	}
+	restored := h.subtreeAfter(op.ctx, op.caller, id)
+	h.publish(op.ctx, op.caller, events.TaskCreated, restored...)
+	op.diff(nil, restored)
+	return nil
+}
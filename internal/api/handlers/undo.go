@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"queueit/internal/events"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"slices"
+	"strconv"
+)
+
+// how many operations a single undo or redo may take back or replay
+const maxReplaySteps = 50
+
+// undo or redo would overwrite a change made since, or needs a task or
+// project that is gone
+type replayConflict struct {
+	reason string
+}
+
+func (e *replayConflict) Error() string {
+	return e.reason
+}
+
+func conflictf(format string, args ...any) error {
+	return &replayConflict{reason: fmt.Sprintf(format, args...)}
+}
+
+// UndoOperations godoc
+// @Summary      Undo the last operations of a session
+// @Description  Reverses the last task operations (create, update, delete, move) made with the same X-Queueit-Session header, newest first, deleted tasks coming back from the trash with their old ids, fields and tags. Every field is put back only if nobody changed it since; the first operation that can't be undone cleanly stops the undo, the ones before it stay undone. Making a new change drops the operations that were undone from the redo history.
+// @Tags         Activity
+// @Produce      json
+// @Param        X-Queueit-Session  header  string  true   "Client session the operations were made in"
+// @Param        steps              query   int     false  "Number of operations, 1-50 (default 1)"
+// @Success      200  {object}  models.UndoResponse  "The operations undone"
+// @Failure      400  {object}  models.ErrorResponse  "Missing session or invalid steps"
+// @Failure      409  {string}  string  "Nothing to undo, or the last operation conflicts with a later change"
+// @Failure      500  {string}  string  "Undo failed"
+// @Router       /v1/undo [post]
+func (h *Handler) UndoOperations(w http.ResponseWriter, r *http.Request) {
+	h.replayHTTP(w, r, "UndoOperations", true)
+}
+
+// RedoOperations godoc
+// @Summary      Redo the last undone operations of a session
+// @Description  Replays the operations the session undid, the most recently undone first, as long as the tasks still look the way the undo left them. The redo history is dropped as soon as the session makes a new change.
+// @Tags         Activity
+// @Produce      json
+// @Param        X-Queueit-Session  header  string  true   "Client session the operations were made in"
+// @Param        steps              query   int     false  "Number of operations, 1-50 (default 1)"
+// @Success      200  {object}  models.UndoResponse  "The operations redone"
+// @Failure      400  {object}  models.ErrorResponse  "Missing session or invalid steps"
+// @Failure      409  {string}  string  "Nothing to redo, or the next operation conflicts with a later change"
+// @Failure      500  {string}  string  "Redo failed"
+// @Router       /v1/redo [post]
+func (h *Handler) RedoOperations(w http.ResponseWriter, r *http.Request) {
+	h.replayHTTP(w, r, "RedoOperations", false)
+}
+
+func (h *Handler) replayHTTP(w http.ResponseWriter, r *http.Request, caller string, undo bool) {
+	helper.SetJSONHeader(w)
+
+	o := httpOrigin(r)
+	if o.session == "" {
+		writeParamError(w, r, &paramError{param: models.HEADER_SESSION, reason: "undo and redo need the session the changes were made in"})
+		return
+	}
+	steps, perr := parseSteps(r.URL.Query().Get("steps"))
+	if perr != nil {
+		writeParamError(w, r, perr)
+		return
+	}
+
+	resp, err := h.replay(r.Context(), caller, o, undo, steps)
+	if err != nil {
+		var conflict *replayConflict
+		if errors.As(err, &conflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		logger.ErrorContext(r.Context(), err, caller, "~ replaying operations failed")
+		http.Error(w, replayVerb(undo)+" failed", http.StatusInternalServerError)
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, caller, "~ JSON encoding failed")
+		http.Error(w, replayVerb(undo)+" failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+func parseSteps(raw string) (int, *paramError) {
+	if raw == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxReplaySteps {
+		return 0, &paramError{param: "steps", value: raw, reason: fmt.Sprintf("expected an integer between 1 and %d", maxReplaySteps)}
+	}
+	return n, nil
+}
+
+func replayVerb(undo bool) string {
+	if undo {
+		return "undo"
+	}
+	return "redo"
+}
+
+// undoes or redoes up to steps operations of the session, each in a
+// transaction of its own; a conflict only fails the whole when it hits the
+// first one
+func (h *Handler) replay(ctx context.Context, caller string, o origin, undo bool, steps int) (models.UndoResponse, error) {
+	h.replayMu.Lock()
+	defer h.replayMu.Unlock()
+
+	state, next, verb := models.OP_DONE, models.OP_UNDONE, "Undid"
+	if !undo {
+		state, next, verb = models.OP_UNDONE, models.OP_DONE, "Redid"
+	}
+
+	resp := models.UndoResponse{Operations: []models.Operation{}}
+	ops, err := h.store.SessionOperations(o.session, state, steps)
+	if err != nil {
+		return resp, err
+	}
+	if len(ops) == 0 {
+		return resp, conflictf("nothing to %s", replayVerb(undo))
+	}
+
+	for _, op := range ops {
+		// the checks, the writes and the new state of the operation commit
+		// together, so a change made meanwhile is never overwritten and a
+		// failing step leaves the tasks as they were
+		err := h.inTx(ctx, caller, func(tx *Handler) error {
+			return tx.replayOp(ctx, caller, o, op, undo)
+		})
+		var conflict *replayConflict
+		if errors.As(err, &conflict) && len(resp.Operations) > 0 {
+			resp.Message = fmt.Sprintf("%s %d of %d operations, operation %d conflicts: %s",
+				verb, len(resp.Operations), len(ops), op.OperationID, conflict.reason)
+			return resp, nil
+		}
+		if err != nil {
+			return resp, err
+		}
+		op.State = next
+		resp.Operations = append(resp.Operations, op)
+	}
+
+	resp.Message = fmt.Sprintf("%s %d operation", verb, len(resp.Operations))
+	if len(resp.Operations) > 1 {
+		resp.Message += "s"
+	}
+	return resp, nil
+}
+
+// one activity entry seen from the side of a replay: the task has to be in
+// the from state for it to be put in the to state
+type replayStep struct {
+	taskID     int64
+	fromExists bool
+	from       map[string]json.RawMessage
+	toExists   bool
+	to         map[string]json.RawMessage
+}
+
+// the steps taking the tasks of an operation back (undo) or forth again
+func replaySteps(changes []models.Activity, undo bool) []replayStep {
+	steps := make([]replayStep, 0, len(changes))
+	for _, a := range changes {
+		s := replayStep{
+			taskID:     a.TaskID,
+			fromExists: a.Action != models.ACTIVITY_CREATED,
+			from:       map[string]json.RawMessage{},
+			toExists:   a.Action != models.ACTIVITY_DELETED,
+			to:         map[string]json.RawMessage{},
+		}
+		// a created task has no old values, a deleted one no new ones
+		for _, c := range a.Changes {
+			if c.Old != nil {
+				s.from[c.Field] = c.Old
+			}
+			if c.New != nil {
+				s.to[c.Field] = c.New
+			}
+		}
+		if undo {
+			s.fromExists, s.toExists = s.toExists, s.fromExists
+			s.from, s.to = s.to, s.from
+		}
+		steps = append(steps, s)
+	}
+	if undo {
+		slices.Reverse(steps)
+	}
+	return steps
+}
+
+// the tasks an operation touches as they are now and as the replay leaves
+// them, a nil task is absent
+type replayPlan struct {
+	before map[int64]*models.GetTasksResponse
+	after  map[int64]*models.GetTasksResponse
+	order  []int64
+}
+
+// the task as the plan has it so far, else as the store has it
+func (h *Handler) planned(p *replayPlan, id int64) (*models.GetTasksResponse, error) {
+	if t, ok := p.after[id]; ok {
+		return t, nil
+	}
+	if t, ok := p.before[id]; ok {
+		return t, nil
+	}
+	t, err := h.store.Get(id)
+	if errors.Is(err, store.ErrTaskNotFound) {
+		p.before[id] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Children, t.Progress = nil, nil
+	p.before[id] = &t
+	return &t, nil
+}
+
+func (h *Handler) replayOp(ctx context.Context, caller string, o origin, op models.Operation, undo bool) error {
+	p := &replayPlan{before: map[int64]*models.GetTasksResponse{}, after: map[int64]*models.GetTasksResponse{}}
+	for _, s := range replaySteps(op.Changes, undo) {
+		cur, err := h.planned(p, s.taskID)
+		if err != nil {
+			return err
+		}
+		if err := checkStep(s, cur); err != nil {
+			return err
+		}
+		if _, ok := p.after[s.taskID]; !ok {
+			p.order = append(p.order, s.taskID)
+		}
+		if !s.toExists {
+			p.after[s.taskID] = nil
+			continue
+		}
+		t, err := applyFields(cur, s.taskID, s.to)
+		if err != nil {
+			return err
+		}
+		p.after[s.taskID] = &t
+	}
+	if err := h.checkPlan(p); err != nil {
+		return err
+	}
+
+	// parents go first so a restored subtree is put back top down, and
+	// tasks are deleted last when their children have moved out
+	var puts, deletes []int64
+	for _, id := range p.order {
+		switch {
+		case p.after[id] != nil:
+			puts = append(puts, id)
+		case p.before[id] != nil:
+			deletes = append(deletes, id)
+		}
+	}
+	slices.SortStableFunc(puts, func(a, b int64) int { return p.depth(a) - p.depth(b) })
+
+	for _, id := range puts {
+		if err := h.store.PutTask(*p.after[id]); err != nil {
+			return err
+		}
+	}
+	for _, id := range deletes {
+		// already gone with an ancestor deleted before it
+		if err := h.store.Delete(id); err != nil && !errors.Is(err, store.ErrTaskNotFound) {
+			return err
+		}
+	}
+
+	var before, after []models.GetTasksResponse
+	for _, id := range p.order {
+		if t := p.before[id]; t != nil {
+			before = append(before, *t)
+		}
+	}
+	for _, id := range puts {
+		t, err := h.store.Get(id)
+		if err != nil {
+			logger.ErrorContext(ctx, err, caller, "~ fetching task", id, "after the", replayVerb(undo), "failed")
+			continue
+		}
+		after = append(after, t)
+		if p.before[id] == nil {
+			h.publish(ctx, caller, events.TaskCreated, t)
+		} else {
+			h.publish(ctx, caller, events.TaskUpdated, t)
+		}
+	}
+	for _, id := range deletes {
+		h.publish(ctx, caller, events.TaskDeleted, *p.before[id])
+	}
+
+	entries, err := diffTasks(o, before, after)
+	if err != nil {
+		logger.ErrorContext(ctx, err, caller, "~ diffing tasks for the activity log failed")
+	}
+	state := models.OP_UNDONE
+	if !undo {
+		state = models.OP_DONE
+	}
+	return h.store.SetOperationState(op.OperationID, state, entries)
+}
+
+// the task has to be there (or not) with the fields the step starts from
+func checkStep(s replayStep, cur *models.GetTasksResponse) error {
+	switch {
+	case s.fromExists && cur == nil:
+		return conflictf("task %d was deleted since", s.taskID)
+	case !s.fromExists && cur != nil:
+		return conflictf("task %d exists again", s.taskID)
+	case cur == nil:
+		return nil
+	}
+
+	fields, err := taskFields(cur)
+	if err != nil {
+		return err
+	}
+	for _, f := range auditedFields {
+		want, ok := s.from[f]
+		if !ok {
+			continue
+		}
+		have := fields[f]
+		if have == nil {
+			have = json.RawMessage("null")
+		}
+		if !bytes.Equal(have, want) {
+			return conflictf("task %d: %s was changed since", s.taskID, f)
+		}
+	}
+	return nil
+}
+
+// the task with the given fields overlaid, built from scratch when it is
+// absent; fields a task was deleted without are left empty
+func applyFields(cur *models.GetTasksResponse, id int64, fields map[string]json.RawMessage) (models.GetTasksResponse, error) {
+	base := models.GetTasksResponse{TaskID: int(id), Tags: []string{}}
+	if cur != nil {
+		base = *cur
+	}
+	merged, err := taskFields(&base)
+	if err != nil {
+		return base, err
+	}
+	for f, v := range fields {
+		merged[f] = v
+	}
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return base, err
+	}
+	var t models.GetTasksResponse
+	if err := json.Unmarshal(b, &t); err != nil {
+		return base, err
+	}
+	if t.Tags == nil {
+		t.Tags = []string{}
+	}
+	return t, nil
+}
+
+// checks the planned tasks fit with the rest of the tree: no orphaned
+// subtasks, no missing parents or projects and no cycles
+func (h *Handler) checkPlan(p *replayPlan) error {
+	for _, id := range p.order {
+		t, cur := p.after[id], p.before[id]
+		if t == nil {
+			if cur == nil {
+				continue
+			}
+			children, err := h.store.Subtree(id)
+			if err != nil {
+				return err
+			}
+			for _, c := range children {
+				if _, ok := p.after[int64(c.TaskID)]; !ok {
+					return conflictf("task %d got subtask %d since", id, c.TaskID)
+				}
+			}
+			continue
+		}
+
+		seen := map[int64]bool{id: true}
+		for parent := t.ParentTaskID; parent != nil; {
+			pid := int64(*parent)
+			if seen[pid] {
+				return conflictf("task %d would become its own ancestor", id)
+			}
+			seen[pid] = true
+			pt, err := h.planned(p, pid)
+			if err != nil {
+				return err
+			}
+			if pt == nil {
+				return conflictf("parent task %d of task %d is gone", pid, id)
+			}
+			parent = pt.ParentTaskID
+		}
+
+		if cur == nil || cur.ProjectID != t.ProjectID {
+			project, err := h.store.GetProject(int64(t.ProjectID))
+			if errors.Is(err, store.ErrProjectNotFound) || (err == nil && project.Archived) {
+				return conflictf("project %d of task %d is gone or archived", t.ProjectID, id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sorts a planned task after its planned ancestors
+func (p *replayPlan) depth(id int64) int {
+	d := 0
+	for t := p.after[id]; t != nil && t.ParentTaskID != nil && d <= len(p.order); d++ {
+		t = p.after[int64(*t.ParentTaskID)]
+	}
+	return d
+}
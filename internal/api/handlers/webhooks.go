@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"queueit/internal/helper"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"strconv"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// GetAllWebhooks godoc
+// @Summary      List the registered webhooks
+// @Tags         Webhooks
+// @Produce      json
+// @Success      200  {array}   models.Webhook
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      500  {string}  string  "Fetching webhooks failed"
+// @Router       /v1/webhooks [get]
+func (h *Handler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	webhooks, err := h.store.ListWebhooks()
+	if err != nil {
+		logger.ErrorContext(r.Context(), err, "GetAllWebhooks ~ db query failed")
+		http.Error(w, "fetching webhooks failed", http.StatusInternalServerError)
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(webhooks); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetAllWebhooks ~ JSON encoding failed")
+		http.Error(w, "fetching webhooks failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// GetWebhookByID godoc
+// @Summary      Get webhook details by ID
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      200  {object}  models.Webhook
+// @Failure      400  {object}  models.ErrorResponse  "Invalid webhook ID"
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      404  {string}  string  "Webhook not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/webhooks/{id} [get]
+func (h *Handler) GetWebhookByID(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetWebhookByID", "webhook")
+	if !ok {
+		return
+	}
+
+	wh, err := h.store.GetWebhook(id)
+	if err != nil {
+		writeStoreError(w, r, err, "GetWebhookByID", "fetching webhook failed")
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(wh); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetWebhookByID ~ JSON encoding failed")
+		http.Error(w, "fetching webhook failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// CreateWebhook godoc
+// @Summary      Register a webhook
+// @Description  Every task.created, task.updated and task.deleted event, or only the ones listed in events, is POSTed to url as JSON. The X-Queueit-Signature-256 header carries sha256=<hex HMAC-SHA256 of the body keyed with the secret>. A blank secret is generated and returned once in the response. Failed deliveries are retried with exponential backoff.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body      models.CreateWebhookRequest  true  "Webhook to register"
+// @Success      200  {object}  models.GenricWebhookResponse  "Webhook created successfully"
+// @Failure      400  {string}  string  "Invalid JSON"
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      422  {string}  string  "Invalid url or unknown event type"
+// @Failure      500  {string}  string  "Creating webhook failed"
+// @Router       /v1/webhooks [post]
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	var cwr models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&cwr); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateWebhook ~ JSON decoding failed")
+		http.Error(w, "creating webhook failed", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	webhookID, secret, err := h.store.CreateWebhook(cwr)
+	if err != nil {
+		writeStoreError(w, r, err, "CreateWebhook", "creating webhook failed")
+		return
+	}
+	resp := models.GenricWebhookResponse{
+		WebhookID: webhookID,
+		Secret:    secret,
+		Message:   "Webhook created",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "CreateWebhook ~ json encoding failed")
+		http.Error(w, "creating webhook failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateWebhook godoc
+// @Summary      Change the url, secret, events or active flag of a webhook
+// @Description  An empty events list subscribes to every event type. Deliveries to an inactive webhook are held back until it is activated again.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                          true  "Webhook ID"
+// @Param        webhook  body      models.UpdateWebhookRequest  true  "Fields to update"
+// @Success      200  {object}  models.GenricWebhookResponse  "Webhook updated successfully"
+// @Failure      400  {string}  string  "Invalid input or missing ID"
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      404  {string}  string  "Webhook not found"
+// @Failure      422  {string}  string  "Invalid url, secret or event type"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/webhooks/{id} [patch]
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "UpdateWebhook", "webhook")
+	if !ok {
+		return
+	}
+
+	var uwr models.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&uwr); err != nil {
+		logger.ErrorContext(r.Context(), err, "UpdateWebhook ~ request json decoding failed")
+		http.Error(w, "invalid JSON payload in request", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if uwr.URL == nil && uwr.Secret == nil && uwr.Events == nil && uwr.Active == nil {
+		http.Error(w, "no fields to update", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpdateWebhook(id, uwr); err != nil {
+		writeStoreError(w, r, err, "UpdateWebhook", "webhook updation failed")
+		return
+	}
+
+	resp := models.GenricWebhookResponse{
+		WebhookID: id,
+		Message:   "Webhook updated",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "UpdateWebhook ~ json encoding failed")
+		http.Error(w, "updating webhook failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook by ID
+// @Description  Deletes the webhook along with its delivery log, pending deliveries are dropped
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id   path      int  true  "Webhook ID"
+// @Success      200  {object}  models.GenricWebhookResponse  "Webhook deleted successfully"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid webhook ID"
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      404  {string}  string  "Webhook not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "DeleteWebhook", "webhook")
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteWebhook(id); err != nil {
+		writeStoreError(w, r, err, "DeleteWebhook", "deleting webhook failed")
+		return
+	}
+
+	resp := models.GenricWebhookResponse{
+		WebhookID: id,
+		Message:   "Webhook deleted",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "DeleteWebhook ~ json encoding failed")
+		http.Error(w, "deleting webhook failed", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      The delivery log of a webhook
+// @Description  Newest first. Pending deliveries carry next_attempt_at, failed ones have given up after their last attempt.
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id     path      int  true   "Webhook ID"
+// @Param        limit  query     int  false  "Number of deliveries, 1-500 (default 50)"
+// @Success      200  {array}   models.WebhookDelivery
+// @Failure      400  {object}  models.ErrorResponse  "Invalid webhook ID or limit"
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      404  {string}  string  "Webhook not found"
+// @Failure      500  {string}  string  "Fetching deliveries failed"
+// @Router       /v1/webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "GetWebhookDeliveries", "webhook")
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxDeliveryLimit {
+			writeParamError(w, r, &paramError{param: "limit", value: raw, reason: fmt.Sprintf("expected an integer between 1 and %d", maxDeliveryLimit)})
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.store.ListDeliveries(id, limit)
+	if err != nil {
+		writeStoreError(w, r, err, "GetWebhookDeliveries", "fetching deliveries failed")
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = json.NewEncoder(buffer).Encode(deliveries); err != nil {
+		logger.ErrorContext(r.Context(), err, "GetWebhookDeliveries ~ JSON encoding failed")
+		http.Error(w, "fetching deliveries failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// RedeliverWebhook godoc
+// @Summary      Send a delivery again
+// @Description  Queues a new delivery of the same event and payload, the original stays in the log untouched. The new one is attempted within a few seconds.
+// @Tags         Webhooks
+// @Produce      json
+// @Param        id           path      int  true  "Webhook ID"
+// @Param        delivery_id  path      int  true  "Delivery ID"
+// @Success      200  {object}  models.GenricDeliveryResponse  "Redelivery queued"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid webhook or delivery ID"
+// @Failure      403  {string}  string  "Sent from a page of another origin"
+// @Failure      404  {string}  string  "Webhook or delivery not found"
+// @Failure      500  {string}  string  "Internal server error"
+// @Router       /v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *Handler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	helper.SetJSONHeader(w)
+
+	id, ok := idFromPath(w, r, "RedeliverWebhook", "webhook")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, r, "RedeliverWebhook", "delivery_id", "delivery")
+	if !ok {
+		return
+	}
+
+	newID, err := h.store.Redeliver(id, deliveryID)
+	if err != nil {
+		writeStoreError(w, r, err, "RedeliverWebhook", "redelivery failed")
+		return
+	}
+
+	resp := models.GenricDeliveryResponse{
+		DeliveryID: newID,
+		Message:    "Redelivery queued",
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.ErrorContext(r.Context(), err, "RedeliverWebhook ~ json encoding failed")
+		http.Error(w, "redelivery failed", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// time allowed to write a frame
+	wsWriteWait = 10 * time.Second
+
+	// a connection whose pongs stop for this long is dropped
+	wsPongWait = 60 * time.Second
+
+	// pings go out a bit more often than pongs are expected
+	wsPingPeriod = wsPongWait * 9 / 10
+
+	wsMaxMessageSize = 1 << 20
+)
+
+// WebSocket handshakes aren't covered by CORS, so any page the user opens
+// could connect and change tasks; the upgrader's own origin check (the nil
+// CheckOrigin) only lets in clients sending no Origin, like the CLI, and
+// pages served by this host, like the webview
+var upgrader = websocket.Upgrader{}
+
+// GetWebSocket godoc
+// @Summary      WebSocket API for task operations and change broadcasts
+// @Description  Upgrades to a WebSocket speaking JSON frames (models.WSMessage). A request {"id": 1, "method": "tasks.update", "params": {...}} is answered by {"id": 1, "result": {...}} or {"id": 1, "error": {"code": 404, "message": "..."}}, the code being the HTTP status the same request gets over HTTP.
+// @Description  Methods: tasks.list (params are the GET /v1/tasks query parameters plus project_id as a JSON object, lists may be JSON arrays), tasks.get {task_id}, tasks.create (models.CreateTaskRequest), tasks.update (task_id plus models.UpdateTaskRequest), tasks.delete {task_id}, undo and redo {steps} (like POST /v1/undo and /v1/redo, for the changes made over the connection or its session), subscribe (models.WSSubscribeRequest), unsubscribe and ping.
+// @Description  After subscribe the connection receives {"method": "event", "params": models.Event} for every task change in the subscribed project and statuses, a task leaving them is still announced once. {"method": "reset"} means events were pruned before they could be sent, refetch the tasks. The server pings every 54s and drops connections that stop answering.
+// @Tags         Events
+// @Param        actor    query  string  false  "Recorded as the actor of the changes made over the connection, like the X-Queueit-Actor header"
+// @Param        session  query  string  false  "Undo session of the changes made over the connection, like the X-Queueit-Session header (default: one of its own)"
+// @Success      101  {object}  models.WSMessage  "Switching protocols"
+// @Failure      400  {string}  string  "Not a WebSocket handshake"
+// @Failure      403  {string}  string  "Origin header naming another host than the server"
+// @Router       /v1/ws [get]
+func (h *Handler) GetWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has written the error response already
+		logger.ErrorContext(r.Context(), err, "GetWebSocket ~ upgrade failed")
+		return
+	}
+
+	ctx, cancel := h.streamContext(r)
+	c := &wsConn{
+		h:      h,
+		conn:   conn,
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan models.WSMessage, 64),
+		known:  map[int]bool{},
+	}
+	c.origin = wsOrigin(r)
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		c.writeLoop()
+	}()
+	c.readLoop()
+
+	cancel()
+	c.unsubscribe()
+	<-written
+}
+
+// browsers can't set headers on a WebSocket handshake, they name the
+// actor with ?actor= and the session with ?session= instead; without a
+// session the connection is one of its own
+func wsOrigin(r *http.Request) origin {
+	actor := r.Header.Get(models.HEADER_ACTOR)
+	if actor == "" {
+		actor = r.URL.Query().Get("actor")
+	}
+	session := r.Header.Get(models.HEADER_SESSION)
+	if session == "" {
+		session = r.URL.Query().Get("session")
+	}
+	if session = sessionName(session); session == "" {
+		session = "ws-" + rand.Text()
+	}
+	return origin{actor: actorName(actor), source: models.SOURCE_WS, session: session}
+}
+
+// one WebSocket client, requests are handled one at a time in readLoop and
+// every frame goes out through writeLoop as gorilla/websocket allows a
+// single writer
+type wsConn struct {
+	h      *Handler
+	conn   *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc // ends ctx, once the client is gone
+	send   chan models.WSMessage
+	origin origin // of every change made over the connection
+
+	mu sync.Mutex
+	// tasks the client was told about, changes to them are forwarded even
+	// when they no longer match the subscription
+	known map[int]bool
+	// stops the running subscription and waits for it to finish
+	stopSub func()
+}
+
+func (c *wsConn) readLoop() {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var req models.WSMessage
+		if err := c.conn.ReadJSON(&req); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				c.reply(models.WSMessage{Error: &models.WSError{Code: http.StatusBadRequest, Message: "invalid JSON frame"}})
+				continue
+			}
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.ErrorContext(c.ctx, err, "GetWebSocket ~ reading frame failed")
+			}
+			return
+		}
+
+		resp := models.WSMessage{ID: req.ID}
+		resp.Result, resp.Error = c.handle(req)
+		if !c.reply(resp) {
+			return
+		}
+	}
+}
+
+// queues a frame, false once the connection is going away
+func (c *wsConn) reply(m models.WSMessage) bool {
+	select {
+	case c.send <- m:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+func (c *wsConn) writeLoop() {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+	// also unblocks readLoop when writing fails, and whatever is blocked
+	// queueing a frame nobody will write
+	defer c.conn.Close()
+	defer c.cancel()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case m := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(m); err != nil {
+				logger.ErrorContext(c.ctx, err, "GetWebSocket ~ writing frame failed")
+				return
+			}
+		case <-ping.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// runs a request, returning either its result or its error
+func (c *wsConn) handle(req models.WSMessage) (any, *models.WSError) {
+	switch req.Method {
+	case "ping":
+		return "pong", nil
+
+	case "tasks.list":
+		return c.listTasks(req.Params)
+
+	case "tasks.get":
+		var p struct {
+			TaskID int64 `json:"task_id"`
+		}
+		if werr := decodeParams(req.Params, &p); werr != nil {
+			return nil, werr
+		}
+		t, err := c.h.store.Get(p.TaskID)
+		if err != nil {
+			return nil, wsStoreError(c.ctx, err, "tasks.get", "fetching task failed")
+		}
+		c.remember(t)
+		return t, nil
+
+	case "tasks.create":
+		var p models.CreateTaskRequest
+		if werr := decodeParams(req.Params, &p); werr != nil {
+			return nil, werr
+		}
+		if p.Title == "" {
+			return nil, &models.WSError{Code: http.StatusUnprocessableEntity, Message: "title cannot be blank"}
+		}
+		op := c.h.newOp(c.ctx, "GetWebSocket", c.origin, models.OP_CREATE)
+		id, err := c.h.createTask(op, p)
+		if err != nil {
+			return nil, wsStoreError(c.ctx, err, "tasks.create", "creating task failed")
+		}
+		op.record()
+		return models.GenricTaskResponse{TaskID: id, Message: "Task created"}, nil
+
+	case "tasks.update":
+		var p struct {
+			TaskID int64 `json:"task_id"`
+			models.UpdateTaskRequest
+		}
+		if werr := decodeParams(req.Params, &p); werr != nil {
+			return nil, werr
+		}
+		if msg := validateUpdate(p.UpdateTaskRequest); msg != "" {
+			return nil, &models.WSError{Code: http.StatusBadRequest, Message: msg}
+		}
+		op := c.h.newOp(c.ctx, "GetWebSocket", c.origin, models.OP_UPDATE)
+		nextID, err := c.h.updateTask(op, p.TaskID, p.UpdateTaskRequest)
+		if err != nil {
+			return nil, wsStoreError(c.ctx, err, "tasks.update", "updating task failed")
+		}
+		op.record()
+		return models.GenricTaskResponse{TaskID: p.TaskID, Message: "Task updated", NextTaskID: nextID}, nil
+
+	case "tasks.delete":
+		var p struct {
+			TaskID int64 `json:"task_id"`
+		}
+		if werr := decodeParams(req.Params, &p); werr != nil {
+			return nil, werr
+		}
+		op := c.h.newOp(c.ctx, "GetWebSocket", c.origin, models.OP_DELETE)
+		if err := c.h.deleteTask(op, p.TaskID); err != nil {
+			return nil, wsStoreError(c.ctx, err, "tasks.delete", "deleting task failed")
+		}
+		op.record()
+		return models.GenricTaskResponse{TaskID: p.TaskID, Message: "Task deleted"}, nil
+
+	case "undo", "redo":
+		var p struct {
+			Steps int `json:"steps"`
+		}
+		if werr := decodeParams(req.Params, &p); werr != nil {
+			return nil, werr
+		}
+		if p.Steps == 0 {
+			p.Steps = 1
+		}
+		if p.Steps < 1 || p.Steps > maxReplaySteps {
+			return nil, wsParamError(&paramError{param: "steps", value: strconv.Itoa(p.Steps), reason: fmt.Sprintf("expected an integer between 1 and %d", maxReplaySteps)})
+		}
+		resp, err := c.h.replay(c.ctx, "GetWebSocket", c.origin, req.Method == "undo", p.Steps)
+		var conflict *replayConflict
+		if errors.As(err, &conflict) {
+			return nil, &models.WSError{Code: http.StatusConflict, Message: err.Error()}
+		}
+		if err != nil {
+			logger.ErrorContext(c.ctx, err, "GetWebSocket ~ replaying operations failed")
+			return nil, &models.WSError{Code: http.StatusInternalServerError, Message: req.Method + " failed"}
+		}
+		return resp, nil
+
+	case "subscribe":
+		var p models.WSSubscribeRequest
+		if werr := decodeParams(req.Params, &p); werr != nil {
+			return nil, werr
+		}
+		if werr := c.subscribe(p); werr != nil {
+			return nil, werr
+		}
+		return p, nil
+
+	case "unsubscribe":
+		c.unsubscribe()
+		return "unsubscribed", nil
+	}
+
+	return nil, &models.WSError{Code: http.StatusNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}
+}
+
+func (c *wsConn) listTasks(raw json.RawMessage) (any, *models.WSError) {
+	q, werr := paramsToQuery(raw)
+	if werr != nil {
+		return nil, werr
+	}
+
+	filter, perr := parseTaskFilter(q)
+	if perr != nil {
+		return nil, wsParamError(perr)
+	}
+	page, perr := parsePage(q, store.TaskSortFields)
+	if perr != nil {
+		return nil, wsParamError(perr)
+	}
+	fields, perr := parseFields(q, models.GetTasksResponse{})
+	if perr != nil {
+		return nil, wsParamError(perr)
+	}
+	if p := q.Get("project_id"); p != "" {
+		if filter.ProjectID, _ = strconv.ParseInt(p, 10, 64); filter.ProjectID <= 0 {
+			return nil, wsParamError(&paramError{param: "project_id", value: p, reason: "expected a positive project id"})
+		}
+	}
+
+	tasks, next, err := c.h.store.List(filter, page)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		return nil, wsParamError(&paramError{param: "cursor", value: page.Cursor, reason: err.Error()})
+	}
+	if err != nil {
+		return nil, wsStoreError(c.ctx, err, "tasks.list", "fetching tasks failed")
+	}
+	c.remember(tasks...)
+
+	items, err := selectFields(tasks, fields)
+	if err != nil {
+		return nil, wsStoreError(c.ctx, err, "tasks.list", "fetching tasks failed")
+	}
+	return pageBody{Items: items, NextCursor: next}, nil
+}
+
+func (c *wsConn) remember(tasks ...models.GetTasksResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range tasks {
+		c.known[t.TaskID] = true
+	}
+}
+
+// replaces the running subscription, the events are read from the log
+// in their own goroutine
+func (c *wsConn) subscribe(p models.WSSubscribeRequest) *models.WSError {
+	c.unsubscribe()
+
+	reader, err := c.h.events.Reader()
+	if err != nil {
+		return wsStoreError(c.ctx, err, "subscribe", "reading events failed")
+	}
+	reset := false
+	if p.LastEventID != nil {
+		// an id from the future belongs to another database, or a log that was reset
+		if *p.LastEventID >= 0 && *p.LastEventID <= reader.Pos() {
+			reader.ResumeAfter(*p.LastEventID)
+		} else {
+			reset = true
+		}
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer reader.Close()
+		if reset && !c.reply(models.WSMessage{Method: "reset"}) {
+			return
+		}
+		c.forward(ctx, reader, p)
+	}()
+
+	c.mu.Lock()
+	c.stopSub = func() {
+		cancel()
+		<-done
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *wsConn) unsubscribe() {
+	c.mu.Lock()
+	stop := c.stopSub
+	c.stopSub = nil
+	c.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// sends the events matching the subscription until ctx is done
+func (c *wsConn) forward(ctx context.Context, reader *events.Reader, p models.WSSubscribeRequest) {
+	for {
+		batch, reset, err := reader.Next(ctx, wsPingPeriod)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorContext(ctx, err, "GetWebSocket ~ reading the event log failed")
+			}
+			return
+		}
+		if reset && !c.reply(models.WSMessage{Method: "reset"}) {
+			return
+		}
+
+		for _, e := range batch {
+			if !c.wants(e, p) {
+				continue
+			}
+			params, err := json.Marshal(e)
+			if err != nil {
+				logger.ErrorContext(ctx, err, "GetWebSocket ~ JSON encoding failed")
+				return
+			}
+			if !c.reply(models.WSMessage{Method: "event", Params: params}) {
+				return
+			}
+		}
+	}
+}
+
+// whether the event is for the subscriber, tasks it knows about are
+// forwarded regardless so it learns when they leave the subscription
+func (c *wsConn) wants(e models.Event, p models.WSSubscribeRequest) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matches := (p.ProjectID == 0 || e.Task.ProjectID == p.ProjectID) &&
+		(len(p.Statuses) == 0 || slices.Contains(p.Statuses, e.Task.Status))
+	known := c.known[e.Task.TaskID]
+
+	switch {
+	case matches && e.Type != events.TaskDeleted:
+		c.known[e.Task.TaskID] = true
+	case known:
+		delete(c.known, e.Task.TaskID)
+	}
+	return matches || known
+}
+
+// decodes the params of a request, missing params decode as {}
+func decodeParams(raw json.RawMessage, v any) *models.WSError {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &models.WSError{Code: http.StatusBadRequest, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// turns {"status": [1, 2], "limit": 50} into the query parameters the
+// HTTP list takes, status=1,2&limit=50
+func paramsToQuery(raw json.RawMessage) (url.Values, *models.WSError) {
+	var params map[string]any
+	if werr := decodeParams(raw, &params); werr != nil {
+		return nil, werr
+	}
+
+	q := url.Values{}
+	for name, v := range params {
+		values, ok := v.([]any)
+		if !ok {
+			values = []any{v}
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			switch v := v.(type) {
+			case string:
+				parts[i] = v
+			case float64:
+				parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				parts[i] = strconv.FormatBool(v)
+			default:
+				return nil, &models.WSError{Code: http.StatusBadRequest, Message: "invalid params: " + name + " must be a string, number, bool or a list of them", Param: name}
+			}
+		}
+		q.Set(name, strings.Join(parts, ","))
+	}
+	return q, nil
+}
+
+func wsParamError(e *paramError) *models.WSError {
+	return &models.WSError{Code: http.StatusBadRequest, Message: e.Error(), Param: e.param, Value: e.value}
+}
+
+// the WebSocket counterpart of writeStoreError
+func wsStoreError(ctx context.Context, err error, method, failure string) *models.WSError {
+	status := storeErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger.ErrorContext(ctx, err, "GetWebSocket ~", method, "failed")
+		return &models.WSError{Code: status, Message: failure}
+	}
+	return &models.WSError{Code: status, Message: err.Error()}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"queueit/pkg/logger"
+	"time"
+)
+
+// logs every request once it is done with its status, the bytes sent and
+// how long it took; 5xx responses are logged as warnings
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context())
+		start := time.Now()
+		rw := wrap(w)
+
+		// event streams and WebSockets only finish when the client leaves
+		log.Debug("request started", "method", r.Method, "uri", r.RequestURI, "remote", r.RemoteAddr)
+
+		// deferred to log aborted responses too
+		defer func() {
+			level := slog.LevelInfo
+			if rw.statusCode() >= 500 {
+				level = slog.LevelWarn
+			}
+			log.Log(r.Context(), level, "request",
+				"method", r.Method,
+				"uri", r.RequestURI,
+				"status", rw.statusCode(),
+				"bytes", rw.bytes,
+				"duration", time.Since(start),
+				"remote", r.RemoteAddr,
+			)
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -10,8 +10,9 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		} else {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 		}
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Queueit-Actor, X-Queueit-Session, If-Match, If-None-Match, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		// Handle preflight (OPTIONS) requests
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"runtime/debug"
+)
+
+// turns a panicking handler into a 500 with a JSON error carrying the
+// request id, the panic and its stack go to the log
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := wrap(w)
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// the server's way of dropping a response on purpose
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			logger.FromContext(r.Context()).Error("handler panicked",
+				"method", r.Method, "uri", r.RequestURI, "panic", p, "stack", string(debug.Stack()))
+
+			// part of the response is out, all that's left is cutting it short
+			if rw.written() {
+				panic(http.ErrAbortHandler)
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(rw).Encode(models.ErrorResponse{
+				Error:     "internal server error",
+				RequestID: RequestID(r.Context()),
+			})
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"queueit/pkg/logger"
+)
+
+const HEADER_REQUEST_ID = "X-Request-ID"
+
+// the longest X-Request-ID taken from a client, longer ones are replaced
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// gives every request an id, the X-Request-ID it came with or a new one,
+// sent back in X-Request-ID and carried by the request's logger, which
+// the handlers find with logger.FromContext(r.Context())
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HEADER_REQUEST_ID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(HEADER_REQUEST_ID, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = logger.NewContext(ctx, logger.With("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// the id RequestIDMiddleware gave the request, blank outside of it
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// an id from a proxy or client is taken as long as it can't garble the log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// 16 hex digits, random enough to tell the requests in a log apart
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,47 +1,141 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
-	"os"
 	"queueit/internal/api/handlers"
 	"queueit/internal/api/middleware"
+	"queueit/internal/events"
+	"queueit/internal/store"
 	"queueit/pkg/logger"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// how long a shutdown waits for the requests in flight before cutting them off
+const shutdownTimeout = 10 * time.Second
+
 type API struct {
-	router *mux.Router
+	router   http.Handler
+	handlers *handlers.Handler
 }
 
-// creates a new router instance usingn gorilla mux lib
-func NewRouter() *API {
+// creates a new router instance usingn gorilla mux lib, the handlers
+// read and write through the given store and publish task changes on bus
+func NewRouter(s store.Store, bus *events.Bus) *API {
 	mr := mux.NewRouter()
+	h := handlers.New(s, bus)
 
 	// middleware implementations:
 	mr.Use(middleware.CORSMiddleware)
-	mr.Use(middleware.LoggingMiddleware)
-
-	mr.HandleFunc("/v1/health", handlers.HandleHealth).Methods("GET", "OPTIONS")
-	mr.HandleFunc("/v1/tasks", handlers.GetAllTasks).Methods("GET", "OPTIONS")
-	mr.HandleFunc("/v1/tasks/{id}", handlers.GetTaskByID).Methods("GET")
-	mr.HandleFunc("/v1/tasks", handlers.CreateTask).Methods("POST", "OPTIONS")
-	mr.HandleFunc("/v1/tasks/{id}", handlers.UpdateTask).Methods("PUT", "PATCH")
-	mr.HandleFunc("/v1/tasks/{id}", handlers.DeleteTask).Methods("DELETE")
+
+	mr.HandleFunc("/v1/health", h.HandleHealth).Methods("GET", "OPTIONS")
+	mr.HandleFunc("/v1/tasks", h.GetAllTasks).Methods("GET", "OPTIONS")
+	mr.HandleFunc("/v1/tasks/{id}", h.GetTaskByID).Methods("GET")
+	mr.HandleFunc("/v1/tasks", h.CreateTask).Methods("POST", "OPTIONS")
+	mr.HandleFunc("/v1/tasks/bulk", h.BulkTasks).Methods("POST", "OPTIONS")
+	mr.HandleFunc("/v1/tasks/{id}", h.UpdateTask).Methods("PUT", "PATCH")
+	mr.HandleFunc("/v1/tasks/{id}", h.DeleteTask).Methods("DELETE")
+	mr.HandleFunc("/v1/tasks/{id}/parent", h.MoveTask).Methods("PUT")
+	mr.HandleFunc("/v1/tasks/{id}/project", h.MoveTaskToProject).Methods("PUT")
+	mr.HandleFunc("/v1/tasks/{id}/occurrences", h.GetTaskOccurrences).Methods("GET")
+	mr.HandleFunc("/v1/tasks/{id}/reminders", h.GetTaskReminders).Methods("GET")
+	mr.HandleFunc("/v1/tasks/{id}/reminders", h.CreateReminder).Methods("POST")
+	mr.HandleFunc("/v1/reminders/{id}", h.DeleteReminder).Methods("DELETE")
+	mr.HandleFunc("/v1/tasks/{id}/history", h.GetTaskHistory).Methods("GET")
+	mr.HandleFunc("/v1/activity", h.GetActivity).Methods("GET")
+	mr.HandleFunc("/v1/trash", h.GetTrash).Methods("GET", "OPTIONS")
+	mr.HandleFunc("/v1/trash/{id}/restore", h.RestoreTask).Methods("POST")
+	mr.HandleFunc("/v1/trash/{id}", h.PurgeTask).Methods("DELETE")
+	mr.HandleFunc("/v1/undo", h.UndoOperations).Methods("POST", "OPTIONS")
+	mr.HandleFunc("/v1/redo", h.RedoOperations).Methods("POST", "OPTIONS")
+	mr.HandleFunc("/v1/events", h.GetEvents).Methods("GET")
+	mr.HandleFunc("/v1/ws", h.GetWebSocket).Methods("GET")
+	// webhooks send task changes anywhere they point, so pages of other
+	// origins may neither register nor read them
+	wh := mr.PathPrefix("/v1/webhooks").Subrouter()
+	wh.Use(middleware.SameOriginMiddleware)
+	wh.HandleFunc("", h.GetAllWebhooks).Methods("GET", "OPTIONS")
+	wh.HandleFunc("", h.CreateWebhook).Methods("POST", "OPTIONS")
+	wh.HandleFunc("/{id}", h.GetWebhookByID).Methods("GET")
+	wh.HandleFunc("/{id}", h.UpdateWebhook).Methods("PUT", "PATCH")
+	wh.HandleFunc("/{id}", h.DeleteWebhook).Methods("DELETE")
+	wh.HandleFunc("/{id}/deliveries", h.GetWebhookDeliveries).Methods("GET")
+	wh.HandleFunc("/{id}/deliveries/{delivery_id}/redeliver", h.RedeliverWebhook).Methods("POST")
+	mr.HandleFunc("/v1/search", h.SearchTasks).Methods("GET", "OPTIONS")
+	mr.HandleFunc("/v1/tags", h.GetAllTags).Methods("GET", "OPTIONS")
+	mr.HandleFunc("/v1/tags", h.CreateTag).Methods("POST", "OPTIONS")
+	mr.HandleFunc("/v1/tags/{id}", h.GetTagByID).Methods("GET")
+	mr.HandleFunc("/v1/tags/{id}", h.UpdateTag).Methods("PUT", "PATCH")
+	mr.HandleFunc("/v1/tags/{id}", h.DeleteTag).Methods("DELETE")
+	mr.HandleFunc("/v1/projects", h.GetAllProjects).Methods("GET", "OPTIONS")
+	mr.HandleFunc("/v1/projects", h.CreateProject).Methods("POST", "OPTIONS")
+	mr.HandleFunc("/v1/projects/{id}", h.GetProjectByID).Methods("GET")
+	mr.HandleFunc("/v1/projects/{id}", h.UpdateProject).Methods("PUT", "PATCH")
+	mr.HandleFunc("/v1/projects/{id}", h.DeleteProject).Methods("DELETE")
+	mr.HandleFunc("/v1/projects/{id}/tasks", h.GetProjectTasks).Methods("GET")
 	mr.HandleFunc("/", handlers.Home)
 
 	logger.Info("router created")
+	// around the router rather than in it, so requests matching no route
+	// are logged as well
 	return &API{
-		router: mr,
+		router:   middleware.RequestIDMiddleware(middleware.AccessLogMiddleware(middleware.RecoveryMiddleware(mr))),
+		handlers: h,
+	}
+}
+
+// exposes the routes for embedding the API in another server or for tests
+func (api API) Handler() http.Handler {
+	return api.router
+}
+
+func (api API) StartServer(addr string) error {
+	ln, err := api.Listen(addr)
+	if err != nil {
+		return err
 	}
+	return api.Serve(context.Background(), ln)
 }
 
-func (api API) StartServer() error {
-	url_base := fmt.Sprintf("%s:%s", os.Getenv("SERVER_IP"), os.Getenv("SERVER_PORT"))
+// binds addr, once it returned connections are accepted (and wait for
+// Serve), so the API can be relied on from then on
+func (api API) Listen(addr string) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
 
 	logger.Info("router started, ready to accept requests")
-	logger.Info("router ip:port", url_base)
+	logger.Info("router ip:port", ln.Addr())
+	return ln, nil
+}
+
+// serves the API on ln until ctx is done, then stops accepting connections,
+// closes the event streams and waits for the requests in flight
+func (api API) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: api.router}
+	srv.RegisterOnShutdown(api.handlers.CloseStreams)
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
 
-	return http.ListenAndServe(url_base, api.router)
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("router shutting down, draining requests in flight")
+	drain, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(drain); err != nil {
+		srv.Close()
+		return fmt.Errorf("draining requests: %w", err)
+	}
+	logger.Info("router stopped")
+	return nil
 }
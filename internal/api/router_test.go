@@ -0,0 +1,576 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"queueit/internal/api"
+	"queueit/internal/db"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// every test runs against both stores, so the in-memory one keeps
+// behaving like SQLite as far as the API can tell
+var stores = []struct {
+	name string
+	open func(t *testing.T) store.Store
+}{
+	{"memory", func(t *testing.T) store.Store {
+		return store.NewMemoryStore()
+	}},
+	{"sqlite", func(t *testing.T) store.Store {
+		if err := db.InitDB(filepath.Join(t.TempDir(), "queueit.db")); err != nil {
+			t.Fatal(err)
+		}
+		di := db.GetDBInfo()
+		t.Cleanup(func() { di.Close() })
+		return store.NewSQLiteStore(di)
+	}},
+}
+
+func forEachStore(t *testing.T, test func(t *testing.T, c *client)) {
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			st := s.open(t)
+			test(t, &client{t: t, h: api.NewRouter(st, events.NewBus(st)).Handler()})
+		})
+	}
+}
+
+// sends requests straight to the router, the session header groups the
+// changes for undo
+type client struct {
+	t       *testing.T
+	h       http.Handler
+	session string
+}
+
+// a string body is sent as it is, anything else as JSON
+func (c *client) do(method, path string, body any, header ...string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	var r io.Reader
+	switch body := body.(type) {
+	case nil:
+	case string:
+		r = strings.NewReader(body)
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		r = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, r)
+	if c.session != "" {
+		req.Header.Set(models.HEADER_SESSION, c.session)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	c.h.ServeHTTP(rec, req)
+	return rec
+}
+
+// do, failing the test unless the response has the status, and decoding
+// its body into out when out isn't nil
+func (c *client) expect(status int, out any, method, path string, body any, header ...string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	rec := c.do(method, path, body, header...)
+	if rec.Code != status {
+		c.t.Fatalf("%s %s: status %d, want %d: %s", method, path, rec.Code, status, rec.Body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			c.t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body, err)
+		}
+	}
+	return rec
+}
+
+func (c *client) create(req models.CreateTaskRequest) int64 {
+	c.t.Helper()
+	var resp models.GenricTaskResponse
+	c.expect(http.StatusOK, &resp, "POST", "/v1/tasks", req)
+	return resp.TaskID
+}
+
+func (c *client) get(id int64) models.GetTasksResponse {
+	c.t.Helper()
+	var t models.GetTasksResponse
+	c.expect(http.StatusOK, &t, "GET", fmt.Sprintf("/v1/tasks/%d", id), nil)
+	return t
+}
+
+// every task of the list, which comes as a bare array without paging
+func (c *client) list(query string) []models.GetTasksResponse {
+	c.t.Helper()
+	var tasks []models.GetTasksResponse
+	c.expect(http.StatusOK, &tasks, "GET", "/v1/tasks"+query, nil)
+	return tasks
+}
+
+func titles(tasks []models.GetTasksResponse) string {
+	var out []string
+	for _, t := range tasks {
+		out = append(out, t.Title)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		id := c.create(models.CreateTaskRequest{Title: "buy milk", Priority: models.PRIORITY_HIGH, Tags: []string{"errands"}})
+
+		got := c.get(id)
+		if got.Title != "buy milk" || got.Status != models.STATUS_PENDING || got.ProjectID != models.PROJECT_INBOX {
+			t.Fatalf("created task = %+v", got)
+		}
+		if len(got.Tags) != 1 || got.Tags[0] != "errands" {
+			t.Errorf("tags = %q, want [errands]", got.Tags)
+		}
+
+		title, status := "buy oat milk", models.STATUS_DONE
+		c.expect(http.StatusOK, nil, "PATCH", fmt.Sprintf("/v1/tasks/%d", id), models.UpdateTaskRequest{Title: &title, Status: &status})
+		if got := c.get(id); got.Title != title || got.Status != status {
+			t.Errorf("updated task = %+v", got)
+		}
+
+		c.expect(http.StatusOK, nil, "DELETE", fmt.Sprintf("/v1/tasks/%d", id), nil)
+		c.expect(http.StatusNotFound, nil, "GET", fmt.Sprintf("/v1/tasks/%d", id), nil)
+
+		var trash models.TaskPage
+		c.expect(http.StatusOK, &trash, "GET", "/v1/trash", nil)
+		if len(trash.Items) != 1 || trash.Items[0].TaskID != int(id) {
+			t.Fatalf("trash = %+v, want task %d", trash.Items, id)
+		}
+		c.expect(http.StatusOK, nil, "POST", fmt.Sprintf("/v1/trash/%d/restore", id), nil)
+		c.get(id)
+	})
+}
+
+func TestInvalidRequests(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		id := c.create(models.CreateTaskRequest{Title: "a"})
+		missing := fmt.Sprintf("/v1/tasks/%d", id+100)
+		recurrence := "FREQ=WEEKLY"
+		bad := "no,commas"
+
+		tests := []struct {
+			name, method, path string
+			body               any
+			status             int
+			param              string // of the ErrorResponse, when it is one
+		}{
+			{"blank title", "POST", "/v1/tasks", models.CreateTaskRequest{}, http.StatusUnprocessableEntity, ""},
+			{"invalid JSON", "POST", "/v1/tasks", "{", http.StatusBadRequest, ""},
+			{"unknown parent", "POST", "/v1/tasks", models.CreateTaskRequest{Title: "b", ParentTaskID: ptr(id + 100)}, http.StatusUnprocessableEntity, ""},
+			{"recurrence without deadline", "POST", "/v1/tasks", models.CreateTaskRequest{Title: "b", Recurrence: recurrence}, http.StatusUnprocessableEntity, ""},
+			{"invalid tag", "PATCH", fmt.Sprintf("/v1/tasks/%d", id), models.UpdateTaskRequest{Tags: &[]string{bad}}, http.StatusUnprocessableEntity, ""},
+			{"missing task", "GET", missing, nil, http.StatusNotFound, ""},
+			{"update missing task", "PATCH", missing, models.UpdateTaskRequest{Title: ptr("x")}, http.StatusNotFound, ""},
+			{"delete missing task", "DELETE", missing, nil, http.StatusNotFound, ""},
+			{"invalid id", "GET", "/v1/tasks/abc", nil, http.StatusBadRequest, "id"},
+			{"invalid status filter", "GET", "/v1/tasks?status=9", nil, http.StatusBadRequest, "status"},
+			{"invalid limit", "GET", "/v1/tasks?limit=0", nil, http.StatusBadRequest, "limit"},
+			{"invalid sort", "GET", "/v1/tasks?sort=colour", nil, http.StatusBadRequest, "sort"},
+			{"invalid cursor", "GET", "/v1/tasks?cursor=nope", nil, http.StatusBadRequest, "cursor"},
+			{"unknown field", "GET", "/v1/tasks?fields=colour", nil, http.StatusBadRequest, "fields"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rec := c.do(tt.method, tt.path, tt.body)
+				if rec.Code != tt.status {
+					t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+				}
+				if tt.param == "" {
+					return
+				}
+				var resp models.ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Param != tt.param {
+					t.Errorf("body %s, want an error naming %q", rec.Body, tt.param)
+				}
+			})
+		}
+	})
+}
+
+func TestListFiltersAndPages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		for i, p := range []int{models.PRIORITY_LOW, models.PRIORITY_HIGH, models.PRIORITY_MEDIUM, models.PRIORITY_HIGH} {
+			c.create(models.CreateTaskRequest{Title: fmt.Sprintf("t%d", i), Priority: p, Tags: []string{fmt.Sprintf("tag%d", i%2)}})
+		}
+		wip := models.STATUS_WIP
+		c.expect(http.StatusOK, nil, "PATCH", "/v1/tasks/2", models.UpdateTaskRequest{Status: &wip})
+
+		tests := []struct {
+			query, want string
+		}{
+			{"", "t0,t1,t2,t3"},
+			{"?priority=1", "t1,t3"},
+			{"?status=2", "t1"},
+			{"?tag=tag1", "t1,t3"},
+			{"?tag=tag0,tag1&tag_mode=all", ""},
+			{"?sort=-priority,title", "t0,t2,t1,t3"},
+			{"?sort=priority,-task_id", "t3,t1,t2,t0"},
+		}
+		for _, tt := range tests {
+			if got := titles(c.list(tt.query)); got != tt.want {
+				t.Errorf("GET /v1/tasks%s = %q, want %q", tt.query, got, tt.want)
+			}
+		}
+
+		// walking the pages gives every task once, in order
+		var seen []models.GetTasksResponse
+		path := "/v1/tasks?limit=3&sort=-title"
+		for {
+			var page models.TaskPage
+			c.expect(http.StatusOK, &page, "GET", path, nil)
+			seen = append(seen, page.Items...)
+			if page.NextCursor == "" {
+				break
+			}
+			path = "/v1/tasks?limit=3&sort=-title&cursor=" + page.NextCursor
+		}
+		if got := titles(seen); got != "t3,t2,t1,t0" {
+			t.Errorf("paged titles = %q, want t3,t2,t1,t0", got)
+		}
+	})
+}
+
+// without limit and cursor the list is the bare array of every task it
+// was before pagination, well past the default page size
+func TestListWithoutPaging(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		// more than the store hands out at once as well
+		for from := 0; from < 600; from += 300 {
+			items := make([]models.BulkTaskItem, 300)
+			for i := range items {
+				raw, _ := json.Marshal(models.CreateTaskRequest{Title: fmt.Sprintf("t%03d", from+i)})
+				items[i] = models.BulkTaskItem{Op: models.BULK_CREATE, Task: raw}
+			}
+			c.expect(http.StatusOK, nil, "POST", "/v1/tasks/bulk", models.BulkTasksRequest{Items: items})
+		}
+
+		tasks := c.list("?sort=-title&fields=title")
+		if len(tasks) != 600 || tasks[0].Title != "t599" || tasks[599].Title != "t000" {
+			t.Errorf("got %d tasks from %q to %q, want 600 from t599 to t000", len(tasks), tasks[0].Title, tasks[len(tasks)-1].Title)
+		}
+		if got := c.do("GET", "/v1/projects/1/tasks", nil).Body.String(); !strings.HasPrefix(got, "[") {
+			t.Errorf("project tasks = %.40s..., want an array", got)
+		}
+
+		var page models.TaskPage
+		c.expect(http.StatusOK, &page, "GET", "/v1/tasks?limit=200", nil)
+		if len(page.Items) != 200 || page.NextCursor == "" {
+			t.Errorf("limit=200 gave %d tasks and cursor %q, want a page of 200 and a cursor", len(page.Items), page.NextCursor)
+		}
+	})
+}
+
+func TestSubtasks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		parent := c.create(models.CreateTaskRequest{Title: "parent"})
+		child := c.create(models.CreateTaskRequest{Title: "child", ParentTaskID: &parent})
+		grandchild := c.create(models.CreateTaskRequest{Title: "grandchild", ParentTaskID: &child})
+
+		var tree models.GetTasksResponse
+		c.expect(http.StatusOK, &tree, "GET", fmt.Sprintf("/v1/tasks/%d?expand=children", parent), nil)
+		if len(tree.Children) != 1 || len(tree.Children[0].Children) != 1 || tree.Children[0].Children[0].TaskID != int(grandchild) {
+			t.Fatalf("subtree of %d = %+v", parent, tree.Children)
+		}
+
+		// a task can't move under itself or its descendants
+		for _, to := range []int64{parent, grandchild} {
+			c.expect(http.StatusConflict, nil, "PUT", fmt.Sprintf("/v1/tasks/%d/parent", parent), models.MoveTaskRequest{ParentTaskID: &to})
+		}
+
+		c.expect(http.StatusOK, nil, "PUT", fmt.Sprintf("/v1/tasks/%d/parent", grandchild), models.MoveTaskRequest{ParentTaskID: &parent})
+		if got := c.get(grandchild).ParentTaskID; got == nil || *got != int(parent) {
+			t.Errorf("parent after the move = %v, want %d", got, parent)
+		}
+
+		// deleting the parent takes the subtree with it
+		c.expect(http.StatusOK, nil, "DELETE", fmt.Sprintf("/v1/tasks/%d", parent), nil)
+		if got := c.list(""); len(got) != 0 {
+			t.Errorf("tasks left after deleting the parent: %q", titles(got))
+		}
+	})
+}
+
+// two tasks moved under each other at once, only one of the moves may
+// go through or they end up each other's parent
+func TestConcurrentMoves(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		for range 20 {
+			a := c.create(models.CreateTaskRequest{Title: "a"})
+			b := c.create(models.CreateTaskRequest{Title: "b"})
+
+			codes := make(chan int, 2)
+			var wg sync.WaitGroup
+			for _, m := range [][2]int64{{a, b}, {b, a}} {
+				wg.Go(func() {
+					codes <- c.do("PUT", fmt.Sprintf("/v1/tasks/%d/parent", m[0]), models.MoveTaskRequest{ParentTaskID: &m[1]}).Code
+				})
+			}
+			wg.Wait()
+			close(codes)
+
+			moved := 0
+			for code := range codes {
+				if code == http.StatusOK {
+					moved++
+				} else if code != http.StatusConflict {
+					t.Fatalf("move: status %d, want 200 or 409", code)
+				}
+			}
+			if moved != 1 {
+				t.Fatalf("%d of the moves of %d and %d went through, want 1", moved, a, b)
+			}
+		}
+	})
+}
+
+func TestIfMatch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		id := c.create(models.CreateTaskRequest{Title: "a"})
+		path := fmt.Sprintf("/v1/tasks/%d", id)
+
+		etag := c.expect(http.StatusOK, nil, "GET", path, nil).Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("GET returned no ETag")
+		}
+		c.expect(http.StatusNotModified, nil, "GET", path, nil, "If-None-Match", etag)
+
+		rec := c.expect(http.StatusOK, nil, "PATCH", path, models.UpdateTaskRequest{Title: ptr("b")}, "If-Match", etag)
+		current := rec.Header().Get("ETag")
+		if current == "" || current == etag {
+			t.Fatalf("ETag after the update = %q, want a new one", current)
+		}
+
+		// the stale ETag is refused, with the current one to retry with
+		rec = c.expect(http.StatusPreconditionFailed, nil, "PATCH", path, models.UpdateTaskRequest{Title: ptr("c")}, "If-Match", etag)
+		if got := rec.Header().Get("ETag"); got != current {
+			t.Errorf("ETag of the 412 = %q, want %q", got, current)
+		}
+		c.expect(http.StatusPreconditionFailed, nil, "DELETE", path, nil, "If-Match", etag)
+		if got := c.get(id).Title; got != "b" {
+			t.Errorf("title = %q, want b", got)
+		}
+		c.expect(http.StatusOK, nil, "DELETE", path, nil, "If-Match", current)
+	})
+}
+
+func TestUndoRedo(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		c.session = "test"
+		id := c.create(models.CreateTaskRequest{Title: "a"})
+		path := fmt.Sprintf("/v1/tasks/%d", id)
+		c.expect(http.StatusOK, nil, "PATCH", path, models.UpdateTaskRequest{Title: ptr("b")})
+
+		c.expect(http.StatusOK, nil, "POST", "/v1/undo", nil)
+		if got := c.get(id).Title; got != "a" {
+			t.Errorf("title after undo = %q, want a", got)
+		}
+		c.expect(http.StatusOK, nil, "POST", "/v1/undo", nil)
+		c.expect(http.StatusNotFound, nil, "GET", path, nil)
+		c.expect(http.StatusConflict, nil, "POST", "/v1/undo", nil)
+
+		c.expect(http.StatusOK, nil, "POST", "/v1/redo?steps=2", nil)
+		if got := c.get(id).Title; got != "b" {
+			t.Errorf("title after redo = %q, want b", got)
+		}
+
+		// another session has nothing to undo
+		c.session = "other"
+		c.expect(http.StatusConflict, nil, "POST", "/v1/undo", nil)
+		c.session = ""
+		c.expect(http.StatusBadRequest, nil, "POST", "/v1/undo", nil)
+	})
+}
+
+// a bulk undo hitting a conflict on one task leaves every task as it was
+func TestUndoBulkConflict(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		a := c.create(models.CreateTaskRequest{Title: "a"})
+		b := c.create(models.CreateTaskRequest{Title: "b"})
+		raw := func(v any) json.RawMessage {
+			out, _ := json.Marshal(v)
+			return out
+		}
+
+		c.session = "test"
+		c.expect(http.StatusOK, nil, "POST", "/v1/tasks/bulk", models.BulkTasksRequest{Items: []models.BulkTaskItem{
+			{Op: models.BULK_UPDATE, TaskID: a, Task: raw(models.UpdateTaskRequest{Title: ptr("a2")})},
+			{Op: models.BULK_UPDATE, TaskID: b, Task: raw(models.UpdateTaskRequest{Title: ptr("b2")})},
+		}})
+		c.session = "other"
+		c.expect(http.StatusOK, nil, "PATCH", fmt.Sprintf("/v1/tasks/%d", b), models.UpdateTaskRequest{Title: ptr("b3")})
+
+		c.session = "test"
+		c.expect(http.StatusConflict, nil, "POST", "/v1/undo", nil)
+		if got := titles(c.list("?sort=title")); got != "a2,b3" {
+			t.Errorf("tasks after the conflicting undo = %q, want a2,b3", got)
+		}
+
+		// once b is back the way the bulk request left it, the undo goes through
+		c.session = "other"
+		c.expect(http.StatusOK, nil, "PATCH", fmt.Sprintf("/v1/tasks/%d", b), models.UpdateTaskRequest{Title: ptr("b2")})
+		c.session = "test"
+		c.expect(http.StatusOK, nil, "POST", "/v1/undo", nil)
+		if got := titles(c.list("?sort=title")); got != "a,b" {
+			t.Errorf("tasks after the undo = %q, want a,b", got)
+		}
+	})
+}
+
+func TestBulk(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		a := c.create(models.CreateTaskRequest{Title: "a"})
+		raw := func(v any) json.RawMessage {
+			b, _ := json.Marshal(v)
+			return b
+		}
+
+		// the atomic request fails on its last item and changes nothing
+		var resp models.BulkTasksResponse
+		c.expect(http.StatusNotFound, &resp, "POST", "/v1/tasks/bulk", models.BulkTasksRequest{Items: []models.BulkTaskItem{
+			{Op: models.BULK_CREATE, Task: raw(models.CreateTaskRequest{Title: "b"})},
+			{Op: models.BULK_DELETE, TaskID: a + 100},
+		}})
+		if resp.Succeeded != 0 || resp.Failed != 2 {
+			t.Errorf("atomic: %d succeeded and %d failed, want 0 and 2", resp.Succeeded, resp.Failed)
+		}
+		if got := titles(c.list("")); got != "a" {
+			t.Errorf("tasks after the failed atomic request = %q, want a", got)
+		}
+
+		c.expect(http.StatusOK, &resp, "POST", "/v1/tasks/bulk", models.BulkTasksRequest{Mode: models.BULK_BEST_EFFORT, Items: []models.BulkTaskItem{
+			{Op: models.BULK_CREATE, Task: raw(models.CreateTaskRequest{Title: "b"})},
+			{Op: models.BULK_UPDATE, TaskID: a, Task: raw(models.UpdateTaskRequest{Title: ptr("a2")})},
+			{Op: models.BULK_DELETE, TaskID: a + 100},
+		}})
+		if resp.Succeeded != 2 || resp.Failed != 1 {
+			t.Errorf("best effort: %d succeeded and %d failed, want 2 and 1", resp.Succeeded, resp.Failed)
+		}
+		if got := titles(c.list("?sort=title")); got != "a2,b" {
+			t.Errorf("tasks after the best effort request = %q, want a2,b", got)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		c.create(models.CreateTaskRequest{Title: "<b>milk</b> & bread", Description: "oat milk, not <i>cow</i> milk"})
+		c.create(models.CreateTaskRequest{Title: "walk the dog"})
+
+		var page models.SearchPage
+		c.expect(http.StatusOK, &page, "GET", "/v1/search?q=milk", nil)
+		if len(page.Items) != 1 {
+			t.Fatalf("search for milk found %d tasks, want 1", len(page.Items))
+		}
+		res := page.Items[0]
+		if want := "&lt;b&gt;<mark>milk</mark>&lt;/b&gt; &amp; bread"; res.Highlight != want {
+			t.Errorf("highlight = %q, want %q", res.Highlight, want)
+		}
+		if want := "oat <mark>milk</mark>, not &lt;i&gt;cow&lt;/i&gt; <mark>milk</mark>"; res.Snippet != want {
+			t.Errorf("snippet = %q, want %q", res.Snippet, want)
+		}
+
+		c.expect(http.StatusOK, &page, "GET", "/v1/search?q=do*", nil)
+		if len(page.Items) != 1 || page.Items[0].Title != "walk the dog" {
+			t.Errorf("search for do* = %+v, want the dog", page.Items)
+		}
+		c.expect(http.StatusBadRequest, nil, "GET", "/v1/search", nil)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		rec := c.do("GET", "/v1/tasks", nil, "X-Request-ID", "abc-123")
+		if got := rec.Header().Get("X-Request-ID"); got != "abc-123" {
+			t.Errorf("X-Request-ID = %q, want the one sent", got)
+		}
+		rec = c.do("GET", "/v1/tasks", nil, "X-Request-ID", "bad id\n")
+		if got := rec.Header().Get("X-Request-ID"); got == "" || got == "bad id\n" {
+			t.Errorf("X-Request-ID = %q, want a generated one", got)
+		}
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		srv := httptest.NewServer(c.h)
+		defer srv.Close()
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/ws"
+
+		tests := []struct {
+			name, origin string
+			ok           bool
+		}{
+			{"no origin", "", true},
+			{"same host", srv.URL, true},
+			{"other host", "http://example.com", false},
+			{"other port", "http://127.0.0.1:1", false},
+		}
+		for _, tt := range tests {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+			if err == nil {
+				conn.Close()
+			}
+			switch {
+			case tt.ok && err != nil:
+				t.Errorf("%s: dial failed: %v", tt.name, err)
+			case !tt.ok && err == nil:
+				t.Errorf("%s: the handshake succeeded", tt.name)
+			case !tt.ok && resp.StatusCode != http.StatusForbidden:
+				t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, http.StatusForbidden)
+			}
+		}
+	})
+}
+
+// a page of another origin can neither register a webhook nor read them
+func TestWebhooksSameOrigin(t *testing.T) {
+	forEachStore(t, func(t *testing.T, c *client) {
+		hook := models.CreateWebhookRequest{URL: "http://127.0.0.1:1/hook", Secret: "s3cret"}
+
+		c.expect(http.StatusForbidden, nil, "POST", "/v1/webhooks", hook, "Origin", "http://evil.example")
+		c.expect(http.StatusForbidden, nil, "GET", "/v1/webhooks", nil, "Origin", "http://evil.example")
+		c.expect(http.StatusForbidden, nil, "DELETE", "/v1/webhooks/1", nil, "Origin", "http://evil.example")
+
+		// httptest requests go to example.com
+		c.expect(http.StatusOK, nil, "POST", "/v1/webhooks", hook, "Origin", "http://example.com")
+		var hooks []models.Webhook
+		c.expect(http.StatusOK, &hooks, "GET", "/v1/webhooks", nil)
+		if len(hooks) != 1 {
+			t.Errorf("webhooks = %+v, want only the same-origin one", hooks)
+		}
+	})
+}
@@ -1,7 +1,403 @@
+// the settings of queueit, each of them is taken from the first of these
+// that has it:
+//
+//  1. a command-line flag, e.g. --port 18772
+//  2. an environment variable, e.g. SERVER_PORT=18772, a .env file in the
+//     working directory fills in the ones that aren't set
+//  3. the config file, config.yaml in the app data dir (or the file given
+//     by --config or QUEUEIT_CONFIG), e.g. "server: {port: 18772}"
+//  4. the default
+//
+// every value is checked while loading, so a bad one fails at startup
 package config
 
-import "github.com/joho/godotenv"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"queueit/internal/helper"
+	"strconv"
+	"strings"
+	"time"
 
-func LoadConfig() error {
-	return godotenv.Load()
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CONFIG_FILE_NAME     = "config.yaml"
+	SQLLITE_DB_FILE_NAME = "queueit.db"
+
+	LOG_FORMAT_TEXT = "text"
+	LOG_FORMAT_JSON = "json"
+
+	// how long deleted tasks are kept when trash.retention isn't set
+	DEFAULT_TRASH_RETENTION = 30 * 24 * time.Hour
+)
+
+type Config struct {
+	Server   ServerConfig
+	Database DatabaseConfig
+	Log      LogConfig
+	Trash    TrashConfig
+	Features FeaturesConfig
+
+	// the config file that was read, blank when there was none
+	File string
+}
+
+type ServerConfig struct {
+	IP   string // an IP address or localhost, 0.0.0.0 listens on every interface
+	Port int
+}
+
+type DatabaseConfig struct {
+	Path string // the SQLite file, created when missing
+}
+
+type LogConfig struct {
+	Level  slog.Level
+	Format string // text or json
+
+	// also log to this file, blank for none; a relative path is taken from
+	// the app data dir
+	File      string
+	MaxSizeMB int // a file bigger than this is rotated
+	MaxFiles  int // rotated files kept
+}
+
+type TrashConfig struct {
+	Retention time.Duration // 0 keeps deleted tasks until they are purged by hand
+}
+
+// parts of the app that can be turned off
+type FeaturesConfig struct {
+	Webhooks  bool // deliver task changes to the registered webhooks
+	Reminders bool // fire task reminders
+}
+
+// one setting and the names it goes by in each source
+type setting struct {
+	key    string // in the config file, dotted
+	env    string
+	flag   string
+	usage  string
+	isBool bool
+	set    func(c *Config, raw string) error
+}
+
+var settings = []setting{
+	{key: "server.ip", env: "SERVER_IP", flag: "ip", usage: "`address` to listen on, 0.0.0.0 for every interface", set: func(c *Config, raw string) error {
+		if raw != "localhost" && net.ParseIP(raw) == nil {
+			return fmt.Errorf("invalid address %q, expected an IP address or localhost", raw)
+		}
+		c.Server.IP = raw
+		return nil
+	}},
+	{key: "server.port", env: "SERVER_PORT", flag: "port", usage: "`port` to listen on", set: func(c *Config, raw string) error {
+		port, err := strconv.Atoi(raw)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q, expected a number from 1 to 65535", raw)
+		}
+		c.Server.Port = port
+		return nil
+	}},
+	{key: "database.path", env: "DB_PATH", flag: "db", usage: "SQLite database `file`", set: func(c *Config, raw string) error {
+		path, err := expandHome(raw)
+		if err != nil {
+			return err
+		}
+		c.Database.Path = path
+		return nil
+	}},
+	{key: "log.level", env: "LOG_LEVEL", flag: "log-level", usage: "`level` of the lines logged: debug, info, warn or error", set: func(c *Config, raw string) error {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(raw)); err != nil || strings.ContainsAny(raw, "+-") {
+			return fmt.Errorf("invalid log level %q, expected debug, info, warn or error", raw)
+		}
+		c.Log.Level = level
+		return nil
+	}},
+	{key: "log.format", env: "LOG_FORMAT", flag: "log-format", usage: "`format` of the log lines: text or json", set: func(c *Config, raw string) error {
+		if raw != LOG_FORMAT_TEXT && raw != LOG_FORMAT_JSON {
+			return fmt.Errorf("invalid log format %q, expected text or json", raw)
+		}
+		c.Log.Format = raw
+		return nil
+	}},
+	{key: "log.file", env: "LOG_FILE", flag: "log-file", usage: "also log to this `file`, relative to the app data dir, blank for none", set: func(c *Config, raw string) error {
+		if raw == "" {
+			c.Log.File = ""
+			return nil
+		}
+		path, err := expandHome(raw)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(path) {
+			dir, err := helper.GetAppDataDir()
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(dir, path)
+		}
+		c.Log.File = path
+		return nil
+	}},
+	{key: "log.max_size_mb", env: "LOG_MAX_SIZE_MB", flag: "log-max-size-mb", usage: "`megabytes` a log file grows to before it is rotated", set: func(c *Config, raw string) error {
+		return parseInt(raw, 1, &c.Log.MaxSizeMB)
+	}},
+	{key: "log.max_files", env: "LOG_MAX_FILES", flag: "log-max-files", usage: "`number` of rotated log files kept", set: func(c *Config, raw string) error {
+		return parseInt(raw, 0, &c.Log.MaxFiles)
+	}},
+	{key: "trash.retention", env: "TRASH_RETENTION", flag: "trash-retention", usage: "how long deleted tasks are kept, a `duration` like 720h or 30d, 0 for ever", set: func(c *Config, raw string) error {
+		retention, err := parseRetention(raw)
+		c.Trash.Retention = retention
+		return err
+	}},
+	{key: "features.webhooks", env: "FEATURE_WEBHOOKS", flag: "webhooks", usage: "deliver task changes to webhooks", isBool: true, set: func(c *Config, raw string) error {
+		return parseBool(raw, &c.Features.Webhooks)
+	}},
+	{key: "features.reminders", env: "FEATURE_REMINDERS", flag: "reminders", usage: "fire task reminders", isBool: true, set: func(c *Config, raw string) error {
+		return parseBool(raw, &c.Features.Reminders)
+	}},
+}
+
+// the settings without any file, environment or flags
+func Default() (*Config, error) {
+	dir, err := helper.GetAppDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Config{
+		Server:   ServerConfig{IP: "127.0.0.1", Port: 18772},
+		Database: DatabaseConfig{Path: filepath.Join(dir, SQLLITE_DB_FILE_NAME)},
+		Log:      LogConfig{Level: slog.LevelInfo, Format: LOG_FORMAT_TEXT, MaxSizeMB: 10, MaxFiles: 5},
+		Trash:    TrashConfig{Retention: DEFAULT_TRASH_RETENTION},
+		Features: FeaturesConfig{Webhooks: true, Reminders: true},
+	}, nil
+}
+
+// registers --config and a flag per setting on fs, Load picks up the ones
+// given once fs is parsed
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(&flagValue{}, "config", "config `file` (default "+CONFIG_FILE_NAME+" in the app data dir)")
+	for _, s := range settings {
+		fs.Var(&flagValue{isBool: s.isBool}, s.flag, fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+}
+
+// loads the settings from every source, fs being the parsed flags of
+// RegisterFlags (nil for none); every invalid value is reported
+func Load(fs *flag.FlagSet) (*Config, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+
+	// a .env is optional, what it has doesn't override the environment
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+
+	flags := map[string]string{}
+	if fs != nil {
+		fs.Visit(func(f *flag.Flag) {
+			if _, ok := f.Value.(*flagValue); ok {
+				flags[f.Name] = f.Value.String()
+			}
+		})
+	}
+
+	// an explicit file has to be there, the default one doesn't
+	file, explicit := flags["config"], true
+	if file == "" {
+		file = os.Getenv("QUEUEIT_CONFIG")
+	}
+	if file == "" {
+		dir, err := helper.GetAppDataDir()
+		if err != nil {
+			return nil, err
+		}
+		file, explicit = filepath.Join(dir, CONFIG_FILE_NAME), false
+	}
+
+	fromFile, err := readFile(file)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+	case err != nil:
+		return nil, err
+	default:
+		c.File = file
+	}
+
+	var errs []error
+	for _, s := range settings {
+		var raw, source string
+		if v, ok := flags[s.flag]; ok {
+			raw, source = v, "flag --"+s.flag
+		} else if v, ok := os.LookupEnv(s.env); ok {
+			raw, source = v, "env "+s.env
+		} else if v, ok := fromFile[s.key]; ok {
+			raw, source = v, file+": "+s.key
+		} else {
+			continue
+		}
+		if err := s.set(c, strings.TrimSpace(raw)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return c, nil
+}
+
+// the address the server listens on
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Server.IP, strconv.Itoa(c.Server.Port))
+}
+
+// the base URL of the server for clients on this machine, which reach a
+// server listening on every interface through loopback
+func (c *Config) URL() string {
+	host := c.Server.IP
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(c.Server.Port))
+}
+
+// reads the config file into its settings keyed by their dotted names,
+// any key that isn't a setting is an error
+func readFile(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	if err := flatten("", doc, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var errs []error
+	for key := range values {
+		if !isSetting(key) {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+		}
+	}
+	return values, errors.Join(errs...)
+}
+
+func flatten(prefix string, node any, into map[string]string) error {
+	switch v := node.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if err := flatten(prefix+k+".", child, into); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		for k, child := range v {
+			if err := flatten(prefix+fmt.Sprint(k)+".", child, into); err != nil {
+				return err
+			}
+		}
+	case []any:
+		return fmt.Errorf("%s: expected a single value, not a list", strings.TrimSuffix(prefix, "."))
+	case nil:
+	default:
+		into[strings.TrimSuffix(prefix, ".")] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func isSetting(key string) bool {
+	for _, s := range settings {
+		if s.key == key {
+			return true
+		}
+	}
+	return false
 }
+
+func parseInt(raw string, least int, dst *int) error {
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < least {
+		return fmt.Errorf("invalid value %q, expected a number of at least %d", raw, least)
+	}
+	*dst = n
+	return nil
+}
+
+func parseBool(raw string, dst *bool) error {
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fmt.Errorf("invalid value %q, expected true or false", raw)
+	}
+	*dst = b
+	return nil
+}
+
+// a Go duration like 720h or a number of days like 30d, blank means the
+// default and 0 turns the purge off
+func parseRetention(raw string) (time.Duration, error) {
+	if raw == "" {
+		return DEFAULT_TRASH_RETENTION, nil
+	}
+	if raw == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid trash retention %q, expected a number of days like 30d", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid trash retention %q, expected a duration like 720h or a number of days like 30d", raw)
+	}
+	return d, nil
+}
+
+// ~/ at the start of a path is the home directory
+func expandHome(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("expected a file path")
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, rest), nil
+	}
+	return path, nil
+}
+
+// holds a flag as given, Load parses it along with the other sources
+type flagValue struct {
+	raw    string
+	isBool bool
+}
+
+func (v *flagValue) String() string { return v.raw }
+
+func (v *flagValue) Set(raw string) error {
+	v.raw = raw
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// a home and working directory of the test's own and none of the settings
+// in the environment, so only what the test sets up is read
+func isolate(t *testing.T) (home string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", "")
+	t.Chdir(t.TempDir())
+	for _, name := range append([]string{"QUEUEIT_CONFIG"}, envNames()...) {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	return home
+}
+
+func envNames() []string {
+	var names []string
+	for _, s := range settings {
+		names = append(names, s.env)
+	}
+	return names
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// the flags of RegisterFlags parsed from args
+func parseFlags(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+	fs := flag.NewFlagSet("queueit", flag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestDefaults(t *testing.T) {
+	home := isolate(t)
+
+	c, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case c.Addr() != "127.0.0.1:18772":
+		t.Errorf("Addr() = %s, want 127.0.0.1:18772", c.Addr())
+	case c.Database.Path != filepath.Join(home, ".queueit", SQLLITE_DB_FILE_NAME):
+		t.Errorf("database path = %s, want it in the app data dir", c.Database.Path)
+	case c.Log.Level != slog.LevelInfo || c.Log.Format != LOG_FORMAT_TEXT || c.Log.File != "":
+		t.Errorf("log = %+v, want info in text and no file", c.Log)
+	case c.Trash.Retention != DEFAULT_TRASH_RETENTION:
+		t.Errorf("trash retention = %v, want %v", c.Trash.Retention, DEFAULT_TRASH_RETENTION)
+	case !c.Features.Webhooks || !c.Features.Reminders:
+		t.Errorf("features = %+v, want all on", c.Features)
+	case c.File != "":
+		t.Errorf("config file = %q, want none", c.File)
+	}
+}
+
+// a flag beats the environment, which beats .env, which beats the file
+func TestPrecedence(t *testing.T) {
+	home := isolate(t)
+	file := filepath.Join(home, ".queueit", CONFIG_FILE_NAME)
+	writeFile(t, file, `
+server:
+  ip: 0.0.0.0
+  port: 1001
+log:
+  level: warn
+  format: json
+trash:
+  retention: 1d
+`)
+	writeFile(t, ".env", "SERVER_PORT=1002\nLOG_LEVEL=error\nTRASH_RETENTION=7d\n")
+	t.Setenv("SERVER_PORT", "1003")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	c, err := Load(parseFlags(t, "--port", "1004", "--reminders=false"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.File != file {
+		t.Errorf("config file = %q, want %q", c.File, file)
+	}
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"the flag over all", c.Server.Port, 1004},
+		{"a bool flag", c.Features.Reminders, false},
+		{"the environment over .env and the file", c.Log.Level, slog.LevelDebug},
+		{".env over the file", c.Trash.Retention, 7 * 24 * time.Hour},
+		{"the file over the default", c.Server.IP, "0.0.0.0"},
+		{"the file alone", c.Log.Format, LOG_FORMAT_JSON},
+		{"the default", c.Features.Webhooks, true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	// the server is reached through loopback when it listens everywhere
+	if c.URL() != "http://127.0.0.1:1004" {
+		t.Errorf("URL() = %s, want http://127.0.0.1:1004", c.URL())
+	}
+}
+
+func TestConfigFileChoice(t *testing.T) {
+	home := isolate(t)
+	writeFile(t, filepath.Join(home, ".queueit", CONFIG_FILE_NAME), "server: {port: 1001}")
+	other := filepath.Join(home, "other.yaml")
+	writeFile(t, other, "server: {port: 1002}")
+	flagged := filepath.Join(home, "flagged.yaml")
+	writeFile(t, flagged, "server: {port: 1003}")
+
+	t.Setenv("QUEUEIT_CONFIG", other)
+	if c, err := Load(nil); err != nil || c.Server.Port != 1002 {
+		t.Errorf("with QUEUEIT_CONFIG: %+v %v, want port 1002", c, err)
+	}
+	if c, err := Load(parseFlags(t, "--config", flagged)); err != nil || c.Server.Port != 1003 {
+		t.Errorf("with --config: %+v %v, want port 1003", c, err)
+	}
+	// a file asked for has to be there
+	if _, err := Load(parseFlags(t, "--config", filepath.Join(home, "missing.yaml"))); err == nil {
+		t.Error("Load succeeded without the file given by --config")
+	}
+}
+
+// every bad value is reported along with where it came from
+func TestInvalid(t *testing.T) {
+	home := isolate(t)
+	writeFile(t, filepath.Join(home, ".queueit", CONFIG_FILE_NAME), "log: {format: xml}")
+	t.Setenv("SERVER_PORT", "99999")
+
+	_, err := Load(parseFlags(t, "--ip", "example.com", "--trash-retention", "soon"))
+	if err == nil {
+		t.Fatal("Load succeeded with invalid settings")
+	}
+	for _, want := range []string{"flag --ip", "env SERVER_PORT", CONFIG_FILE_NAME + ": log.format", "flag --trash-retention"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %s", err, want)
+		}
+	}
+
+	writeFile(t, filepath.Join(home, ".queueit", CONFIG_FILE_NAME), "server: {host: example.com}")
+	t.Setenv("SERVER_PORT", "")
+	os.Unsetenv("SERVER_PORT")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), `unknown setting "server.host"`) {
+		t.Errorf("Load = %v, want the unknown setting", err)
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	var logLevel setting
+	for _, s := range settings {
+		if s.key == "log.level" {
+			logLevel = s
+		}
+	}
+	tests := map[string]bool{"debug": true, "INFO": true, "warn": true, "error": true, "info+2": false, "loud": false}
+	for raw, valid := range tests {
+		var c Config
+		if err := logLevel.set(&c, raw); (err == nil) != valid {
+			t.Errorf("log level %q: error %v, want valid %v", raw, err, valid)
+		}
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Duration
+		err  bool
+	}{
+		{"", DEFAULT_TRASH_RETENTION, false},
+		{"0", 0, false},
+		{"720h", 720 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"-1h", 0, true},
+		{"-2d", 0, true},
+		{"1.5d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRetention(tt.raw)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseRetention(%q) = %v %v, want %v and error %v", tt.raw, got, err, tt.want, tt.err)
+		}
+	}
+}
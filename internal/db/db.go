@@ -4,54 +4,52 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
-	"queueit/internal/helper"
-
-	_ "embed"
 
 	_ "modernc.org/sqlite"
 )
 
-//go:embed migration/schema.sql
-var schema string
-
-const SQLLITE_DB_FILE_NAME = "queueit.db"
-
 var dbinfo *DBInfo
 
 type DBInfo struct {
 	conn   *sql.DB
 	dbfile string
+
+	// set on the DBInfo InTx hands out, everything runs in that transaction
+	tx    *Tx
+	depth int
 }
 
-func InitDB() error {
+// opens the database file at path, creating it (and its directory) when
+// missing, and brings it up to date
+func InitDB(path string) error {
 
 	// pre-checks for db file
-	loc, err := helper.GetAppDataDir()
-	if err != nil {
-		return err
-	}
-
-	if _, err = os.Stat(loc); os.IsNotExist(err) {
+	loc := filepath.Dir(path)
+	if _, err := os.Stat(loc); os.IsNotExist(err) {
 		if err = os.MkdirAll(loc, 0755); err != nil {
 			return err
 		}
 	}
 
-	loc = filepath.Join(loc, SQLLITE_DB_FILE_NAME)
-	if _, err = os.Stat(loc); os.IsNotExist(err) {
+	loc = path
+	if _, err := os.Stat(loc); os.IsNotExist(err) {
 		if err = os.WriteFile(loc, []byte(""), 0755); err != nil {
 			return err
 		}
 	}
 
-	// init db connection
-	conn, err := sql.Open("sqlite", loc)
+	// init db connection, WAL lets readers (like the event stream) carry on
+	// while another connection or process writes, and transactions take the
+	// write lock up front so concurrent writers wait their turn instead of
+	// failing with SQLITE_BUSY
+	conn, err := sql.Open("sqlite", loc+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate")
 	if err != nil {
 		return err
 	}
 
-	// check and create sql-schema
-	if _, err := conn.Exec(string(schema)); err != nil {
+	// bring the sql-schema up to date
+	if err := migrate(conn); err != nil {
+		conn.Close()
 		return err
 	}
 
@@ -63,12 +61,35 @@ func InitDB() error {
 }
 
 func (di *DBInfo) E(query string, args ...any) (sql.Result, error) {
+	if di.tx != nil {
+		return di.tx.Exec(query, args...)
+	}
 	return di.conn.Exec(query, args...)
 }
 
 func (di *DBInfo) Q(query string, args ...any) (*sql.Rows, error) {
+	if di.tx != nil {
+		return di.tx.Query(query, args...)
+	}
 	return di.conn.Query(query, args...)
 }
 
+// starts a transaction, caller must Commit or Rollback
+func (di *DBInfo) Begin() (*Tx, error) {
+	if di.tx != nil {
+		return di.savepoint()
+	}
+	tx, err := di.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx}, nil
+}
+
+// closes the connection, for a shutting down server once nothing uses it
+func (di *DBInfo) Close() error {
+	return di.conn.Close()
+}
+
 func GetDBInfo() *DBInfo   { return dbinfo }
 func setDBInfo(di *DBInfo) { dbinfo = di }
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// numbered migrations, NNNN_description.sql, applied in order and never
+// edited once released: schema changes always go into a new file
+//
+//go:embed migration/*.sql
+var migrationFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// returned by InitDB when the database was migrated by a newer build,
+// the binary refuses to touch a schema it doesn't know about
+type ErrSchemaTooNew struct {
+	Current int
+	Latest  int
+}
+
+func (e ErrSchemaTooNew) Error() string {
+	return fmt.Sprintf("database schema version %d is newer than the latest version %d known to this build, upgrade queueit", e.Current, e.Latest)
+}
+
+const schemaMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)
+`
+
+// loads the embedded migrations sorted by version
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFS.ReadDir("migration")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	seen := map[int]string{}
+	for _, e := range entries {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %q: file name must look like NNNN_description.sql", e.Name())
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migration %q: version %d already used by %q", e.Name(), version, other)
+		}
+		seen[version] = e.Name()
+
+		body, err := migrationFS.ReadFile(path.Join("migration", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// latest schema version this build knows how to produce
+func LatestSchemaVersion() int {
+	migrations, err := loadMigrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// brings the schema up to the latest version, every migration runs in its
+// own transaction together with its schema_migrations bookkeeping row
+func migrate(conn *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Exec(schemaMigrationsTable); err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(conn)
+	if err != nil {
+		return err
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].version
+	}
+	if current > latest {
+		return ErrSchemaTooNew{Current: current, Latest: latest}
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(conn, m); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(conn *sql.DB, m migration) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.version, m.name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func schemaVersion(conn *sql.DB) (int, error) {
+	var version int
+	err := conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// schema version the connected database is currently at
+func (di *DBInfo) SchemaVersion() (int, error) {
+	return schemaVersion(di.conn)
+}
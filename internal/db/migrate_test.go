@@ -0,0 +1,241 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func openConn(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "queueit.db")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// applies the migrations up to and including version
+func migrateTo(t *testing.T, conn *sql.DB, version int) {
+	t.Helper()
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(schemaMigrationsTable); err != nil {
+		t.Fatal(err)
+	}
+	current, err := schemaVersion(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range migrations {
+		if m.version > current && m.version <= version {
+			if err := applyMigration(conn, m); err != nil {
+				t.Fatalf("migration %04d_%s: %v", m.version, m.name, err)
+			}
+		}
+	}
+}
+
+func exec(t *testing.T, conn *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := conn.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func TestMigrationFiles(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %d is %04d_%s, versions must count up from 1 without gaps", i+1, m.version, m.name)
+		}
+	}
+	if got := LatestSchemaVersion(); got != len(migrations) {
+		t.Errorf("LatestSchemaVersion() = %d, want %d", got, len(migrations))
+	}
+}
+
+// a database of the release before migrations, made by its schema.sql
+// (0001 now) and without schema_migrations, is brought up to date keeping
+// its rows
+func TestMigrateFromBaseline(t *testing.T) {
+	conn, path := openConn(t)
+	baseline, err := migrationFS.ReadFile("migration/0001_init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec(t, conn, string(baseline))
+
+	deadlines := []struct {
+		stored string
+		want   any // nil for NULL
+	}{
+		// how the driver printed a time.Time, with and without fractions
+		{"2026-10-18 10:39:06.5 +0530 IST", "2026-10-18T10:39:06+05:30"},
+		{"2026-10-18 10:39:06 -0700 -0700", "2026-10-18T10:39:06-07:00"},
+		{"2026-10-18 10:39:06 +0000 UTC", "2026-10-18T10:39:06+00:00"},
+		// RFC3339 as UpdateTask wrote it, and what nobody can read, stay
+		{"2026-10-18T10:39:06Z", "2026-10-18T10:39:06Z"},
+		{"someday", "someday"},
+		{"", nil},
+	}
+	for _, d := range deadlines {
+		var stored any = d.stored
+		if d.stored == "" {
+			stored = nil
+		}
+		exec(t, conn, `INSERT INTO tasksmaster (title, description, deadline_at) VALUES ('buy milk', 'oat', ?)`, stored)
+	}
+	exec(t, conn, `INSERT INTO tasksmaster (title, parent_task_id) VALUES ('child', 1)`)
+	exec(t, conn, `INSERT INTO task_children (parent_id, child_id) VALUES (1, 7)`)
+	conn.Close()
+
+	if err := InitDB(path); err != nil {
+		t.Fatal(err)
+	}
+	di := GetDBInfo()
+	defer di.Close()
+	conn = di.conn
+
+	if v, err := di.SchemaVersion(); err != nil || v != LatestSchemaVersion() {
+		t.Errorf("schema version = %d %v, want %d", v, err, LatestSchemaVersion())
+	}
+
+	for i, d := range deadlines {
+		var got sql.NullString
+		if err := conn.QueryRow(`SELECT CAST(deadline_at AS TEXT) FROM tasksmaster WHERE task_id = ?`, i+1).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case d.want == nil && got.Valid:
+			t.Errorf("deadline NULL became %q", got.String)
+		case d.want != nil && got.String != d.want:
+			t.Errorf("deadline %q became %q, want %q", d.stored, got.String, d.want)
+		}
+	}
+
+	// every old task lands in the Inbox, at version 1, out of the trash and
+	// in the search index
+	var inbox, versioned, live, children int
+	err = conn.QueryRow(`
+		SELECT
+			SUM(project_id = 1), SUM(version = 1), SUM(deleted_at IS NULL),
+			(SELECT COUNT(*) FROM task_children WHERE parent_id = 1 AND child_id = 7)
+		FROM tasksmaster
+	`).Scan(&inbox, &versioned, &live, &children)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inbox != 7 || versioned != 7 || live != 7 || children != 1 {
+		t.Errorf("of 7 tasks %d are in the Inbox, %d at version 1, %d live and %d child links kept", inbox, versioned, live, children)
+	}
+	var project string
+	if err := conn.QueryRow(`SELECT name FROM projects WHERE project_id = 1`).Scan(&project); err != nil || project != "Inbox" {
+		t.Errorf("project 1 = %q %v, want the Inbox", project, err)
+	}
+	var found int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM tasks_fts WHERE tasks_fts MATCH 'milk'`).Scan(&found); err != nil || found != 6 {
+		t.Errorf("search for milk found %d tasks %v, want 6", found, err)
+	}
+}
+
+// the version bump of 0013 replaces the touch trigger of 0004, so a change
+// made before and after it counts once
+func TestTaskVersionTrigger(t *testing.T) {
+	conn, _ := openConn(t)
+	migrateTo(t, conn, 12)
+	exec(t, conn, `INSERT INTO tasksmaster (title, updated_at) VALUES ('old', '2000-01-01 00:00:00')`)
+	migrateTo(t, conn, 13)
+
+	task := func() (version int64, updatedAt string) {
+		t.Helper()
+		if err := conn.QueryRow(`SELECT version, updated_at FROM tasksmaster WHERE task_id = 1`).Scan(&version, &updatedAt); err != nil {
+			t.Fatal(err)
+		}
+		return version, updatedAt
+	}
+	if v, _ := task(); v != 1 {
+		t.Errorf("existing task at version %d, want 1", v)
+	}
+
+	exec(t, conn, `UPDATE tasksmaster SET title = 'new' WHERE task_id = 1`)
+	if v, at := task(); v != 2 || at == "2000-01-01T00:00:00Z" {
+		t.Errorf("after an update: version %d updated %s, want 2 and now", v, at)
+	}
+
+	// undo puts back versions and times of its own
+	exec(t, conn, `UPDATE tasksmaster SET title = 'old', version = 7, updated_at = '2001-01-01 00:00:00' WHERE task_id = 1`)
+	if v, at := task(); v != 7 || at != "2001-01-01T00:00:00Z" {
+		t.Errorf("after putting back a version: version %d updated %s, want 7 and 2001", v, at)
+	}
+	exec(t, conn, `UPDATE tasksmaster SET updated_at = '2002-01-01 00:00:00' WHERE task_id = 1`)
+	if v, at := task(); v != 8 || at != "2002-01-01T00:00:00Z" {
+		t.Errorf("after setting the time: version %d updated %s, want 8 and 2002", v, at)
+	}
+}
+
+func TestMigrateTwice(t *testing.T) {
+	_, path := openConn(t)
+	for range 2 {
+		if err := InitDB(path); err != nil {
+			t.Fatal(err)
+		}
+		GetDBInfo().Close()
+	}
+
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var applied, distinct int
+	if err := conn.QueryRow(`SELECT COUNT(*), COUNT(DISTINCT version) FROM schema_migrations`).Scan(&applied, &distinct); err != nil {
+		t.Fatal(err)
+	}
+	if applied != LatestSchemaVersion() || distinct != applied {
+		t.Errorf("%d migrations recorded, %d distinct, want each of %d once", applied, distinct, LatestSchemaVersion())
+	}
+}
+
+func TestSchemaTooNew(t *testing.T) {
+	conn, path := openConn(t)
+	migrateTo(t, conn, LatestSchemaVersion())
+	exec(t, conn, `INSERT INTO schema_migrations (version, name) VALUES (?, 'future')`, LatestSchemaVersion()+1)
+	conn.Close()
+
+	var tooNew ErrSchemaTooNew
+	if err := InitDB(path); !errors.As(err, &tooNew) || tooNew.Current != LatestSchemaVersion()+1 {
+		t.Errorf("InitDB = %v, want ErrSchemaTooNew", err)
+	}
+}
+
+// a migration failing halfway leaves neither its changes nor its
+// bookkeeping row behind
+func TestFailedMigrationRollsBack(t *testing.T) {
+	conn, _ := openConn(t)
+	migrateTo(t, conn, 1)
+
+	broken := migration{version: 2, name: "broken", sql: `
+		CREATE TABLE half (id INTEGER);
+		INSERT INTO missing VALUES (1);
+	`}
+	if err := applyMigration(conn, broken); err == nil {
+		t.Fatal("the broken migration applied")
+	}
+
+	var tables int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half'`).Scan(&tables); err != nil || tables != 0 {
+		t.Errorf("the table of the broken migration is there (%v)", err)
+	}
+	if v, err := schemaVersion(conn); err != nil || v != 1 {
+		t.Errorf("schema version = %d %v, want 1", v, err)
+	}
+}
@@ -0,0 +1,17 @@
+-- named lists grouping tasks into separate queues
+CREATE TABLE IF NOT EXISTS projects (
+    project_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    color TEXT,                                    -- optional display color (#rrggbb)
+    default_priority INTEGER CHECK(default_priority BETWEEN 1 AND 3) DEFAULT 2, -- used when a task comes without one
+    archived INTEGER NOT NULL DEFAULT 0,           -- 0=active,1=archived
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- every task without an explicit project lands in the Inbox, it can't be deleted
+INSERT OR IGNORE INTO projects (project_id, name) VALUES (1, 'Inbox');
+
+-- existing tasks move to the Inbox through the column default
+ALTER TABLE tasksmaster ADD COLUMN project_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_tasksmaster_project ON tasksmaster(project_id);
@@ -0,0 +1,20 @@
+-- CreateTask used to store deadlines the way the driver prints a time.Time
+-- ("2026-10-18 10:39:06.5 +0530 IST") which date functions can't read,
+-- rewrite them as RFC3339 ("2026-10-18T10:39:06+05:30") like UpdateTask does
+UPDATE tasksmaster
+SET deadline_at =
+    substr(deadline_at, 1, 10) || 'T' || substr(deadline_at, 12, 8) ||
+    substr(substr(deadline_at, 20), instr(substr(deadline_at, 20), ' ') + 1, 3) || ':' ||
+    substr(substr(deadline_at, 20), instr(substr(deadline_at, 20), ' ') + 4, 2)
+WHERE julianday(deadline_at) IS NULL
+  AND deadline_at GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9] [0-9][0-9]:[0-9][0-9]:[0-9][0-9]*[+-][0-9][0-9][0-9][0-9]*';
+
+-- updated_at was never maintained, keep it current on every change to a task
+CREATE TRIGGER IF NOT EXISTS tasksmaster_touch
+AFTER UPDATE ON tasksmaster
+FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+    UPDATE tasksmaster SET updated_at = CURRENT_TIMESTAMP WHERE task_id = NEW.task_id;
+END;
+
+CREATE INDEX IF NOT EXISTS idx_tasksmaster_deadline ON tasksmaster(deadline_at);
@@ -0,0 +1,26 @@
+-- full-text index over task titles and descriptions, an external content
+-- table reading from tasksmaster so the text isn't stored twice
+CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(
+    title,
+    description,
+    content='tasksmaster',
+    content_rowid='task_id',
+    tokenize='unicode61 remove_diacritics 2'   -- "cafe" finds "café"
+);
+
+-- the triggers keep the index in step with every write to tasksmaster
+CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasksmaster BEGIN
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (NEW.task_id, NEW.title, NEW.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasksmaster BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', OLD.task_id, OLD.title, OLD.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, description ON tasksmaster BEGIN
+    INSERT INTO tasks_fts (tasks_fts, rowid, title, description) VALUES ('delete', OLD.task_id, OLD.title, OLD.description);
+    INSERT INTO tasks_fts (rowid, title, description) VALUES (NEW.task_id, NEW.title, NEW.description);
+END;
+
+-- index the tasks that exist already
+INSERT INTO tasks_fts (tasks_fts) VALUES ('rebuild');
@@ -0,0 +1,4 @@
+-- repeating tasks: the series lives on its latest open occurrence, which
+-- carries the rule, completing it creates the next one
+ALTER TABLE tasksmaster ADD COLUMN rrule TEXT;            -- RFC 5545 RRULE, NULL for one-off tasks
+ALTER TABLE tasksmaster ADD COLUMN rrule_start DATETIME;  -- first deadline of the series (DTSTART), RFC3339
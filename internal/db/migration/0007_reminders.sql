@@ -0,0 +1,15 @@
+-- reminders fire either a fixed time before the task's deadline or at an
+-- absolute time, exactly one of offset_seconds and remind_at is set
+CREATE TABLE IF NOT EXISTS reminders (
+    reminder_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    offset_seconds INTEGER CHECK(offset_seconds >= 0), -- "1h before" is 3600
+    remind_at DATETIME,                                -- absolute time, RFC3339
+    delivered_for DATETIME,  -- fire time of the last delivery, moving the deadline re-arms the reminder
+    delivered_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    CHECK((offset_seconds IS NULL) != (remind_at IS NULL)),
+    FOREIGN KEY (task_id) REFERENCES tasksmaster(task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_task ON reminders(task_id);
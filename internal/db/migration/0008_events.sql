@@ -0,0 +1,10 @@
+-- the change feed behind GET /v1/events, every task change is appended
+-- here so clients can resume from the last event they saw; the log only
+-- keeps the newest events, older ones are pruned as new ones arrive
+CREATE TABLE IF NOT EXISTS events (
+    event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,     -- task.created, task.updated or task.deleted
+    task_id INTEGER NOT NULL,
+    payload TEXT NOT NULL,  -- the task as JSON, as it was before deletion for task.deleted
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,43 @@
+-- outgoing webhooks, every task event matching a webhook's filter becomes
+-- a delivery that is retried with backoff until the receiver accepts it
+CREATE TABLE IF NOT EXISTS webhooks (
+    webhook_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,           -- HMAC-SHA256 key of the signature header
+    events TEXT NOT NULL DEFAULT '', -- comma-separated event types, blank for all
+    active INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event_id INTEGER NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,          -- the request body, redeliveries send it unchanged
+    status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,       -- RFC3339, also pushed out while an attempt is in flight
+    last_status_code INTEGER,
+    last_error TEXT,
+    redelivery_of INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id)
+);
+
+-- an event is queued once per webhook however many processes follow the log
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event
+    ON webhook_deliveries(webhook_id, event_id) WHERE redelivery_of IS NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries(status, next_attempt_at);
+
+-- how far the event log has been turned into deliveries, starts at the
+-- current end of the log so registering the first webhook doesn't replay it
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+    id INTEGER PRIMARY KEY CHECK(id = 1),
+    last_event_id INTEGER NOT NULL
+);
+INSERT OR IGNORE INTO webhook_cursor (id, last_event_id)
+    SELECT 1, COALESCE(MAX(event_id), 0) FROM events;
@@ -0,0 +1,14 @@
+-- the audit trail behind GET /v1/tasks/{id}/history and GET /v1/activity,
+-- one row per task touched by a change; rows are never updated and are
+-- kept after the task is deleted, so task_id has no foreign key
+CREATE TABLE IF NOT EXISTS task_activity (
+    activity_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+    changes TEXT NOT NULL,  -- JSON array of {field, old, new}
+    actor TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL,   -- http or ws
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_activity_task ON task_activity (task_id, activity_id);
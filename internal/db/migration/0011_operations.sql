@@ -0,0 +1,16 @@
+-- undo/redo, the activity of every request is grouped into an operation of
+-- the client session it came from; undoing an operation moves it to
+-- 'undone', and a new operation of the session discards the undone ones
+-- as they can no longer be redone on top of it
+CREATE TABLE IF NOT EXISTS operations (
+    operation_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session TEXT NOT NULL DEFAULT '',   -- X-Queueit-Session, '' can't undo
+    kind TEXT NOT NULL,
+    state TEXT NOT NULL DEFAULT 'done' CHECK (state IN ('done', 'undone', 'discarded')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_operations_session ON operations (session, state, operation_id);
+
+ALTER TABLE task_activity ADD COLUMN operation_id INTEGER;
+ALTER TABLE task_activity ADD COLUMN via TEXT NOT NULL DEFAULT '';  -- undo or redo, '' for the change itself
@@ -0,0 +1,6 @@
+-- soft delete, a deleted task stays in the trash with its tags, subtasks
+-- and reminders until it is restored or purged; a subtree deleted in one
+-- go shares its deleted_at, so restoring the task brings exactly those back
+ALTER TABLE tasksmaster ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_tasksmaster_deleted ON tasksmaster(deleted_at);
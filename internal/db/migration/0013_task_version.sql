@@ -0,0 +1,17 @@
+-- a counter bumped by every change to a task, handed out as its ETag so
+-- writers can tell when somebody else changed the task in the meantime;
+-- tasksmaster_touch takes care of it along with updated_at, a separate
+-- trigger would bump it again for the touch
+ALTER TABLE tasksmaster ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+DROP TRIGGER IF EXISTS tasksmaster_touch;
+
+CREATE TRIGGER tasksmaster_touch
+AFTER UPDATE ON tasksmaster
+FOR EACH ROW WHEN NEW.version IS OLD.version
+BEGIN
+    UPDATE tasksmaster
+    SET version = OLD.version + 1,
+        updated_at = CASE WHEN NEW.updated_at IS OLD.updated_at THEN CURRENT_TIMESTAMP ELSE NEW.updated_at END
+    WHERE task_id = NEW.task_id;
+END;
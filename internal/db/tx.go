@@ -0,0 +1,79 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// a transaction started by Begin, on a DBInfo bound to a transaction it is
+// a savepoint inside that one, so code written around Begin/Commit keeps
+// working when it ends up running as part of a bigger transaction
+type Tx struct {
+	tx        *sql.Tx
+	savepoint string // blank for the outermost transaction
+	done      bool
+}
+
+func (t *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.tx.Exec(query, args...)
+}
+
+func (t *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return t.tx.Query(query, args...)
+}
+
+func (t *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return t.tx.QueryRow(query, args...)
+}
+
+func (t *Tx) Commit() error {
+	if t.savepoint == "" {
+		return t.tx.Commit()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	_, err := t.tx.Exec("RELEASE " + t.savepoint)
+	return err
+}
+
+// a no-op once committed or rolled back, so it can always be deferred
+func (t *Tx) Rollback() error {
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if _, err := t.tx.Exec("ROLLBACK TO " + t.savepoint); err != nil {
+		return err
+	}
+	_, err := t.tx.Exec("RELEASE " + t.savepoint)
+	return err
+}
+
+// runs fn on a DBInfo whose statements all go through one transaction,
+// committed when fn returns nil and rolled back otherwise; nested calls
+// become savepoints
+func (di *DBInfo) InTx(fn func(*DBInfo) error) error {
+	tx, err := di.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&DBInfo{conn: di.conn, dbfile: di.dbfile, tx: tx, depth: di.depth + 1}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (di *DBInfo) savepoint() (*Tx, error) {
+	name := fmt.Sprintf("sp%d", di.depth)
+	if _, err := di.tx.Exec("SAVEPOINT " + name); err != nil {
+		return nil, err
+	}
+	return &Tx{tx: di.tx.tx, savepoint: name}, nil
+}
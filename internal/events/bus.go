@@ -0,0 +1,66 @@
+// the change feed, task changes are appended to the event log and every
+// subscriber is woken up to read them from there, so a subscriber that
+// falls behind or reconnects catches up from the log instead of a buffer
+package events
+
+import (
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"sync"
+)
+
+// event types
+const (
+	TaskCreated = models.EVENT_TASK_CREATED
+	TaskUpdated = models.EVENT_TASK_UPDATED
+	TaskDeleted = models.EVENT_TASK_DELETED
+)
+
+type Bus struct {
+	store store.EventStore
+
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewBus(s store.EventStore) *Bus {
+	return &Bus{
+		store: s,
+		subs:  map[chan struct{}]struct{}{},
+	}
+}
+
+// records the event and wakes up every subscriber
+func (b *Bus) Publish(typ string, task models.GetTasksResponse) (models.Event, error) {
+	e, err := b.store.AppendEvent(typ, task)
+	if err != nil {
+		return e, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		// a pending wake-up already covers this event
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return e, nil
+}
+
+// the returned channel receives a value whenever events were published
+// since the last receive, cancel stops the wake-ups
+func (b *Bus) Subscribe() (wake <-chan struct{}, cancel func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"time"
+)
+
+const (
+	// events read from the log per Next
+	readBatch = 100
+
+	// how often the log is checked even without a wake-up, catching the
+	// events of other queueit processes on the same database
+	pollInterval = 2 * time.Second
+)
+
+// follows the event log for one subscriber, not safe for concurrent use
+type Reader struct {
+	bus    *Bus
+	after  int64
+	wake   <-chan struct{}
+	cancel func()
+}
+
+// a reader positioned at the newest event, so it only sees the ones
+// published from now on unless moved back with ResumeAfter
+func (b *Bus) Reader() (*Reader, error) {
+	// subscribe before reading the head so nothing published in between is lost
+	wake, cancel := b.Subscribe()
+	head, err := b.store.LastEventID()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Reader{bus: b, after: head, wake: wake, cancel: cancel}, nil
+}
+
+// the id of the last event read
+func (r *Reader) Pos() int64 {
+	return r.after
+}
+
+// continues after the given event
+func (r *Reader) ResumeAfter(after int64) {
+	r.after = after
+}
+
+// the next events, waiting up to wait for some to be published; it comes
+// back empty handed when the wait is over and with reset set when events
+// were pruned before they could be read, the reader then skips to the
+// newest event
+func (r *Reader) Next(ctx context.Context, wait time.Duration) (batch []models.Event, reset bool, err error) {
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	for {
+		batch, err := r.bus.store.EventsAfter(r.after, readBatch)
+		if errors.Is(err, store.ErrEventsPruned) {
+			head, err := r.bus.store.LastEventID()
+			if err != nil {
+				return nil, false, err
+			}
+			r.after = head
+			return nil, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if len(batch) > 0 {
+			r.after = batch[len(batch)-1].EventID
+			return batch, false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-timeout.C:
+			return nil, false, nil
+		case <-r.wake:
+		case <-poll.C:
+		}
+	}
+}
+
+// stops the wake-ups
+func (r *Reader) Close() {
+	r.cancel()
+}
@@ -15,6 +15,81 @@ const (
 	STATUS_ARCHIVED = 4
 )
 
+// Projects:
+const (
+	PROJECT_INBOX = 1 // holds every task without an explicit project
+)
+
+// Event types:
+const (
+	EVENT_TASK_CREATED = "task.created"
+	EVENT_TASK_UPDATED = "task.updated"
+	EVENT_TASK_DELETED = "task.deleted"
+)
+
+// Webhook delivery statuses:
+const (
+	DELIVERY_PENDING   = "pending"
+	DELIVERY_DELIVERED = "delivered"
+	DELIVERY_FAILED    = "failed" // gave up after the last retry
+)
+
+// Bulk request modes:
+const (
+	BULK_ATOMIC      = "atomic"      // one failing item rolls back every other
+	BULK_BEST_EFFORT = "best_effort" // the items that work are kept
+)
+
+// Bulk item ops:
+const (
+	BULK_CREATE = "create"
+	BULK_UPDATE = "update"
+	BULK_DELETE = "delete"
+)
+
+// Activity log actions:
+const (
+	ACTIVITY_CREATED = "created"
+	ACTIVITY_UPDATED = "updated"
+	ACTIVITY_DELETED = "deleted"
+)
+
+// Operation kinds, what a request did as a unit of undo:
+const (
+	OP_CREATE  = "task.create"
+	OP_UPDATE  = "task.update"
+	OP_DELETE  = "task.delete"
+	OP_MOVE    = "task.move"
+	OP_RESTORE = "task.restore"
+	OP_BULK    = "task.bulk"
+)
+
+// Operation states:
+const (
+	OP_DONE      = "done"
+	OP_UNDONE    = "undone"
+	OP_DISCARDED = "discarded" // undone, then overtaken by a new operation
+)
+
+// How an activity entry came about besides the change itself:
+const (
+	VIA_UNDO = "undo"
+	VIA_REDO = "redo"
+)
+
+// Where a change came from:
+const (
+	SOURCE_HTTP = "http"
+	SOURCE_WS   = "ws"
+)
+
+// names who made a change in the activity log, e.g. "app" or "cli"
+const HEADER_ACTOR = "X-Queueit-Actor"
+
+// the client session a change belongs to, undo and redo only touch the
+// changes of the session they are called for
+const HEADER_SESSION = "X-Queueit-Session"
+
 var ValidStatuses = map[int]bool{
 	STATUS_PENDING:  true,
 	STATUS_WIP:      true,
@@ -27,3 +102,9 @@ var ValidPriorities = map[int]bool{
 	PRIORITY_MEDIUM: true,
 	PRIORITY_LOW:    true,
 }
+
+var ValidEventTypes = map[string]bool{
+	EVENT_TASK_CREATED: true,
+	EVENT_TASK_UPDATED: true,
+	EVENT_TASK_DELETED: true,
+}
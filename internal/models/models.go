@@ -1,27 +1,72 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type GetTasksResponse struct {
-	TaskID      int        `json:"task_id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Status      int        `json:"status"`
-	Priority    int        `json:"priority"`
-	CreatedAt   time.Time  `json:"created_at"`
-	DeadlineAt  *time.Time `json:"deadline_at,omitempty"`
+	TaskID       int                `json:"task_id"`
+	Title        string             `json:"title"`
+	Description  string             `json:"description"`
+	Status       int                `json:"status"`
+	Priority     int                `json:"priority"`
+	ProjectID    int                `json:"project_id"`
+	ParentTaskID *int               `json:"parent_task_id,omitempty"`
+	Tags         []string           `json:"tags"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	Version      int64              `json:"version"` // bumped by every change to the task, its ETag
+	DeadlineAt   *time.Time         `json:"deadline_at,omitempty"`
+	Recurrence   string             `json:"recurrence,omitempty"` // RRULE
+	DeletedAt    *time.Time         `json:"deleted_at,omitempty"` // only set on tasks in the trash
+	Progress     *TaskProgress      `json:"progress,omitempty"`
+	Children     []GetTasksResponse `json:"children,omitempty"`
+}
+
+// a task matching a search, Highlight (the title) and Snippet (an excerpt
+// of the description) are HTML-escaped with the matched words wrapped in
+// <mark>, safe to render as HTML
+type SearchResult struct {
+	GetTasksResponse
+	Rank      float64 `json:"rank"` // bm25, lower is a better match
+	Highlight string  `json:"highlight"`
+	Snippet   string  `json:"snippet"`
+}
+
+// one page of search results, NextCursor is blank on the last page
+type SearchPage struct {
+	Items      []SearchResult `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// one page of a task list, NextCursor is blank on the last page
+type TaskPage struct {
+	Items      []GetTasksResponse `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// rolled up from the direct children of a task, archived children are not counted
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
 
 type CreateTaskRequest struct {
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Priority    int        `json:"priority"`
-	DeadlineAt  *time.Time `json:"deadline_at"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Priority     int        `json:"priority"`
+	ProjectID    *int64     `json:"project_id"` // defaults to the parent's project, else the Inbox
+	ParentTaskID *int64     `json:"parent_task_id"`
+	Tags         []string   `json:"tags"`
+	DeadlineAt   *time.Time `json:"deadline_at"`
+	Recurrence   string     `json:"recurrence"` // RRULE, e.g. FREQ=WEEKLY;BYDAY=FR, needs deadline_at
 }
 
 type GenricTaskResponse struct {
-	TaskID  int64  `json:"taskid"`
-	Message string `json:"message"`
+	TaskID     int64  `json:"taskid"`
+	Message    string `json:"message"`
+	NextTaskID int64  `json:"next_taskid,omitempty"` // occurrence created by completing a recurring task
 }
 
 type UpdateTaskRequest struct {
@@ -29,5 +74,301 @@ type UpdateTaskRequest struct {
 	Description *string    `json:"description"`
 	Status      *int       `json:"status"`
 	Priority    *int       `json:"priority"`
+	Tags        *[]string  `json:"tags"` // replaces the whole set, [] clears it
 	DeadlineAt  *time.Time `json:"deadline_at"`
+	Recurrence  *string    `json:"recurrence"` // "" stops the task from repeating
+}
+
+// either a list of items or a filter and the patch applied to every task
+// it matches
+type BulkTasksRequest struct {
+	Mode   string             `json:"mode" enums:"atomic,best_effort"` // atomic when blank
+	Items  []BulkTaskItem     `json:"items,omitempty"`
+	Filter string             `json:"filter,omitempty" example:"status=3&updated_before=2026-09-01"` // GET /v1/tasks query parameters plus project_id
+	Patch  *UpdateTaskRequest `json:"patch,omitempty"`
+}
+
+// Task is a CreateTaskRequest for create and an UpdateTaskRequest for
+// update, delete takes none
+type BulkTaskItem struct {
+	Op     string          `json:"op" enums:"create,update,delete"`
+	TaskID int64           `json:"task_id,omitempty"` // the task to update or delete
+	Task   json.RawMessage `json:"task,omitempty" swaggertype:"object"`
+}
+
+// how one item of a bulk request went, Status is the HTTP status it would
+// have had on its own, 424 for the items an atomic request didn't apply
+type BulkTaskResult struct {
+	Index      int    `json:"index"` // of the item, or of the matched task for a filter
+	Op         string `json:"op"`
+	TaskID     int64  `json:"task_id,omitempty"`
+	NextTaskID int64  `json:"next_task_id,omitempty"` // occurrence created by completing a recurring task
+	Status     int    `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+type BulkTasksResponse struct {
+	Mode      string           `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkTaskResult `json:"results"`
+}
+
+// a reminder on a task, Before is relative to the deadline and At absolute
+type Reminder struct {
+	ReminderID  int64      `json:"reminder_id"`
+	TaskID      int64      `json:"task_id"`
+	Before      string     `json:"before,omitempty"` // e.g. 1h30m0s
+	At          *time.Time `json:"at,omitempty"`
+	FireAt      *time.Time `json:"fire_at,omitempty"` // unset while a Before reminder's task has no deadline
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// exactly one of Before (a duration like "1h" or "15m") and At
+type CreateReminderRequest struct {
+	Before string     `json:"before"`
+	At     *time.Time `json:"at"`
+}
+
+type GenricReminderResponse struct {
+	ReminderID int64  `json:"reminderid"`
+	Message    string `json:"message"`
+}
+
+// a reminder that is due, as handed to a notifier
+type DueReminder struct {
+	ReminderID int64      `json:"reminder_id"`
+	TaskID     int64      `json:"task_id"`
+	Title      string     `json:"title"`
+	DeadlineAt *time.Time `json:"deadline_at,omitempty"`
+	FireAt     time.Time  `json:"fire_at"`
+	Late       bool       `json:"late"` // missed while queueit wasn't running
+}
+
+// a task change from the event log, Task is the task after the change and
+// before it for task.deleted
+type Event struct {
+	EventID   int64            `json:"event_id"`
+	Type      string           `json:"type"`
+	TaskID    int64            `json:"task_id"`
+	Task      GetTasksResponse `json:"task"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// one field of a task before and after a change, old is missing for
+// created tasks and new for deleted ones
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty" swaggertype:"object"`
+	New   json.RawMessage `json:"new,omitempty" swaggertype:"object"`
+}
+
+// an entry of the activity log, one per task touched by a change
+type Activity struct {
+	ActivityID  int64         `json:"activity_id"`
+	TaskID      int64         `json:"task_id"`
+	Action      string        `json:"action"` // created, updated or deleted
+	Changes     []FieldChange `json:"changes"`
+	Actor       string        `json:"actor,omitempty"` // the X-Queueit-Actor of the request
+	Source      string        `json:"source"`          // http or ws
+	OperationID int64         `json:"operation_id,omitempty"`
+	Via         string        `json:"via,omitempty"` // undo or redo when the entry reverts or replays its operation
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// one page of the activity log, NextCursor is blank on the last page
+type ActivityPage struct {
+	Items      []Activity `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// the changes of one request as a unit of undo
+type Operation struct {
+	OperationID int64      `json:"operation_id"`
+	Kind        string     `json:"kind"`  // task.create, task.update, task.delete, task.move, task.restore or task.bulk
+	State       string     `json:"state"` // done, undone or discarded
+	Changes     []Activity `json:"changes"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type UndoResponse struct {
+	Operations []Operation `json:"operations"` // in the order they were undone or redone
+	Message    string      `json:"message"`
+}
+
+// a registered webhook, its secret is never handed out again after creation
+type Webhook struct {
+	WebhookID int64     `json:"webhook_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"` // empty for every event type
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// a blank secret is generated and returned in the response
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"` // defaults to true
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string   `json:"url"`
+	Secret *string   `json:"secret"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+type GenricWebhookResponse struct {
+	WebhookID int64  `json:"webhookid"`
+	Secret    string `json:"secret,omitempty"` // only when it was generated
+	Message   string `json:"message"`
+}
+
+// one event sent (or being sent) to a webhook
+type WebhookDelivery struct {
+	DeliveryID     int64           `json:"delivery_id"`
+	WebhookID      int64           `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"` // pending, delivered or failed
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	RedeliveryOf   *int64          `json:"redelivery_of,omitempty"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type GenricDeliveryResponse struct {
+	DeliveryID int64  `json:"deliveryid"`
+	Message    string `json:"message"`
+}
+
+// a delivery due for an attempt, as handed to the dispatcher
+type DueDelivery struct {
+	DeliveryID int64
+	WebhookID  int64
+	EventType  string
+	URL        string
+	Secret     string
+	Payload    []byte
+	Attempts   int // made so far
+}
+
+// the outcome of a delivery attempt, NextAttemptAt is nil when a failed
+// delivery is not retried anymore
+type DeliveryAttempt struct {
+	Delivered     bool
+	StatusCode    int
+	Error         string
+	NextAttemptAt *time.Time
+}
+
+// a frame of the WebSocket API: a request carries id, method and params,
+// its reply the same id with either result or error, and a broadcast
+// method and params without an id
+type WSMessage struct {
+	ID     json.RawMessage `json:"id,omitempty" swaggertype:"string"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty" swaggertype:"object"`
+	Result any             `json:"result,omitempty"`
+	Error  *WSError        `json:"error,omitempty"`
+}
+
+// why a WebSocket request failed, Code is the HTTP status the same request
+// gets over HTTP
+type WSError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+// params of the subscribe method, zero values don't filter
+type WSSubscribeRequest struct {
+	ProjectID   int    `json:"project_id"`
+	Statuses    []int  `json:"status"`
+	LastEventID *int64 `json:"last_event_id"` // replays the events after it
+}
+
+// upcoming deadlines of a recurring task, after its current one
+type OccurrencesResponse struct {
+	TaskID      int64       `json:"task_id"`
+	Recurrence  string      `json:"recurrence"`
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// null (or missing) parent_task_id moves the task to the top level
+type MoveTaskRequest struct {
+	ParentTaskID *int64 `json:"parent_task_id"`
+}
+
+type Tag struct {
+	TagID     int       `json:"tag_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color,omitempty"`
+	TaskCount int       `json:"task_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateTagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type UpdateTagRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+type GenricTagResponse struct {
+	TagID   int64  `json:"tagid"`
+	Message string `json:"message"`
+}
+
+type Project struct {
+	ProjectID       int       `json:"project_id"`
+	Name            string    `json:"name"`
+	Color           string    `json:"color,omitempty"`
+	DefaultPriority int       `json:"default_priority"`
+	Archived        bool      `json:"archived"`
+	TaskCount       int       `json:"task_count"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type CreateProjectRequest struct {
+	Name            string `json:"name"`
+	Color           string `json:"color"`
+	DefaultPriority int    `json:"default_priority"`
+}
+
+type UpdateProjectRequest struct {
+	Name            *string `json:"name"`
+	Color           *string `json:"color"`
+	DefaultPriority *int    `json:"default_priority"`
+	Archived        *bool   `json:"archived"`
+}
+
+type GenricProjectResponse struct {
+	ProjectID int64  `json:"projectid"`
+	Message   string `json:"message"`
+}
+
+type MoveTaskToProjectRequest struct {
+	ProjectID int64 `json:"project_id"`
+}
+
+// body of the 4xx responses caused by a bad query or path parameter, and
+// of the 500 of a handler that panicked
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Param     string `json:"param,omitempty"`
+	Value     string `json:"value,omitempty"`
+	RequestID string `json:"request_id,omitempty"` // X-Request-ID, to find the request in the log
 }
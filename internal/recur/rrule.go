@@ -0,0 +1,365 @@
+// recurrence rules for repeating tasks, the subset of RFC 5545 RRULE
+// queueit understands:
+//
+//	FREQ=DAILY|WEEKLY|MONTHLY|YEARLY   required
+//	INTERVAL=n                         every n-th day/week/month/year
+//	BYDAY=MO,WE / 1MO,-1FR             weekdays, ordinals only with MONTHLY
+//	BYMONTHDAY=1,15,-1                 days of the month, MONTHLY only
+//	COUNT=n or UNTIL=20261231T000000Z  where the series ends
+//
+// Weeks start on Monday (WKST=MO) and every occurrence keeps the time of
+// day of the series start, which is always the first occurrence.
+package recur
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq string
+
+const (
+	DAILY   Freq = "DAILY"
+	WEEKLY  Freq = "WEEKLY"
+	MONTHLY Freq = "MONTHLY"
+	YEARLY  Freq = "YEARLY"
+)
+
+// a BYDAY entry, N is the ordinal within the month (-1 for the last one)
+// and 0 for every such weekday
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+type Rule struct {
+	Freq       Freq
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int        // 0 means no limit
+	Until      *time.Time // inclusive
+}
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// periods without a single occurrence after which a series is considered
+// exhausted, BYMONTHDAY=31 with INTERVAL=12 starting in February never fires
+const maxEmptyPeriods = 1000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// parses "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", an "RRULE:" prefix is allowed
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return r, fmt.Errorf("%w: rule is blank", ErrInvalidRule)
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return r, fmt.Errorf("%w: %q is not NAME=VALUE", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return r, fmt.Errorf("%w: %s given more than once", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq = Freq(value)
+			if !slices.Contains([]Freq{DAILY, WEEKLY, MONTHLY, YEARLY}, r.Freq) {
+				err = fmt.Errorf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(value)
+		case "COUNT":
+			r.Count, err = positive(value)
+		case "UNTIL":
+			var t time.Time
+			t, err = parseUntil(value)
+			r.Until = &t
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		case "WKST":
+			if value != "MO" {
+				err = fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			err = fmt.Errorf("%s is not supported", name)
+		}
+		if err != nil {
+			return r, fmt.Errorf("%w: %s: %v", ErrInvalidRule, name, err)
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return r, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	case r.Count > 0 && r.Until != nil:
+		return r, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRule)
+	case r.Freq == YEARLY && (len(r.ByDay) > 0 || len(r.ByMonthDay) > 0):
+		return r, fmt.Errorf("%w: FREQ=YEARLY supports neither BYDAY nor BYMONTHDAY", ErrInvalidRule)
+	case r.Freq != MONTHLY && len(r.ByMonthDay) > 0:
+		return r, fmt.Errorf("%w: BYMONTHDAY needs FREQ=MONTHLY", ErrInvalidRule)
+	}
+	if r.Freq != MONTHLY {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return r, fmt.Errorf("%w: BYDAY ordinals need FREQ=MONTHLY", ErrInvalidRule)
+			}
+		}
+	}
+	return r, nil
+}
+
+func positive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("expected a positive integer")
+	}
+	return n, nil
+}
+
+// UNTIL is a UTC date-time (20261231T235959Z) or a bare date, taken as
+// the end of that day in UTC
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("expected YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, v := range strings.Split(value, ",") {
+		if len(v) < 2 {
+			return nil, fmt.Errorf("%q is not a weekday", v)
+		}
+		wd, ok := weekdays[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a weekday", v)
+		}
+		d := WeekdayNum{Weekday: wd}
+		if ord := v[:len(v)-2]; ord != "" {
+			n, err := strconv.Atoi(ord)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("%q has an invalid ordinal", v)
+			}
+			d.N = n
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, fmt.Errorf("%q is not a day of the month", v)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
+// the canonical form of the rule, Parse(r.String()) gives r back
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = strings.ToUpper(d.Weekday.String()[:2])
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// every occurrence of a series starting at start, in order
+func (r Rule) All(start time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		n := 0
+		emit := func(t time.Time) bool {
+			if r.Until != nil && t.After(*r.Until) {
+				return false
+			}
+			n++
+			return yield(t) && (r.Count == 0 || n < r.Count)
+		}
+
+		if !emit(start) {
+			return
+		}
+		for period, empty := 0, 0; empty < maxEmptyPeriods; period++ {
+			found := false
+			for _, t := range r.period(start, period) {
+				if !t.After(start) {
+					continue
+				}
+				found = true
+				if !emit(t) {
+					return
+				}
+			}
+			if found {
+				empty = 0
+			} else {
+				empty++
+			}
+		}
+	}
+}
+
+// the first occurrence strictly after t, false once the series has ended
+func (r Rule) After(start, t time.Time) (time.Time, bool) {
+	for o := range r.All(start) {
+		if o.After(t) {
+			return o, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// up to n occurrences strictly after t
+func (r Rule) Next(start, t time.Time, n int) []time.Time {
+	out := []time.Time{}
+	for o := range r.All(start) {
+		if len(out) == n {
+			break
+		}
+		if o.After(t) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// the sorted candidates of the period-th day/week/month/year of a series
+func (r Rule) period(start time.Time, period int) []time.Time {
+	y, m, d := start.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+	step := period * r.Interval
+
+	var out []time.Time
+	switch r.Freq {
+	case DAILY:
+		t := at(y, m, d+step)
+		if len(r.ByDay) == 0 || r.hasWeekday(t.Weekday()) {
+			out = append(out, t)
+		}
+
+	case WEEKLY:
+		monday := d - (int(start.Weekday())+6)%7 + 7*step
+		if len(r.ByDay) == 0 {
+			out = append(out, at(y, m, d+7*step))
+		}
+		for i := 0; i < 7 && len(r.ByDay) > 0; i++ {
+			if t := at(y, m, monday+i); r.hasWeekday(t.Weekday()) {
+				out = append(out, t)
+			}
+		}
+
+	case MONTHLY:
+		first := at(y, m+time.Month(step), 1)
+		days := daysIn(first)
+		for day := 1; day <= days; day++ {
+			t := at(first.Year(), first.Month(), day)
+			if r.monthlyMatch(t, day, days, d) {
+				out = append(out, t)
+			}
+		}
+
+	case YEARLY:
+		// Feb 29 only comes around in leap years
+		if t := at(y+step, m, d); t.Day() == d {
+			out = append(out, t)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+func (r Rule) hasWeekday(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// whether day (of a month with days days) is in a MONTHLY series, both
+// BYDAY and BYMONTHDAY have to hold when given together
+func (r Rule) monthlyMatch(t time.Time, day, days, startDay int) bool {
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		// months without the start's day are skipped, not clamped
+		return day == startDay
+	}
+
+	if len(r.ByMonthDay) > 0 && !slices.ContainsFunc(r.ByMonthDay, func(md int) bool {
+		return md == day || days+md+1 == day
+	}) {
+		return false
+	}
+
+	if len(r.ByDay) > 0 {
+		nth, nthFromEnd := (day-1)/7+1, -((days-day)/7 + 1)
+		return slices.ContainsFunc(r.ByDay, func(wd WeekdayNum) bool {
+			return wd.Weekday == t.Weekday() && (wd.N == 0 || wd.N == nth || wd.N == nthFromEnd)
+		})
+	}
+	return true
+}
+
+func daysIn(first time.Time) int {
+	return time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
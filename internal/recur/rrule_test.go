@@ -0,0 +1,236 @@
+package recur
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 2006-01-02 at 09:00 UTC, the time of day of every series below
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s+" 09:00")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func days(ts []time.Time) string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format("2006-01-02")
+		if t.Hour() != 9 || t.Minute() != 0 {
+			out[i] += t.Format("T15:04")
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func TestAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		want  string // the first occurrences, up to 6, the start included
+	}{
+		{"daily", "FREQ=DAILY", "2026-01-30",
+			"2026-01-30 2026-01-31 2026-02-01 2026-02-02 2026-02-03 2026-02-04"},
+		{"every other day", "FREQ=DAILY;INTERVAL=2", "2026-01-30",
+			"2026-01-30 2026-02-01 2026-02-03 2026-02-05 2026-02-07 2026-02-09"},
+		{"weekdays", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", "2026-01-01",
+			"2026-01-01 2026-01-02 2026-01-05 2026-01-06 2026-01-07 2026-01-08"},
+		{"weekly", "FREQ=WEEKLY", "2026-01-01",
+			"2026-01-01 2026-01-08 2026-01-15 2026-01-22 2026-01-29 2026-02-05"},
+		// the week of the start is the first period, its Monday is gone
+		{"every other week on Monday and Thursday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "2026-01-01",
+			"2026-01-01 2026-01-12 2026-01-15 2026-01-26 2026-01-29 2026-02-09"},
+		{"every other week from a Sunday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU", "2026-01-04",
+			"2026-01-04 2026-01-12 2026-01-18 2026-01-26 2026-02-01 2026-02-09"},
+		{"monthly", "FREQ=MONTHLY", "2026-01-15",
+			"2026-01-15 2026-02-15 2026-03-15 2026-04-15 2026-05-15 2026-06-15"},
+		// months without a 31st are skipped, not clamped
+		{"monthly on the 31st", "FREQ=MONTHLY", "2026-01-31",
+			"2026-01-31 2026-03-31 2026-05-31 2026-07-31 2026-08-31 2026-10-31"},
+		{"BYMONTHDAY=31", "FREQ=MONTHLY;BYMONTHDAY=31", "2026-01-31",
+			"2026-01-31 2026-03-31 2026-05-31 2026-07-31 2026-08-31 2026-10-31"},
+		{"last day of the month", "FREQ=MONTHLY;BYMONTHDAY=-1", "2026-01-31",
+			"2026-01-31 2026-02-28 2026-03-31 2026-04-30 2026-05-31 2026-06-30"},
+		{"second to last day in a leap year", "FREQ=MONTHLY;BYMONTHDAY=-2", "2028-01-30",
+			"2028-01-30 2028-02-28 2028-03-30 2028-04-29 2028-05-30 2028-06-29"},
+		{"1st and 15th", "FREQ=MONTHLY;BYMONTHDAY=15,1", "2026-01-01",
+			"2026-01-01 2026-01-15 2026-02-01 2026-02-15 2026-03-01 2026-03-15"},
+		{"last Friday", "FREQ=MONTHLY;BYDAY=-1FR", "2026-01-30",
+			"2026-01-30 2026-02-27 2026-03-27 2026-04-24 2026-05-29 2026-06-26"},
+		{"second Monday", "FREQ=MONTHLY;BYDAY=2MO", "2026-01-12",
+			"2026-01-12 2026-02-09 2026-03-09 2026-04-13 2026-05-11 2026-06-08"},
+		{"first and third Wednesday every quarter", "FREQ=MONTHLY;INTERVAL=3;BYDAY=1WE,3WE", "2026-01-07",
+			"2026-01-07 2026-01-21 2026-04-01 2026-04-15 2026-07-01 2026-07-15"},
+		{"fifth Friday", "FREQ=MONTHLY;BYDAY=5FR", "2026-01-30",
+			"2026-01-30 2026-05-29 2026-07-31 2026-10-30 2027-01-29 2027-04-30"},
+		{"every Friday of the month", "FREQ=MONTHLY;BYDAY=FR", "2026-01-30",
+			"2026-01-30 2026-02-06 2026-02-13 2026-02-20 2026-02-27 2026-03-06"},
+		{"Friday the 13th", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "2026-02-13",
+			"2026-02-13 2026-03-13 2026-11-13 2027-08-13 2028-10-13 2029-04-13"},
+		{"yearly", "FREQ=YEARLY", "2026-03-01",
+			"2026-03-01 2027-03-01 2028-03-01 2029-03-01 2030-03-01 2031-03-01"},
+		{"Feb 29", "FREQ=YEARLY", "2024-02-29",
+			"2024-02-29 2028-02-29 2032-02-29 2036-02-29 2040-02-29 2044-02-29"},
+		{"Feb 29 every other year", "FREQ=YEARLY;INTERVAL=2", "2024-02-29",
+			"2024-02-29 2028-02-29 2032-02-29 2036-02-29 2040-02-29 2044-02-29"},
+		{"Feb 29 every third year", "FREQ=YEARLY;INTERVAL=3", "2024-02-29",
+			"2024-02-29 2036-02-29 2048-02-29 2060-02-29 2072-02-29 2084-02-29"},
+		{"COUNT", "FREQ=DAILY;COUNT=3", "2026-01-01",
+			"2026-01-01 2026-01-02 2026-01-03"},
+		{"COUNT of one", "FREQ=WEEKLY;COUNT=1", "2026-01-01",
+			"2026-01-01"},
+		{"COUNT includes a start off the rule", "FREQ=WEEKLY;BYDAY=MO;COUNT=3", "2026-01-01",
+			"2026-01-01 2026-01-05 2026-01-12"},
+		{"UNTIL a date", "FREQ=DAILY;UNTIL=20260103", "2026-01-01",
+			"2026-01-01 2026-01-02 2026-01-03"},
+		{"UNTIL the time of an occurrence", "FREQ=DAILY;UNTIL=20260103T090000Z", "2026-01-01",
+			"2026-01-01 2026-01-02 2026-01-03"},
+		{"UNTIL just before an occurrence", "FREQ=DAILY;UNTIL=20260103T085959Z", "2026-01-01",
+			"2026-01-01 2026-01-02"},
+		{"UNTIL before the start", "FREQ=DAILY;UNTIL=20251231", "2026-01-01",
+			""},
+		// February comes around every 12 months and never has a 31st
+		{"never again", "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31", "2026-02-28",
+			"2026-02-28"},
+		// a fifth Monday in February only in leap years starting on a Monday
+		{"rarely", "FREQ=MONTHLY;INTERVAL=12;BYDAY=5MO", "2026-02-02",
+			"2026-02-02 2044-02-29 2072-02-29 2112-02-29 2140-02-29 2168-02-29"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []time.Time
+			for o := range r.All(day(tt.start)) {
+				got = append(got, o)
+				if len(got) == 6 {
+					break
+				}
+			}
+			if days(got) != tt.want {
+				t.Errorf("occurrences\n got %s\nwant %s", days(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestAllKeepsTheTimeOfDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	r, _ := Parse("FREQ=DAILY")
+	start := time.Date(2026, 3, 28, 8, 30, 0, 0, berlin)
+
+	// across the switch to summer time on March 29
+	got := r.Next(start, start, 2)
+	for _, o := range got {
+		if o.Hour() != 8 || o.Minute() != 30 || o.Location() != berlin {
+			t.Errorf("occurrence at %v, want 08:30 in Berlin", o)
+		}
+	}
+	if got[1].Sub(got[0]) != 24*time.Hour {
+		t.Errorf("%v to %v, want a day apart", got[0], got[1])
+	}
+}
+
+func TestAfter(t *testing.T) {
+	r, _ := Parse("FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+	start := day("2026-01-30")
+
+	tests := []struct {
+		after string
+		want  string // blank once the series ended
+	}{
+		{"2025-12-31", "2026-01-30"},
+		{"2026-01-30", "2026-02-27"},
+		{"2026-02-01", "2026-02-27"},
+		{"2026-02-27", "2026-03-27"},
+		{"2026-03-27", ""},
+	}
+	for _, tt := range tests {
+		got, ok := r.After(start, day(tt.after))
+		if (tt.want == "") == ok || (ok && !got.Equal(day(tt.want))) {
+			t.Errorf("After(%s) = %s %v, want %q", tt.after, days([]time.Time{got}), ok, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	r, _ := Parse("FREQ=WEEKLY;BYDAY=TU,TH")
+	start := day("2026-01-01")
+
+	if got, want := days(r.Next(start, day("2026-01-06"), 3)), "2026-01-08 2026-01-13 2026-01-15"; got != want {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+	if got := r.Next(start, start, 0); len(got) != 0 {
+		t.Errorf("Next of 0 = %s", days(got))
+	}
+
+	r, _ = Parse("FREQ=DAILY;COUNT=2")
+	if got, want := days(r.Next(start, start, 5)), "2026-01-02"; got != want {
+		t.Errorf("Next past COUNT = %s, want %s", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string // the canonical form, blank when the rule is invalid
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"rrule:freq=weekly;interval=1;byday=mo,th", "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{" FREQ=MONTHLY ; BYDAY=-1FR,2MO ; COUNT=3 ", "FREQ=MONTHLY;BYDAY=-1FR,2MO;COUNT=3"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1;INTERVAL=2", "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=1,-1"},
+		{"FREQ=YEARLY;UNTIL=20261231", "FREQ=YEARLY;UNTIL=20261231T235959Z"},
+		{"FREQ=DAILY;WKST=MO", "FREQ=DAILY"},
+
+		{"", ""},
+		{"RRULE:", ""},
+		{"FREQ=HOURLY", ""},
+		{"INTERVAL=2", ""},
+		{"FREQ=DAILY;FREQ=WEEKLY", ""},
+		{"FREQ=DAILY;INTERVAL", ""},
+		{"FREQ=DAILY;INTERVAL=0", ""},
+		{"FREQ=DAILY;COUNT=-1", ""},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20261231", ""},
+		{"FREQ=DAILY;UNTIL=2026-12-31", ""},
+		{"FREQ=WEEKLY;BYDAY=MONDAY", ""},
+		{"FREQ=WEEKLY;BYDAY=1MO", ""},
+		{"FREQ=MONTHLY;BYDAY=6MO", ""},
+		{"FREQ=MONTHLY;BYDAY=0MO", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=0", ""},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", ""},
+		{"FREQ=YEARLY;BYDAY=MO", ""},
+		{"FREQ=DAILY;WKST=SU", ""},
+		{"FREQ=DAILY;BYHOUR=9", ""},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.rule)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Parse(%q) = %v, want an ErrInvalidRule", tt.rule, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.rule, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.rule, got, tt.want)
+		}
+		if again, err := Parse(r.String()); err != nil || !slices.Equal(again.ByDay, r.ByDay) || again.String() != r.String() {
+			t.Errorf("Parse(%q) doesn't give the rule back: %v", r.String(), err)
+		}
+	}
+}
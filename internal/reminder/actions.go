@@ -0,0 +1,88 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"queueit/internal/models"
+	"strings"
+	"time"
+)
+
+// keys of the actions offered on a notification
+const (
+	ActionDone   = "done"
+	ActionSnooze = "snooze"
+)
+
+// how far "Snooze 10m" pushes a reminder out
+const SnoozeFor = 10 * time.Minute
+
+// the actions in the order they are shown, as key/label pairs
+var actionLabels = []string{
+	ActionDone, "Mark done",
+	ActionSnooze, "Snooze 10m",
+}
+
+// runs notification actions against the task API, so they go through the
+// same rules (recurrence roll-over and all) as edits made in the app
+type TaskAPI struct {
+	baseURL string
+	client  *http.Client
+}
+
+// baseURL is where the API is served, like http://127.0.0.1:18772
+func NewTaskAPI(baseURL string) *TaskAPI {
+	return &TaskAPI{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// runs the action with the given key on a task
+func (a *TaskAPI) Do(ctx context.Context, action string, taskID int64) error {
+	switch action {
+	case ActionDone:
+		return a.MarkDone(ctx, taskID)
+	case ActionSnooze:
+		return a.Snooze(ctx, taskID, SnoozeFor)
+	}
+	return fmt.Errorf("unknown reminder action %q", action)
+}
+
+func (a *TaskAPI) MarkDone(ctx context.Context, taskID int64) error {
+	return a.send(ctx, http.MethodPatch, fmt.Sprintf("/v1/tasks/%d", taskID),
+		map[string]any{"status": models.STATUS_DONE})
+}
+
+// reminds about the task again d from now
+func (a *TaskAPI) Snooze(ctx context.Context, taskID int64, d time.Duration) error {
+	return a.send(ctx, http.MethodPost, fmt.Sprintf("/v1/tasks/%d/reminders", taskID),
+		map[string]any{"at": time.Now().Add(d)})
+}
+
+func (a *TaskAPI) send(ctx context.Context, method, path string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(models.HEADER_ACTOR, "reminder")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return nil
+}
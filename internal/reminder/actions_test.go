@@ -0,0 +1,28 @@
+package reminder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTaskAPIErrors(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	api := NewTaskAPI(srv.URL)
+
+	if err := api.Do(context.Background(), "archive", 1); err == nil {
+		t.Error("Do ran an unknown action")
+	}
+	if err := api.Do(context.Background(), ActionDone, 1); err == nil {
+		t.Error("Do succeeded on a 404")
+	}
+	status = http.StatusOK
+	if err := api.Do(context.Background(), ActionSnooze, 1); err != nil {
+		t.Errorf("Do failed on a 200: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+//go:build linux
+
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"slices"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notificationsName  = "org.freedesktop.Notifications"
+	notificationsPath  = "/org/freedesktop/Notifications"
+	notificationsIface = "org.freedesktop.Notifications"
+)
+
+// notifier showing reminders as native desktop notifications through the
+// freedesktop Notifications D-Bus interface, clicking one of the actions
+// runs it through the task API
+type DesktopNotifier struct {
+	conn    *dbus.Conn
+	obj     dbus.BusObject
+	actions *TaskAPI
+	signals chan *dbus.Signal
+
+	// notification servers without the "actions" capability get plain ones
+	withActions bool
+
+	mu sync.Mutex
+	// tasks of the notifications still on screen, by notification id
+	shown map[uint32]int64
+}
+
+// connects to the session bus of the current desktop session
+func ConnectDesktop(actions *TaskAPI) (*DesktopNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	n, err := NewDesktopNotifier(conn, actions)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// notifies through an already open bus connection, a private one to a
+// throwaway dbus-daemon works just as well as the session bus
+func NewDesktopNotifier(conn *dbus.Conn, actions *TaskAPI) (*DesktopNotifier, error) {
+	n := &DesktopNotifier{
+		conn:    conn,
+		obj:     conn.Object(notificationsName, notificationsPath),
+		actions: actions,
+		signals: make(chan *dbus.Signal, 16),
+		shown:   map[uint32]int64{},
+	}
+
+	// doubles as a check that a notification server is around
+	var caps []string
+	if err := n.obj.Call(notificationsIface+".GetCapabilities", 0).Store(&caps); err != nil {
+		return nil, fmt.Errorf("no desktop notification server: %w", err)
+	}
+	n.withActions = slices.Contains(caps, "actions")
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(notificationsPath),
+		dbus.WithMatchInterface(notificationsIface),
+	); err != nil {
+		return nil, err
+	}
+	conn.Signal(n.signals)
+	go n.listen()
+
+	return n, nil
+}
+
+func (n *DesktopNotifier) Notify(ctx context.Context, r models.DueReminder) error {
+	var actions []string
+	if n.withActions {
+		actions = actionLabels
+	}
+	hints := map[string]dbus.Variant{
+		"urgency":  dbus.MakeVariant(byte(1)), // normal
+		"category": dbus.MakeVariant("x-queueit.reminder"),
+	}
+
+	var id uint32
+	err := n.obj.CallWithContext(ctx, notificationsIface+".Notify", 0,
+		"queueit",       // app name
+		uint32(0),       // replaces nothing
+		"",              // icon
+		r.Title,         // summary
+		reminderBody(r), // body
+		actions,         // key, label, key, label...
+		hints,           // hints
+		int32(-1),       // server's default timeout
+	).Store(&id)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.shown[id] = r.TaskID
+	n.mu.Unlock()
+	return nil
+}
+
+// stops listening for actions and closes the bus connection
+func (n *DesktopNotifier) Close() error {
+	n.conn.RemoveSignal(n.signals)
+	return n.conn.Close()
+}
+
+// handles the actions clicked on our notifications, the signals of other
+// applications' notifications are ignored as their ids are unknown
+func (n *DesktopNotifier) listen() {
+	for sig := range n.signals {
+		if len(sig.Body) < 2 {
+			continue
+		}
+		id, ok := sig.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		switch sig.Name {
+		case notificationsIface + ".ActionInvoked":
+			key, _ := sig.Body[1].(string)
+			n.mu.Lock()
+			taskID, ours := n.shown[id]
+			n.mu.Unlock()
+			if !ours || (key != ActionDone && key != ActionSnooze) {
+				continue
+			}
+			if err := n.actions.Do(context.Background(), key, taskID); err != nil {
+				logger.Error(err, "desktop notifier ~", key, "on task", taskID, "failed")
+			}
+
+		case notificationsIface + ".NotificationClosed":
+			n.mu.Lock()
+			delete(n.shown, id)
+			n.mu.Unlock()
+		}
+	}
+}
+
+func reminderBody(r models.DueReminder) string {
+	body := "No deadline"
+	if r.DeadlineAt != nil {
+		body = "Due " + r.DeadlineAt.Local().Format("Mon 2 Jan 15:04")
+	}
+	if r.Late {
+		body += " (missed while queueit was closed)"
+	}
+	return body
+}
@@ -0,0 +1,274 @@
+package reminder
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// a bus of its own, so the tests never touch the desktop session
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=BUS</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// starts a private dbus-daemon and returns its address, the test is
+// skipped where there is no dbus-daemon
+func startBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(strings.Replace(busConfig, "BUS", filepath.Join(dir, "bus"), 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading the bus address: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func connect(t *testing.T, addr string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// one Notify call as the notification server got it
+type notifyCall struct {
+	app, summary, body string
+	actions            []string
+	hints              map[string]dbus.Variant
+	timeout            int32
+}
+
+// a notification server on the bus, handing out ids from 7 up
+type fakeServer struct {
+	conn *dbus.Conn
+	caps []string
+	got  chan notifyCall
+
+	mu     sync.Mutex
+	nextID uint32
+}
+
+func startServer(t *testing.T, addr string, caps ...string) *fakeServer {
+	t.Helper()
+	s := &fakeServer{conn: connect(t, addr), caps: caps, got: make(chan notifyCall, 4), nextID: 7}
+	if err := s.conn.Export(s, notificationsPath, notificationsIface); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := s.conn.RequestName(notificationsName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("owning %s: %v %v", notificationsName, reply, err)
+	}
+	return s
+}
+
+func (s *fakeServer) GetCapabilities() ([]string, *dbus.Error) {
+	return s.caps, nil
+}
+
+func (s *fakeServer) Notify(app string, replaces uint32, icon, summary, body string, actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.got <- notifyCall{app: app, summary: summary, body: body, actions: actions, hints: hints, timeout: timeout}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return s.nextID - 1, nil
+}
+
+// what the user does to a notification
+func (s *fakeServer) emit(t *testing.T, signal string, id uint32, arg any) {
+	t.Helper()
+	if err := s.conn.Emit(notificationsPath, notificationsIface+"."+signal, id, arg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// a task API recording the requests it gets
+type fakeAPI struct {
+	*httptest.Server
+	got chan apiCall
+}
+
+type apiCall struct {
+	method, path, actor string
+	body                map[string]any
+}
+
+func startAPI(t *testing.T) *fakeAPI {
+	a := &fakeAPI{got: make(chan apiCall, 8)}
+	a.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := apiCall{method: r.Method, path: r.URL.Path, actor: r.Header.Get(models.HEADER_ACTOR)}
+		json.NewDecoder(r.Body).Decode(&c.body)
+		a.got <- c
+	}))
+	t.Cleanup(a.Close)
+	return a
+}
+
+func (a *fakeAPI) next(t *testing.T) apiCall {
+	t.Helper()
+	select {
+	case c := <-a.got:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task API got no request")
+		return apiCall{}
+	}
+}
+
+func TestDesktopNotify(t *testing.T) {
+	addr := startBus(t)
+	server := startServer(t, addr, "body", "actions")
+	n, err := NewDesktopNotifier(connect(t, addr), NewTaskAPI("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Date(2026, 10, 18, 15, 0, 0, 0, time.Local)
+	if err := n.Notify(context.Background(), models.DueReminder{ReminderID: 1, TaskID: 42, Title: "Pay rent", DeadlineAt: &deadline, Late: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-server.got
+	if got.app != "queueit" || got.summary != "Pay rent" || got.timeout != -1 {
+		t.Errorf("Notify(%q, %q, timeout %d), want queueit, Pay rent and -1", got.app, got.summary, got.timeout)
+	}
+	if want := "Due Sun 18 Oct 15:00 (missed while queueit was closed)"; got.body != want {
+		t.Errorf("body = %q, want %q", got.body, want)
+	}
+	if !slices.Equal(got.actions, actionLabels) {
+		t.Errorf("actions = %q, want %q", got.actions, actionLabels)
+	}
+	if urgency, ok := got.hints["urgency"].Value().(byte); !ok || urgency != 1 {
+		t.Errorf("urgency hint = %v, want 1", got.hints["urgency"])
+	}
+}
+
+func TestDesktopNotifyWithoutActions(t *testing.T) {
+	addr := startBus(t)
+	server := startServer(t, addr, "body")
+	n, err := NewDesktopNotifier(connect(t, addr), NewTaskAPI("http://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := n.Notify(context.Background(), models.DueReminder{TaskID: 42, Title: "Pay rent"}); err != nil {
+		t.Fatal(err)
+	}
+	got := <-server.got
+	if len(got.actions) != 0 || got.body != "No deadline" {
+		t.Errorf("Notify got actions %q and body %q, want none and No deadline", got.actions, got.body)
+	}
+}
+
+func TestDesktopWithoutServer(t *testing.T) {
+	addr := startBus(t)
+	if _, err := NewDesktopNotifier(connect(t, addr), nil); err == nil {
+		t.Error("NewDesktopNotifier succeeded on a bus without a notification server")
+	}
+}
+
+func TestDesktopActions(t *testing.T) {
+	addr := startBus(t)
+	server := startServer(t, addr, "body", "actions")
+	api := startAPI(t)
+	n, err := NewDesktopNotifier(connect(t, addr), NewTaskAPI(api.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	notify := func(taskID int64) {
+		t.Helper()
+		if err := n.Notify(context.Background(), models.DueReminder{TaskID: taskID, Title: "task"}); err != nil {
+			t.Fatal(err)
+		}
+		<-server.got
+	}
+	notify(42) // id 7
+	notify(43) // id 8
+
+	server.emit(t, "ActionInvoked", 7, ActionDone)
+	c := api.next(t)
+	if c.method != http.MethodPatch || c.path != "/v1/tasks/42" || c.body["status"] != float64(models.STATUS_DONE) || c.actor != "reminder" {
+		t.Errorf("Mark done sent %+v, want PATCH /v1/tasks/42 with status done by reminder", c)
+	}
+
+	server.emit(t, "ActionInvoked", 8, ActionSnooze)
+	c = api.next(t)
+	if c.method != http.MethodPost || c.path != "/v1/tasks/43/reminders" {
+		t.Fatalf("Snooze sent %s %s, want POST /v1/tasks/43/reminders", c.method, c.path)
+	}
+	at, err := time.Parse(time.RFC3339Nano, c.body["at"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait := time.Until(at); wait < SnoozeFor-time.Minute || wait > SnoozeFor {
+		t.Errorf("snoozed until %v, want about %v from now", at, SnoozeFor)
+	}
+
+	// unknown actions, other applications' notifications and closed ones
+	// are left alone; the last action shows they were all handled
+	server.emit(t, "ActionInvoked", 7, "default")
+	server.emit(t, "ActionInvoked", 99, ActionDone)
+	server.emit(t, "NotificationClosed", 7, uint32(2))
+	server.emit(t, "ActionInvoked", 7, ActionDone)
+	server.emit(t, "ActionInvoked", 8, ActionDone)
+	if c := api.next(t); c.path != "/v1/tasks/43" {
+		t.Errorf("got %s %s, want only the Mark done of task 43", c.method, c.path)
+	}
+}
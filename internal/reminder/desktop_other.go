@@ -0,0 +1,25 @@
+//go:build !linux
+
+package reminder
+
+import (
+	"context"
+	"errors"
+	"queueit/internal/models"
+)
+
+// desktop notifications go through the freedesktop D-Bus interface, which
+// only Linux desktops provide, elsewhere reminders fall back to the toast
+type DesktopNotifier struct{}
+
+func ConnectDesktop(actions *TaskAPI) (*DesktopNotifier, error) {
+	return nil, errors.New("desktop notifications are only supported on Linux")
+}
+
+func (n *DesktopNotifier) Notify(ctx context.Context, r models.DueReminder) error {
+	return errors.New("desktop notifications are only supported on Linux")
+}
+
+func (n *DesktopNotifier) Close() error {
+	return nil
+}
@@ -0,0 +1,95 @@
+// background delivery of task reminders, the Scheduler wakes up whenever
+// a reminder is due and hands it to a Notifier
+package reminder
+
+import (
+	"context"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"time"
+)
+
+const (
+	// upper bound on how long the scheduler sleeps, reminders created in
+	// the meantime are picked up by the next poll at the latest
+	pollInterval = 30 * time.Second
+
+	// reminders firing this long before the scheduler saw them were missed
+	// while queueit wasn't running and are delivered marked as late
+	lateAfter = time.Minute
+)
+
+// delivers a due reminder, a reminder whose delivery failed is retried
+// on the next poll
+type Notifier interface {
+	Notify(ctx context.Context, r models.DueReminder) error
+}
+
+// notifier writing reminders to the log, for headless setups
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, r models.DueReminder) error {
+	logger.Info("reminder ~", r.Title, "(task", r.TaskID, ") fires", r.FireAt, "deadline", r.DeadlineAt, "late:", r.Late)
+	return nil
+}
+
+type Scheduler struct {
+	store    store.ReminderStore
+	notifier Notifier
+}
+
+func NewScheduler(s store.ReminderStore, n Notifier) *Scheduler {
+	return &Scheduler{
+		store:    s,
+		notifier: n,
+	}
+}
+
+// delivers reminders until ctx is cancelled, the first round catches up on
+// everything that came due while queueit was closed
+func (s *Scheduler) Run(ctx context.Context) {
+	logger.Info("reminder scheduler started")
+	for {
+		next := s.deliverDue(ctx)
+
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Info("reminder scheduler stopped")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// delivers every reminder that is due and returns how long to sleep
+func (s *Scheduler) deliverDue(ctx context.Context) time.Duration {
+	now := time.Now()
+	pending, err := s.store.PendingReminders(now.Add(pollInterval))
+	if err != nil {
+		logger.Error(err, "reminder scheduler ~ loading reminders failed")
+		return pollInterval
+	}
+
+	for _, r := range pending {
+		if r.FireAt.After(now) {
+			// pending is sorted, this is the next one to come due
+			return r.FireAt.Sub(now)
+		}
+		if ctx.Err() != nil {
+			return 0
+		}
+
+		r.Late = now.Sub(r.FireAt) > lateAfter
+		if err := s.notifier.Notify(ctx, r); err != nil {
+			logger.Error(err, "reminder scheduler ~ delivering reminder", r.ReminderID, "failed")
+			continue
+		}
+		if err := s.store.MarkReminderDelivered(r.ReminderID, r.FireAt); err != nil {
+			logger.Error(err, "reminder scheduler ~ recording delivery of reminder", r.ReminderID, "failed")
+		}
+	}
+	return pollInterval
+}
@@ -0,0 +1,80 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"queueit/internal/models"
+	"sync/atomic"
+)
+
+// notifier showing reminders as a toast inside the app window, eval runs
+// a script in the window (webview's Eval, dispatched to the UI thread)
+//
+// The page renders the toast through window.queueitToast and its buttons
+// call the action binding (ToastActionBinding) with the action key and
+// task id. Until the page reports in through the ready binding reminders
+// fail, leaving them to the next poll instead of a page that isn't there.
+type ToastNotifier struct {
+	eval  func(js string)
+	ready atomic.Bool
+}
+
+// names under which the window exposes TaskAPI.Do and Ready to the page
+const (
+	ToastActionBinding = "queueitReminderAction"
+	ToastReadyBinding  = "queueitToastReady"
+)
+
+var errToastNotReady = errors.New("app window is not ready for toasts yet")
+
+func NewToastNotifier(eval func(js string)) *ToastNotifier {
+	return &ToastNotifier{eval: eval}
+}
+
+type toast struct {
+	models.DueReminder
+	Actions []toastAction `json:"actions"`
+}
+
+type toastAction struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// called by the page once it can show toasts
+func (t *ToastNotifier) Ready() {
+	t.ready.Store(true)
+}
+
+func (t *ToastNotifier) Notify(ctx context.Context, r models.DueReminder) error {
+	if !t.ready.Load() {
+		return errToastNotReady
+	}
+	payload := toast{DueReminder: r}
+	for i := 0; i < len(actionLabels); i += 2 {
+		payload.Actions = append(payload.Actions, toastAction{Key: actionLabels[i], Label: actionLabels[i+1]})
+	}
+	js, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	t.eval(fmt.Sprintf("window.queueitToast && window.queueitToast(%s)", js))
+	return nil
+}
+
+// tries the notifiers in order until one of them delivers the reminder
+type Fallback []Notifier
+
+func (f Fallback) Notify(ctx context.Context, r models.DueReminder) error {
+	var errs []error
+	for _, n := range f {
+		err := n.Notify(ctx, r)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+package store
+
+// the activity log is always listed newest first
+var activitySort = []SortKey{{Field: "activity_id", Desc: true}}
+
+// the id the next page starts below, 0 for the first page
+func activityCursor(p Page) (int64, error) {
+	if p.Cursor == "" {
+		return 0, nil
+	}
+	values, err := decodeCursor(p.Cursor, activitySort, 1)
+	if err != nil {
+		return 0, err
+	}
+	// numbers come back from JSON as float64
+	id, ok := values[0].(float64)
+	if !ok || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return int64(id), nil
+}
+
+func nextActivityCursor(lastID int64) string {
+	return encodeCursor(activitySort, []any{lastID})
+}
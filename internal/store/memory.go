@@ -0,0 +1,808 @@
+package store
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"queueit/internal/models"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memTask struct {
+	id          int64
+	title       string
+	description string
+	priority    int
+	status      int
+	projectID   int64
+	parentID    *int64
+	createdAt   time.Time
+	updatedAt   time.Time
+	deadlineAt  *time.Time
+	rrule       string
+	rruleStart  *time.Time
+	deletedAt   *time.Time
+	version     int64
+	tagIDs      map[int64]bool
+}
+
+// what the tasksmaster_touch trigger does in SQLite
+func (t *memTask) touch() {
+	t.updatedAt = now()
+	t.version++
+}
+
+type memTag struct {
+	id        int64
+	name      string
+	color     string
+	createdAt time.Time
+}
+
+type memProject struct {
+	id              int64
+	name            string
+	color           string
+	defaultPriority int
+	archived        bool
+	createdAt       time.Time
+}
+
+// Store kept entirely in memory, for embedding queueit's logic in other
+// tools and for handler tests that shouldn't need a database file
+type MemoryStore struct {
+	mu         sync.RWMutex
+	tasks      map[int64]*memTask
+	trash      map[int64]*memTask // deleted tasks, out of reach of everything but TrashStore
+	tags       map[int64]*memTag
+	projects   map[int64]*memProject
+	reminders  map[int64]*memReminder
+	events     []models.Event    // the event log, oldest first
+	activity   []models.Activity // oldest first
+	operations []*memOperation   // oldest first
+
+	webhooks      map[int64]*memWebhook
+	deliveries    map[int64]*memDelivery
+	webhookCursor int64
+
+	lastID struct{ task, tag, project, reminder, event, webhook, delivery, activity, operation int64 }
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		tasks:      map[int64]*memTask{},
+		trash:      map[int64]*memTask{},
+		tags:       map[int64]*memTag{},
+		projects:   map[int64]*memProject{},
+		reminders:  map[int64]*memReminder{},
+		webhooks:   map[int64]*memWebhook{},
+		deliveries: map[int64]*memDelivery{},
+	}
+	s.projects[models.PROJECT_INBOX] = &memProject{
+		id:              models.PROJECT_INBOX,
+		name:            "Inbox",
+		defaultPriority: models.PRIORITY_MEDIUM,
+		createdAt:       now(),
+	}
+	s.lastID.project = models.PROJECT_INBOX
+	return s
+}
+
+// fn works on a copy of the store that replaces it when fn succeeds, the
+// lock is held throughout so nobody else sees or changes the store meanwhile
+func (s *MemoryStore) Tx(fn func(tx Store) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.clone()
+	if err := fn(c); err != nil {
+		return err
+	}
+
+	s.tasks, s.trash, s.tags, s.projects, s.reminders = c.tasks, c.trash, c.tags, c.projects, c.reminders
+	s.events, s.activity, s.operations = c.events, c.activity, c.operations
+	s.webhooks, s.deliveries, s.webhookCursor = c.webhooks, c.deliveries, c.webhookCursor
+	s.lastID = c.lastID
+	return nil
+}
+
+// a copy nothing done to can show through to s, the caller holds the lock
+func (s *MemoryStore) clone() *MemoryStore {
+	c := &MemoryStore{
+		tasks:         cloneRecords(s.tasks),
+		trash:         cloneRecords(s.trash),
+		tags:          cloneRecords(s.tags),
+		projects:      cloneRecords(s.projects),
+		reminders:     cloneRecords(s.reminders),
+		events:        slices.Clone(s.events),
+		activity:      slices.Clone(s.activity),
+		webhooks:      cloneRecords(s.webhooks),
+		deliveries:    cloneRecords(s.deliveries),
+		webhookCursor: s.webhookCursor,
+		lastID:        s.lastID,
+	}
+	for _, tasks := range []map[int64]*memTask{c.tasks, c.trash} {
+		for _, t := range tasks {
+			t.tagIDs = maps.Clone(t.tagIDs)
+		}
+	}
+	for _, w := range c.webhooks {
+		w.events = slices.Clone(w.events)
+	}
+	for _, op := range s.operations {
+		o := *op
+		c.operations = append(c.operations, &o)
+	}
+	return c
+}
+
+func cloneRecords[T any](records map[int64]*T) map[int64]*T {
+	out := make(map[int64]*T, len(records))
+	for id, r := range records {
+		c := *r
+		out[id] = &c
+	}
+	return out
+}
+
+func (s *MemoryStore) List(f TaskFilter, p Page) ([]models.GetTasksResponse, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, next, err := s.page(s.tasks, f, p, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks := []models.GetTasksResponse{}
+	for _, r := range rows {
+		tasks = append(tasks, s.view(r.t))
+	}
+	return tasks, next, nil
+}
+
+// a task picked by page, with its rank when searching
+type memRow struct {
+	t      *memTask
+	rank   float64
+	values []any
+}
+
+// one page of the tasks (live or trashed) matching f, rank (may be nil)
+// ranks every task and leaves out the ones it returns false for; the
+// caller holds the lock
+func (s *MemoryStore) page(tasks map[int64]*memTask, f TaskFilter, p Page, rank func(t *memTask) (float64, bool)) ([]memRow, string, error) {
+	keys := memSortKeys(p.Sort)
+	var after []any
+	if p.Cursor != "" {
+		var err error
+		if after, err = decodeCursor(p.Cursor, p.Sort, len(keys)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var wantTags []int64
+	for _, name := range f.Tags {
+		if g := s.tagByName(name); g != nil {
+			wantTags = append(wantTags, g.id)
+		}
+	}
+
+	var rows []memRow
+	for _, t := range tasks {
+		if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, t.status) {
+			continue
+		}
+		if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, t.priority) {
+			continue
+		}
+		if f.ProjectID != 0 && t.projectID != f.ProjectID {
+			continue
+		}
+		if len(f.Tags) > 0 {
+			matched := 0
+			for _, id := range wantTags {
+				if t.tagIDs[id] {
+					matched++
+				}
+			}
+			if matched == 0 || (f.AllTags && matched < len(f.Tags)) {
+				continue
+			}
+		}
+		if !f.matchesDates(t) {
+			continue
+		}
+
+		r := memRow{t: t, values: make([]any, len(keys))}
+		if rank != nil {
+			var ok bool
+			if r.rank, ok = rank(t); !ok {
+				continue
+			}
+		}
+		for i, k := range keys {
+			r.values[i] = memSortValue(r, k)
+		}
+		if after != nil && compareSortValues(keys, r.values, after) <= 0 {
+			continue
+		}
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return compareSortValues(keys, rows[i].values, rows[j].values) < 0
+	})
+
+	next := ""
+	if p.Limit > 0 && len(rows) > p.Limit {
+		rows = rows[:p.Limit]
+		next = encodeCursor(p.Sort, rows[p.Limit-1].values)
+	}
+	return rows, next, nil
+}
+
+// applies the date parts of the filter the way SQLiteStore does, a task
+// without a deadline never falls into a deadline range
+func (f TaskFilter) matchesDates(t *memTask) bool {
+	after := func(v time.Time, from *time.Time) bool { return from == nil || !v.Before(from.Truncate(time.Second)) }
+	before := func(v time.Time, to *time.Time) bool { return to == nil || v.Before(to.Truncate(time.Second)) }
+
+	if f.DeadlineAfter != nil || f.DeadlineBefore != nil {
+		if t.deadlineAt == nil || !after(*t.deadlineAt, f.DeadlineAfter) || !before(*t.deadlineAt, f.DeadlineBefore) {
+			return false
+		}
+	}
+	if !after(t.createdAt, f.CreatedAfter) || !after(t.updatedAt, f.UpdatedAfter) || !before(t.updatedAt, f.UpdatedBefore) {
+		return false
+	}
+	if f.HasDeadline != nil && *f.HasDeadline != (t.deadlineAt != nil) {
+		return false
+	}
+	if f.Overdue != nil {
+		overdue := t.deadlineAt != nil && t.deadlineAt.Before(time.Now()) &&
+			t.status != models.STATUS_DONE && t.status != models.STATUS_ARCHIVED
+		if *f.Overdue != overdue {
+			return false
+		}
+	}
+	return true
+}
+
+// the sort keys plus the task_id tie breaker, as SQLiteStore orders them
+func memSortKeys(keys []SortKey) []SortKey {
+	for _, k := range keys {
+		if k.Field == "task_id" {
+			return keys
+		}
+	}
+	return append(slices.Clip(keys), SortKey{Field: "task_id"})
+}
+
+// a task's value for one sort key, numbers are float64 and strings are
+// folded so they compare the same way after a trip through a cursor
+func memSortValue(r memRow, k SortKey) any {
+	t := r.t
+	switch k.Field {
+	case "rank":
+		return r.rank
+	case "title":
+		return strings.ToLower(t.title)
+	case "status":
+		return float64(t.status)
+	case "priority":
+		return float64(t.priority)
+	case "project_id":
+		return float64(t.projectID)
+	case "created_at":
+		return float64(t.createdAt.Unix())
+	case "updated_at":
+		return float64(t.updatedAt.Unix())
+	case "deleted_at":
+		if t.deletedAt == nil {
+			return 0.0
+		}
+		return float64(t.deletedAt.Unix())
+	case "deadline_at":
+		// tasks without a deadline sort last either way
+		switch {
+		case t.deadlineAt != nil:
+			return float64(t.deadlineAt.Unix())
+		case k.Desc:
+			return -math.MaxFloat64
+		default:
+			return math.MaxFloat64
+		}
+	}
+	return float64(t.id)
+}
+
+func compareSortValues(keys []SortKey, a, b []any) int {
+	for i, k := range keys {
+		var c int
+		switch av := a[i].(type) {
+		case string:
+			bv, _ := b[i].(string)
+			c = strings.Compare(av, bv)
+		case float64:
+			bv, _ := b[i].(float64)
+			c = cmp.Compare(av, bv)
+		}
+		if k.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (s *MemoryStore) Get(id int64) (models.GetTasksResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return models.GetTasksResponse{}, ErrTaskNotFound
+	}
+	return s.view(t), nil
+}
+
+func (s *MemoryStore) Subtree(id int64) ([]models.GetTasksResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tasks[id]; !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	var tasks []models.GetTasksResponse
+	for _, tid := range s.descendants(id) {
+		tasks = append(tasks, s.view(s.tasks[tid]))
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].TaskID < tasks[j].TaskID })
+	return nestSubtree(id, tasks), nil
+}
+
+func (s *MemoryStore) Create(req models.CreateTaskRequest) (int64, error) {
+	tags, err := NormalizeTagNames(req.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	var rrule string
+	if req.Recurrence != "" {
+		if req.DeadlineAt == nil {
+			return 0, ErrRecurrenceDeadline
+		}
+		if rrule, err = normalizeRecurrence(req.Recurrence); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// a subtask always lives in its parent's project
+	projectID := int64(models.PROJECT_INBOX)
+	if req.ParentTaskID != nil {
+		parent, ok := s.tasks[*req.ParentTaskID]
+		if !ok {
+			return 0, ErrParentNotFound
+		}
+		if req.ProjectID != nil && *req.ProjectID != parent.projectID {
+			return 0, ErrParentProject
+		}
+		projectID = parent.projectID
+	} else if req.ProjectID != nil {
+		projectID = *req.ProjectID
+	}
+
+	p, ok := s.projects[projectID]
+	if !ok || p.archived {
+		return 0, ErrProjectUnavailable
+	}
+
+	if !models.ValidPriorities[req.Priority] {
+		req.Priority = p.defaultPriority
+	}
+
+	s.lastID.task++
+	t := &memTask{
+		id:          s.lastID.task,
+		title:       req.Title,
+		description: req.Description,
+		priority:    req.Priority,
+		status:      models.STATUS_PENDING, // default status
+		projectID:   projectID,
+		parentID:    copyPtr(req.ParentTaskID),
+		createdAt:   now(),
+		updatedAt:   now(),
+		version:     1,
+		deadlineAt:  copyPtr(req.DeadlineAt),
+	}
+	if rrule != "" {
+		t.rrule, t.rruleStart = rrule, copyPtr(req.DeadlineAt)
+	}
+	s.setTaskTags(t, tags)
+	s.tasks[t.id] = t
+	return t.id, nil
+}
+
+func (s *MemoryStore) Update(id int64, req models.UpdateTaskRequest) (int64, error) {
+	var tags []string
+	if req.Tags != nil {
+		var err error
+		if tags, err = NormalizeTagNames(*req.Tags); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return 0, ErrTaskNotFound
+	}
+
+	var rrule string
+	if req.Recurrence != nil && *req.Recurrence != "" {
+		if req.DeadlineAt == nil && t.deadlineAt == nil {
+			return 0, ErrRecurrenceDeadline
+		}
+		var err error
+		if rrule, err = normalizeRecurrence(*req.Recurrence); err != nil {
+			return 0, err
+		}
+	}
+	wasDone := t.status == models.STATUS_DONE
+
+	if req.Title != nil {
+		t.title = *req.Title
+	}
+	if req.Description != nil {
+		t.description = *req.Description
+	}
+	if req.Status != nil {
+		t.status = *req.Status
+	}
+	if req.Priority != nil {
+		t.priority = *req.Priority
+	}
+	if req.DeadlineAt != nil {
+		// same precision the SQLite store keeps
+		d := req.DeadlineAt.Truncate(time.Second)
+		t.deadlineAt = &d
+	}
+	if req.Tags != nil {
+		s.setTaskTags(t, tags)
+	}
+	if req.Recurrence != nil {
+		// the series starts over from the task's (new) deadline
+		t.rrule, t.rruleStart = rrule, nil
+		if rrule != "" {
+			t.rruleStart = copyPtr(t.deadlineAt)
+		}
+	}
+	t.touch()
+
+	// archiving a parent archives its whole subtree
+	if req.Status != nil && *req.Status == models.STATUS_ARCHIVED {
+		for _, tid := range s.descendants(id) {
+			s.tasks[tid].status = models.STATUS_ARCHIVED
+			s.tasks[tid].touch()
+		}
+	}
+
+	// completing a recurring task rolls the series over to a new occurrence
+	if req.Status != nil && *req.Status == models.STATUS_DONE && !wasDone {
+		return s.spawnOccurrence(t), nil
+	}
+	return 0, nil
+}
+
+func (s *MemoryStore) Occurrences(id int64, n int) ([]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	if t.rrule == "" || t.rruleStart == nil || t.deadlineAt == nil {
+		return []time.Time{}, nil
+	}
+	return upcomingOccurrences(t.rrule, *t.rruleStart, *t.deadlineAt, n), nil
+}
+
+// creates the occurrence following a just completed recurring task and
+// hands the rule over to it, 0 when it doesn't repeat or the series ended
+func (s *MemoryStore) spawnOccurrence(t *memTask) int64 {
+	rrule, start := t.rrule, t.rruleStart
+	t.rrule, t.rruleStart = "", nil
+	t.touch()
+	if rrule == "" || start == nil || t.deadlineAt == nil {
+		return 0
+	}
+	next, ok := nextOccurrence(rrule, *start, *t.deadlineAt)
+	if !ok {
+		return 0
+	}
+
+	s.lastID.task++
+	n := &memTask{
+		id:          s.lastID.task,
+		title:       t.title,
+		description: t.description,
+		priority:    t.priority,
+		status:      models.STATUS_PENDING,
+		projectID:   t.projectID,
+		parentID:    copyPtr(t.parentID),
+		createdAt:   now(),
+		updatedAt:   now(),
+		version:     1,
+		deadlineAt:  &next,
+		rrule:       rrule,
+		rruleStart:  start,
+		tagIDs:      map[int64]bool{},
+	}
+	for id := range t.tagIDs {
+		n.tagIDs[id] = true
+	}
+	s.tasks[n.id] = n
+
+	// "1h before" reminders follow the series, absolute ones stay behind
+	for _, r := range s.remindersOf(t.id) {
+		if r.before != nil {
+			s.lastID.reminder++
+			s.reminders[s.lastID.reminder] = &memReminder{id: s.lastID.reminder, taskID: n.id, before: copyPtr(r.before), createdAt: now()}
+		}
+	}
+	return n.id
+}
+
+func (s *MemoryStore) Delete(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[id]; !ok {
+		return ErrTaskNotFound
+	}
+
+	// subtasks go along with their parent, all with the same deleted_at
+	deletedAt := now()
+	for _, tid := range append(s.descendants(id), id) {
+		t := s.tasks[tid]
+		t.deletedAt = &deletedAt
+		t.touch()
+		s.trash[tid] = t
+		delete(s.tasks, tid)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Move(id int64, parentID *int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+
+	if parentID == nil {
+		t.parentID = nil
+		t.touch()
+		return nil
+	}
+
+	parent, ok := s.tasks[*parentID]
+	if !ok {
+		return ErrParentNotFound
+	}
+	if *parentID == id || slices.Contains(s.descendants(id), *parentID) {
+		return ErrCycle
+	}
+
+	t.parentID = copyPtr(parentID)
+	t.touch()
+
+	// the subtree follows the new parent into its project
+	s.setSubtreeProject(id, parent.projectID)
+	return nil
+}
+
+func (s *MemoryStore) MoveToProject(id, projectID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+
+	p, ok := s.projects[projectID]
+	if !ok || p.archived {
+		return ErrProjectUnavailable
+	}
+	if t.projectID == projectID {
+		return nil
+	}
+
+	t.parentID = nil
+	s.setSubtreeProject(id, projectID)
+	return nil
+}
+
+func (s *MemoryStore) PutTask(v models.GetTasksResponse) error {
+	tags, err := NormalizeTagNames(v.Tags)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := int64(v.TaskID)
+	t, ok := s.tasks[id]
+	if !ok {
+		if t, ok = s.trash[id]; ok {
+			t.deletedAt = nil
+			delete(s.trash, id)
+		} else {
+			t = &memTask{id: id, createdAt: now()}
+			s.lastID.task = max(s.lastID.task, id)
+		}
+		s.tasks[id] = t
+	}
+
+	// a series keeps its start as long as its rule is the same
+	if v.Recurrence != t.rrule {
+		t.rruleStart = nil
+		if v.Recurrence != "" && v.DeadlineAt != nil {
+			start := v.DeadlineAt.Truncate(time.Second)
+			t.rruleStart = &start
+		}
+	}
+
+	t.title = v.Title
+	t.description = v.Description
+	t.status = v.Status
+	t.priority = v.Priority
+	t.projectID = int64(v.ProjectID)
+	t.parentID = nil
+	if v.ParentTaskID != nil {
+		p := int64(*v.ParentTaskID)
+		t.parentID = &p
+	}
+	t.deadlineAt = nil
+	if v.DeadlineAt != nil {
+		d := v.DeadlineAt.Truncate(time.Second)
+		t.deadlineAt = &d
+	}
+	t.rrule = v.Recurrence
+	t.touch()
+	s.setTaskTags(t, tags)
+	return nil
+}
+
+// ids of every live descendant of a task, breadth first
+func (s *MemoryStore) descendants(id int64) []int64 {
+	return descendantsIn(s.tasks, id)
+}
+
+// ids of every descendant of a task among tasks, breadth first
+func descendantsIn(tasks map[int64]*memTask, id int64) []int64 {
+	sorted := make([]*memTask, 0, len(tasks))
+	for _, t := range tasks {
+		sorted = append(sorted, t)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].id < sorted[j].id })
+
+	var ids []int64
+	seen := map[int64]bool{id: true}
+	queue := []int64{id}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, t := range sorted {
+			if t.parentID != nil && *t.parentID == parent && !seen[t.id] {
+				seen[t.id] = true
+				ids = append(ids, t.id)
+				queue = append(queue, t.id)
+			}
+		}
+	}
+	return ids
+}
+
+func (s *MemoryStore) setSubtreeProject(id, projectID int64) {
+	for _, tid := range append(s.descendants(id), id) {
+		s.tasks[tid].projectID = projectID
+		s.tasks[tid].touch()
+	}
+}
+
+// replaces the tag set of a task, unknown tag names are created on the fly
+func (s *MemoryStore) setTaskTags(t *memTask, names []string) {
+	t.tagIDs = map[int64]bool{}
+	for _, name := range names {
+		g := s.tagByName(name)
+		if g == nil {
+			s.lastID.tag++
+			g = &memTag{id: s.lastID.tag, name: name, createdAt: now()}
+			s.tags[g.id] = g
+		}
+		t.tagIDs[g.id] = true
+	}
+}
+
+func (s *MemoryStore) tagByName(name string) *memTag {
+	for _, g := range s.tags {
+		if strings.EqualFold(g.name, name) {
+			return g
+		}
+	}
+	return nil
+}
+
+// builds the API view of a task, mirroring the columns the SQLite store selects
+func (s *MemoryStore) view(t *memTask) models.GetTasksResponse {
+	v := models.GetTasksResponse{
+		TaskID:      int(t.id),
+		Title:       t.title,
+		Description: t.description,
+		Status:      t.status,
+		Priority:    t.priority,
+		ProjectID:   int(t.projectID),
+		Tags:        []string{},
+		CreatedAt:   t.createdAt,
+		UpdatedAt:   t.updatedAt,
+		Version:     t.version,
+		DeadlineAt:  copyPtr(t.deadlineAt),
+		Recurrence:  t.rrule,
+		DeletedAt:   copyPtr(t.deletedAt),
+	}
+	if t.parentID != nil {
+		p := int(*t.parentID)
+		v.ParentTaskID = &p
+	}
+
+	for id := range t.tagIDs {
+		v.Tags = append(v.Tags, s.tags[id].name)
+	}
+	sort.Slice(v.Tags, func(i, j int) bool { return strings.ToLower(v.Tags[i]) < strings.ToLower(v.Tags[j]) })
+
+	var progress models.TaskProgress
+	for _, c := range s.tasks {
+		if c.parentID == nil || *c.parentID != t.id || c.status == models.STATUS_ARCHIVED {
+			continue
+		}
+		progress.Total++
+		if c.status == models.STATUS_DONE {
+			progress.Done++
+		}
+	}
+	if progress.Total > 0 {
+		v.Progress = &progress
+	}
+	return v
+}
+
+// second precision in UTC, like CURRENT_TIMESTAMP
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"slices"
+	"time"
+)
+
+type memOperation struct {
+	id        int64
+	session   string
+	kind      string
+	state     string
+	createdAt time.Time
+}
+
+// appends entries to the log, the lock is held by the caller
+func (s *MemoryStore) appendActivity(opID int64, via string, entries []models.Activity) {
+	for _, a := range entries {
+		s.lastID.activity++
+		a.ActivityID = s.lastID.activity
+		a.Changes = slices.Clone(a.Changes)
+		a.OperationID = opID
+		a.Via = via
+		a.CreatedAt = now()
+		s.activity = append(s.activity, a)
+	}
+}
+
+func (s *MemoryStore) RecordOperation(session, kind string, entries []models.Activity) (int64, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session != "" {
+		for _, op := range s.operations {
+			if op.session == session && op.state == models.OP_UNDONE {
+				op.state = models.OP_DISCARDED
+			}
+		}
+	}
+	s.lastID.operation++
+	op := &memOperation{id: s.lastID.operation, session: session, kind: kind, state: models.OP_DONE, createdAt: now()}
+	s.operations = append(s.operations, op)
+	s.appendActivity(op.id, "", entries)
+	return op.id, nil
+}
+
+func (s *MemoryStore) SessionOperations(session, state string, n int) ([]models.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var picked []*memOperation
+	for _, op := range s.operations {
+		if op.session == session && op.state == state {
+			picked = append(picked, op)
+		}
+	}
+	if state != models.OP_UNDONE {
+		slices.Reverse(picked)
+	}
+	if len(picked) > n {
+		picked = picked[:n]
+	}
+
+	ops := []models.Operation{}
+	for _, op := range picked {
+		o := models.Operation{
+			OperationID: op.id,
+			Kind:        op.kind,
+			State:       op.state,
+			Changes:     []models.Activity{},
+			CreatedAt:   op.createdAt,
+		}
+		for _, a := range s.activity {
+			if a.OperationID == op.id && a.Via == "" {
+				a.Changes = slices.Clone(a.Changes)
+				o.Changes = append(o.Changes, a)
+			}
+		}
+		ops = append(ops, o)
+	}
+	return ops, nil
+}
+
+func (s *MemoryStore) SetOperationState(id int64, state string, entries []models.Activity) error {
+	via := models.VIA_UNDO
+	if state == models.OP_DONE {
+		via = models.VIA_REDO
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, op := range s.operations {
+		if op.id == id {
+			op.state = state
+		}
+	}
+	s.appendActivity(id, via, entries)
+	return nil
+}
+
+func (s *MemoryStore) ListActivity(taskID int64, p Page) ([]models.Activity, string, error) {
+	before, err := activityCursor(p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := []models.Activity{}
+	for i := len(s.activity) - 1; i >= 0; i-- {
+		a := s.activity[i]
+		if (taskID != 0 && a.TaskID != taskID) || (before != 0 && a.ActivityID >= before) {
+			continue
+		}
+		if p.Limit > 0 && len(entries) == p.Limit {
+			return entries, nextActivityCursor(entries[len(entries)-1].ActivityID), nil
+		}
+		a.Changes = slices.Clone(a.Changes)
+		entries = append(entries, a)
+	}
+	return entries, "", nil
+}
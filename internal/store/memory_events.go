@@ -0,0 +1,52 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"time"
+)
+
+func (s *MemoryStore) AppendEvent(typ string, task models.GetTasksResponse) (models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID.event++
+	e := models.Event{
+		EventID:   s.lastID.event,
+		Type:      typ,
+		TaskID:    int64(task.TaskID),
+		Task:      task,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	s.events = append(s.events, e)
+	if len(s.events) > EventLogSize {
+		s.events = append([]models.Event(nil), s.events[len(s.events)-EventLogSize:]...)
+	}
+	return e, nil
+}
+
+func (s *MemoryStore) EventsAfter(id int64, limit int) ([]models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.events) > 0 && id < s.events[0].EventID-1 {
+		return nil, ErrEventsPruned
+	}
+
+	events := []models.Event{}
+	for _, e := range s.events {
+		if len(events) == limit {
+			break
+		}
+		if e.EventID > id {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) LastEventID() (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastID.event, nil
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"sort"
+	"strings"
+)
+
+func (s *MemoryStore) ListProjects(includeArchived bool) ([]models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, p := range s.projects {
+		if p.archived && !includeArchived {
+			continue
+		}
+		projects = append(projects, s.projectView(p))
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ProjectID < projects[j].ProjectID })
+	return projects, nil
+}
+
+func (s *MemoryStore) GetProject(id int64) (models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[id]
+	if !ok {
+		return models.Project{}, ErrProjectNotFound
+	}
+	return s.projectView(p), nil
+}
+
+func (s *MemoryStore) CreateProject(req models.CreateProjectRequest) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.projectNameTaken(req.Name, 0) {
+		return 0, ErrProjectExists
+	}
+
+	s.lastID.project++
+	s.projects[s.lastID.project] = &memProject{
+		id:              s.lastID.project,
+		name:            req.Name,
+		color:           req.Color,
+		defaultPriority: req.DefaultPriority,
+		createdAt:       now(),
+	}
+	return s.lastID.project, nil
+}
+
+func (s *MemoryStore) UpdateProject(id int64, req models.UpdateProjectRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[id]
+	if !ok {
+		return ErrProjectNotFound
+	}
+
+	if req.Name != nil && s.projectNameTaken(*req.Name, id) {
+		return ErrProjectExists
+	}
+	if req.Archived != nil && *req.Archived && id == models.PROJECT_INBOX {
+		return ErrInboxProtected
+	}
+
+	if req.Name != nil {
+		p.name = *req.Name
+	}
+	if req.Color != nil {
+		p.color = *req.Color
+	}
+	if req.DefaultPriority != nil {
+		p.defaultPriority = *req.DefaultPriority
+	}
+	if req.Archived != nil {
+		p.archived = *req.Archived
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteProject(id int64) error {
+	if id == models.PROJECT_INBOX {
+		return ErrInboxProtected
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[id]; !ok {
+		return ErrProjectNotFound
+	}
+
+	for _, tasks := range []map[int64]*memTask{s.tasks, s.trash} {
+		for _, t := range tasks {
+			if t.projectID == id {
+				t.projectID = models.PROJECT_INBOX
+				t.touch()
+			}
+		}
+	}
+	delete(s.projects, id)
+	return nil
+}
+
+// reports whether another project (other than exclude) already has this name
+func (s *MemoryStore) projectNameTaken(name string, exclude int64) bool {
+	for _, p := range s.projects {
+		if p.id != exclude && strings.EqualFold(p.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) projectView(p *memProject) models.Project {
+	project := models.Project{
+		ProjectID:       int(p.id),
+		Name:            p.name,
+		Color:           p.color,
+		DefaultPriority: p.defaultPriority,
+		Archived:        p.archived,
+		CreatedAt:       p.createdAt,
+	}
+	for _, t := range s.tasks {
+		if t.projectID == p.id {
+			project.TaskCount++
+		}
+	}
+	return project
+}
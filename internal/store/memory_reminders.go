@@ -0,0 +1,127 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"sort"
+	"time"
+)
+
+type memReminder struct {
+	id           int64
+	taskID       int64
+	before       *time.Duration
+	at           *time.Time
+	deliveredFor *time.Time
+	deliveredAt  *time.Time
+	createdAt    time.Time
+}
+
+func (s *MemoryStore) ListReminders(taskID int64) ([]models.Reminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tasks[taskID]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+
+	reminders := []models.Reminder{}
+	for _, r := range s.remindersOf(taskID) {
+		v := models.Reminder{
+			ReminderID:  r.id,
+			TaskID:      r.taskID,
+			DeliveredAt: copyPtr(r.deliveredAt),
+			CreatedAt:   r.createdAt,
+		}
+		reminders = append(reminders, reminderView(v, r.before, r.at, t.deadlineAt))
+	}
+	return reminders, nil
+}
+
+func (s *MemoryStore) CreateReminder(taskID int64, req models.CreateReminderRequest) (int64, error) {
+	before, at, err := parseReminder(req)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[taskID]; !ok {
+		return 0, ErrTaskNotFound
+	}
+
+	s.lastID.reminder++
+	s.reminders[s.lastID.reminder] = &memReminder{
+		id:        s.lastID.reminder,
+		taskID:    taskID,
+		before:    before,
+		at:        at,
+		createdAt: now(),
+	}
+	return s.lastID.reminder, nil
+}
+
+func (s *MemoryStore) DeleteReminder(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reminders[id]; !ok {
+		return ErrReminderNotFound
+	}
+	delete(s.reminders, id)
+	return nil
+}
+
+func (s *MemoryStore) PendingReminders(before time.Time) ([]models.DueReminder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	due := []models.DueReminder{}
+	for _, r := range s.reminders {
+		t, ok := s.tasks[r.taskID]
+		if !ok || t.status == models.STATUS_DONE || t.status == models.STATUS_ARCHIVED {
+			continue
+		}
+
+		fireAt := reminderFireAt(r.before, r.at, t.deadlineAt)
+		if !reminderPending(fireAt, r.deliveredFor) || !fireAt.Before(before) {
+			continue
+		}
+		due = append(due, models.DueReminder{
+			ReminderID: r.id,
+			TaskID:     t.id,
+			Title:      t.title,
+			DeadlineAt: copyPtr(t.deadlineAt),
+			FireAt:     *fireAt,
+		})
+	}
+
+	sortDue(due)
+	return due, nil
+}
+
+func (s *MemoryStore) MarkReminderDelivered(id int64, fireAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reminders[id]
+	if !ok {
+		return ErrReminderNotFound
+	}
+	delivered := now()
+	r.deliveredFor, r.deliveredAt = &fireAt, &delivered
+	return nil
+}
+
+// the reminders of a task in creation order
+func (s *MemoryStore) remindersOf(taskID int64) []*memReminder {
+	var reminders []*memReminder
+	for _, r := range s.reminders {
+		if r.taskID == taskID {
+			reminders = append(reminders, r)
+		}
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].id < reminders[j].id })
+	return reminders
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"html"
+	"queueit/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// words of the snippet window, as in snippet(..., 12) on the SQLite side
+const snippetWords = 12
+
+// a word of a task's text along with where it sits in the text
+type memToken struct {
+	word       string
+	start, end int
+}
+
+// a matched stretch of text, in tokens
+type memSpan struct{ from, to int }
+
+// approximates the SQLite search: ranks by weighted match counts rather than
+// bm25 and doesn't fold diacritics, "cafe" won't find "café" here
+func (s *MemoryStore) Search(query string, f TaskFilter, p Page) ([]models.SearchResult, string, error) {
+	terms, err := parseSearch(query)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(p.Sort) == 0 {
+		p.Sort = []SortKey{{Field: "rank"}}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// every term has to match the title or the description, title matches
+	// count ten times as much like the bm25 weights
+	rank := func(t *memTask) (float64, bool) {
+		title, desc := memTokens(t.title), memTokens(t.description)
+		score := 0
+		for _, term := range terms {
+			inTitle, inDesc := len(matchTerm(title, term)), len(matchTerm(desc, term))
+			if inTitle+inDesc == 0 {
+				return 0, false
+			}
+			score += 10*inTitle + inDesc
+		}
+		return -float64(score), true
+	}
+
+	rows, next, err := s.page(s.tasks, f, p, rank)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results := []models.SearchResult{}
+	for _, r := range rows {
+		title, desc := memTokens(r.t.title), memTokens(r.t.description)
+		titleSpans, descSpans := matchTerms(title, terms), matchTerms(desc, terms)
+		results = append(results, models.SearchResult{
+			GetTasksResponse: s.view(r.t),
+			Rank:             r.rank,
+			Highlight:        markSpans(r.t.title, title, titleSpans, 0, len(title)),
+			Snippet:          snippet(r.t.description, desc, descSpans),
+		})
+	}
+	return results, next, nil
+}
+
+func memTokens(text string) []memToken {
+	var tokens []memToken
+	start := -1
+	for i, r := range text + " " {
+		inWord := unicode.IsLetter(r) || unicode.IsNumber(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, memToken{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// every place a term matches, a phrase has to match word for word
+func matchTerm(tokens []memToken, term searchTerm) []memSpan {
+	var spans []memSpan
+	for i := 0; i+len(term.words) <= len(tokens); i++ {
+		matched := true
+		for j, w := range term.words {
+			last := j == len(term.words)-1
+			if tok := tokens[i+j].word; !(tok == w || (last && term.prefix && strings.HasPrefix(tok, w))) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			spans = append(spans, memSpan{from: i, to: i + len(term.words) - 1})
+		}
+	}
+	return spans
+}
+
+// the matches of every term, sorted and with overlapping ones merged
+func matchTerms(tokens []memToken, terms []searchTerm) []memSpan {
+	var spans []memSpan
+	for _, term := range terms {
+		spans = append(spans, matchTerm(tokens, term)...)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].from < spans[j].from })
+
+	var merged []memSpan
+	for _, sp := range spans {
+		if n := len(merged); n > 0 && sp.from <= merged[n-1].to {
+			merged[n-1].to = max(merged[n-1].to, sp.to)
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return merged
+}
+
+// the text of tokens[from:to] as HTML, with the spans wrapped in <mark>
+func markSpans(text string, tokens []memToken, spans []memSpan, from, to int) string {
+	if len(tokens) == 0 {
+		return html.EscapeString(text)
+	}
+
+	var b strings.Builder
+	pos := tokens[from].start
+	if from == 0 {
+		pos = 0
+	}
+	for _, sp := range spans {
+		if sp.to < from || sp.from >= to {
+			continue
+		}
+		first, last := max(sp.from, from), min(sp.to, to-1)
+		b.WriteString(html.EscapeString(text[pos:tokens[first].start]))
+		b.WriteString("<mark>" + html.EscapeString(text[tokens[first].start:tokens[last].end]) + "</mark>")
+		pos = tokens[last].end
+	}
+
+	end := len(text)
+	if to < len(tokens) {
+		end = tokens[to-1].end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	return b.String()
+}
+
+// a window of snippetWords words starting at the first match
+func snippet(text string, tokens []memToken, spans []memSpan) string {
+	if len(tokens) <= snippetWords {
+		return markSpans(text, tokens, spans, 0, len(tokens))
+	}
+
+	from := 0
+	if len(spans) > 0 {
+		from = min(spans[0].from, len(tokens)-snippetWords)
+	}
+	to := from + snippetWords
+
+	out := markSpans(text, tokens, spans, from, to)
+	if from > 0 {
+		out = "…" + out
+	}
+	if to < len(tokens) {
+		out += "…"
+	}
+	return out
+}
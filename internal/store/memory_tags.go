@@ -0,0 +1,104 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"sort"
+	"strings"
+)
+
+func (s *MemoryStore) ListTags() ([]models.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tags := []models.Tag{}
+	for _, g := range s.tags {
+		tags = append(tags, s.tagView(g))
+	}
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name) })
+	return tags, nil
+}
+
+func (s *MemoryStore) GetTag(id int64) (models.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g, ok := s.tags[id]
+	if !ok {
+		return models.Tag{}, ErrTagNotFound
+	}
+	return s.tagView(g), nil
+}
+
+func (s *MemoryStore) CreateTag(req models.CreateTagRequest) (int64, error) {
+	name, err := NormalizeTagName(req.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tagByName(name) != nil {
+		return 0, ErrTagExists
+	}
+
+	s.lastID.tag++
+	s.tags[s.lastID.tag] = &memTag{id: s.lastID.tag, name: name, color: req.Color, createdAt: now()}
+	return s.lastID.tag, nil
+}
+
+func (s *MemoryStore) UpdateTag(id int64, req models.UpdateTagRequest) error {
+	var name string
+	if req.Name != nil {
+		var err error
+		if name, err = NormalizeTagName(*req.Name); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.tags[id]
+	if !ok {
+		return ErrTagNotFound
+	}
+
+	if req.Name != nil {
+		if other := s.tagByName(name); other != nil && other.id != id {
+			return ErrTagExists
+		}
+		g.name = name
+	}
+	if req.Color != nil {
+		g.color = *req.Color
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteTag(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[id]; !ok {
+		return ErrTagNotFound
+	}
+
+	for _, tasks := range []map[int64]*memTask{s.tasks, s.trash} {
+		for _, t := range tasks {
+			delete(t.tagIDs, id)
+		}
+	}
+	delete(s.tags, id)
+	return nil
+}
+
+func (s *MemoryStore) tagView(g *memTag) models.Tag {
+	tag := models.Tag{TagID: int(g.id), Name: g.name, Color: g.color, CreatedAt: g.createdAt}
+	for _, t := range s.tasks {
+		if t.tagIDs[g.id] {
+			tag.TaskCount++
+		}
+	}
+	return tag
+}
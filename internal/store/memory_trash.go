@@ -0,0 +1,97 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"time"
+)
+
+func (s *MemoryStore) ListTrash(f TaskFilter, p Page) ([]models.GetTasksResponse, string, error) {
+	// most recently deleted first unless p.Sort says otherwise
+	if len(p.Sort) == 0 {
+		p.Sort = []SortKey{{Field: "deleted_at", Desc: true}}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, next, err := s.page(s.trash, f, p, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks := []models.GetTasksResponse{}
+	for _, r := range rows {
+		tasks = append(tasks, s.view(r.t))
+	}
+	return tasks, next, nil
+}
+
+func (s *MemoryStore) Restore(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.trash[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	if t.parentID != nil {
+		if _, ok := s.tasks[*t.parentID]; !ok {
+			return ErrParentTrashed
+		}
+	}
+	if p, ok := s.projects[t.projectID]; !ok || p.archived {
+		return ErrProjectUnavailable
+	}
+
+	// the subtasks deleted along with the task, not the ones deleted before
+	deletedAt := *t.deletedAt
+	for _, tid := range append(descendantsIn(s.trash, id), id) {
+		c := s.trash[tid]
+		if c.deletedAt.Equal(deletedAt) {
+			c.deletedAt = nil
+			c.touch()
+			s.tasks[tid] = c
+			delete(s.trash, tid)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Purge(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.trash[id]; !ok {
+		return ErrTaskNotFound
+	}
+	s.purge(append(descendantsIn(s.trash, id), id))
+	return nil
+}
+
+func (s *MemoryStore) PurgeTrash(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the subtasks of a deleted task were deleted no later than it was
+	var ids []int64
+	for id, t := range s.trash {
+		if t.deletedAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	s.purge(ids)
+	return len(ids), nil
+}
+
+// deletes trashed tasks for good along with their reminders, the lock is
+// held by the caller
+func (s *MemoryStore) purge(ids []int64) {
+	for _, id := range ids {
+		delete(s.trash, id)
+		for rid, r := range s.reminders {
+			if r.taskID == id {
+				delete(s.reminders, rid)
+			}
+		}
+	}
+}
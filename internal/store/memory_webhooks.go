@@ -0,0 +1,303 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"slices"
+	"sort"
+	"time"
+)
+
+type memWebhook struct {
+	id        int64
+	url       string
+	secret    string
+	events    []string
+	active    bool
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type memDelivery struct {
+	id             int64
+	webhookID      int64
+	eventID        int64
+	eventType      string
+	payload        []byte
+	status         string
+	attempts       int
+	nextAttemptAt  *time.Time
+	lastStatusCode int
+	lastError      string
+	redeliveryOf   *int64
+	createdAt      time.Time
+	deliveredAt    *time.Time
+}
+
+func (w *memWebhook) view() models.Webhook {
+	return models.Webhook{
+		WebhookID: w.id,
+		URL:       w.url,
+		Events:    slices.Clone(w.events),
+		Active:    w.active,
+		CreatedAt: w.createdAt,
+		UpdatedAt: w.updatedAt,
+	}
+}
+
+func (s *MemoryStore) ListWebhooks() ([]models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, w := range s.webhooks {
+		webhooks = append(webhooks, w.view())
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].WebhookID < webhooks[j].WebhookID })
+	return webhooks, nil
+}
+
+func (s *MemoryStore) GetWebhook(id int64) (models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	w, ok := s.webhooks[id]
+	if !ok {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return w.view(), nil
+}
+
+func (s *MemoryStore) CreateWebhook(req models.CreateWebhookRequest) (int64, string, error) {
+	req, generated, err := parseCreateWebhook(req)
+	if err != nil {
+		return 0, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	s.lastID.webhook++
+	s.webhooks[s.lastID.webhook] = &memWebhook{
+		id:        s.lastID.webhook,
+		url:       req.URL,
+		secret:    req.Secret,
+		events:    req.Events,
+		active:    req.Active == nil || *req.Active,
+		createdAt: now,
+		updatedAt: now,
+	}
+	return s.lastID.webhook, generated, nil
+}
+
+func (s *MemoryStore) UpdateWebhook(id int64, req models.UpdateWebhookRequest) error {
+	req, err := parseUpdateWebhook(req)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.webhooks[id]
+	if !ok {
+		return ErrWebhookNotFound
+	}
+	if req.URL != nil {
+		w.url = *req.URL
+	}
+	if req.Secret != nil {
+		w.secret = *req.Secret
+	}
+	if req.Events != nil {
+		w.events = *req.Events
+	}
+	if req.Active != nil {
+		w.active = *req.Active
+	}
+	w.updatedAt = time.Now().UTC().Truncate(time.Second)
+	return nil
+}
+
+func (s *MemoryStore) DeleteWebhook(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	for did, d := range s.deliveries {
+		if d.webhookID == id {
+			delete(s.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) WebhookCursor() (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.webhookCursor, nil
+}
+
+func (s *MemoryStore) QueueDeliveries(e models.Event) error {
+	payload, err := webhookPayload(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queued := map[int64]bool{}
+	for _, d := range s.deliveries {
+		if d.eventID == e.EventID && d.redeliveryOf == nil {
+			queued[d.webhookID] = true
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, w := range s.webhooks {
+		if !w.active || !webhookWants(w.events, e.Type) || queued[w.id] {
+			continue
+		}
+		s.lastID.delivery++
+		s.deliveries[s.lastID.delivery] = &memDelivery{
+			id:            s.lastID.delivery,
+			webhookID:     w.id,
+			eventID:       e.EventID,
+			eventType:     e.Type,
+			payload:       payload,
+			status:        models.DELIVERY_PENDING,
+			nextAttemptAt: &now,
+			createdAt:     now,
+		}
+	}
+
+	s.webhookCursor = max(s.webhookCursor, e.EventID)
+	return nil
+}
+
+func (s *MemoryStore) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]models.DueDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []*memDelivery
+	for _, d := range s.deliveries {
+		w := s.webhooks[d.webhookID]
+		if d.status == models.DELIVERY_PENDING && !d.nextAttemptAt.After(now) && w != nil && w.active {
+			pending = append(pending, d)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].nextAttemptAt.Equal(*pending[j].nextAttemptAt) {
+			return pending[i].nextAttemptAt.Before(*pending[j].nextAttemptAt)
+		}
+		return pending[i].id < pending[j].id
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+
+	leased := now.Add(lease)
+	due := []models.DueDelivery{}
+	for _, d := range pending {
+		w := s.webhooks[d.webhookID]
+		due = append(due, models.DueDelivery{
+			DeliveryID: d.id,
+			WebhookID:  d.webhookID,
+			EventType:  d.eventType,
+			URL:        w.url,
+			Secret:     w.secret,
+			Payload:    slices.Clone(d.payload),
+			Attempts:   d.attempts,
+		})
+		d.nextAttemptAt = &leased
+	}
+	return due, nil
+}
+
+func (s *MemoryStore) RecordAttempt(deliveryID int64, a models.DeliveryAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[deliveryID]
+	if !ok {
+		return ErrDeliveryNotFound
+	}
+	d.status, d.nextAttemptAt, d.deliveredAt = attemptOutcome(a, time.Now().UTC().Truncate(time.Second))
+	d.nextAttemptAt = copyPtr(d.nextAttemptAt)
+	d.attempts++
+	d.lastStatusCode = a.StatusCode
+	d.lastError = a.Error
+	return nil
+}
+
+func (s *MemoryStore) ListDeliveries(webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	for _, d := range s.deliveries {
+		if d.webhookID != webhookID {
+			continue
+		}
+		v := models.WebhookDelivery{
+			DeliveryID:     d.id,
+			WebhookID:      d.webhookID,
+			EventID:        d.eventID,
+			EventType:      d.eventType,
+			Status:         d.status,
+			Attempts:       d.attempts,
+			LastStatusCode: d.lastStatusCode,
+			LastError:      d.lastError,
+			RedeliveryOf:   copyPtr(d.redeliveryOf),
+			Payload:        slices.Clone(d.payload),
+			CreatedAt:      d.createdAt,
+			DeliveredAt:    copyPtr(d.deliveredAt),
+		}
+		if d.status == models.DELIVERY_PENDING {
+			v.NextAttemptAt = copyPtr(d.nextAttemptAt)
+		}
+		deliveries = append(deliveries, v)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].DeliveryID > deliveries[j].DeliveryID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *MemoryStore) Redeliver(webhookID, deliveryID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return 0, ErrWebhookNotFound
+	}
+	orig, ok := s.deliveries[deliveryID]
+	if !ok || orig.webhookID != webhookID {
+		return 0, ErrDeliveryNotFound
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	s.lastID.delivery++
+	s.deliveries[s.lastID.delivery] = &memDelivery{
+		id:            s.lastID.delivery,
+		webhookID:     webhookID,
+		eventID:       orig.eventID,
+		eventType:     orig.eventType,
+		payload:       orig.payload,
+		status:        models.DELIVERY_PENDING,
+		nextAttemptAt: &now,
+		redeliveryOf:  &orig.id,
+		createdAt:     now,
+	}
+	return s.lastID.delivery, nil
+}
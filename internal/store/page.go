@@ -0,0 +1,135 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("cursor is malformed or was issued for a different sort order")
+
+// one list key, "-priority" parses to {Field: "priority", Desc: true}
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// cursor based paging shared by every list endpoint, a zero Limit means
+// no limit and a blank Cursor starts from the first row
+type Page struct {
+	Limit  int
+	Cursor string
+	Sort   []SortKey
+}
+
+// parses "-priority,deadline_at" against the sortable fields of a list
+func ParseSort(spec string, allowed map[string]bool) ([]SortKey, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	var keys []SortKey
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		key := SortKey{Field: strings.TrimSpace(part)}
+		if strings.HasPrefix(key.Field, "-") {
+			key.Field, key.Desc = key.Field[1:], true
+		}
+		if !allowed[key.Field] {
+			return nil, fmt.Errorf("cannot sort by %q", key.Field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("%q appears more than once", key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func sortSpec(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.Field
+		if k.Desc {
+			parts[i] = "-" + k.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// what a cursor carries: the sort it was issued for and the sort key
+// values of the last row handed out, the next page starts right after it
+type cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+func encodeCursor(keys []SortKey, values []any) string {
+	b, _ := json.Marshal(cursor{Sort: sortSpec(keys), Values: values})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodes a cursor, it must have been issued for the same sort keys
+func decodeCursor(s string, keys []SortKey, nvalues int) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sortSpec(keys) || len(c.Values) != nvalues {
+		return nil, ErrInvalidCursor
+	}
+	return c.Values, nil
+}
+
+// one SQL expression a list can be ordered by, the cursor carries its value
+type orderExpr struct {
+	expr string
+	desc bool
+}
+
+// appends the keyset condition (rows strictly after values) and the ORDER
+// BY/LIMIT for exprs, the last expr has to be unique to make paging stable
+func (q *selectQuery) paginate(exprs []orderExpr, values []any, limit int) (string, []any) {
+	if values != nil {
+		var ors []string
+		var args []any
+		for i, e := range exprs {
+			var ands []string
+			for j := 0; j < i; j++ {
+				ands = append(ands, fmt.Sprintf("%s = ?", exprs[j].expr))
+				args = append(args, values[j])
+			}
+			op := ">"
+			if e.desc {
+				op = "<"
+			}
+			ands = append(ands, fmt.Sprintf("%s %s ?", e.expr, op))
+			args = append(args, values[i])
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+		q.where("("+strings.Join(ors, " OR ")+")", args...)
+	}
+
+	query, args := q.build()
+
+	order := make([]string, len(exprs))
+	for i, e := range exprs {
+		order[i] = e.expr
+		if e.desc {
+			order[i] += " DESC"
+		}
+	}
+	query += " ORDER BY " + strings.Join(order, ", ")
+
+	if limit > 0 {
+		// one extra row tells whether there is a next page
+		query += " LIMIT ?"
+		args = append(args, limit+1)
+	}
+	return query, args
+}
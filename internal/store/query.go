@@ -0,0 +1,59 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// assembles a SELECT from ANDed conditions, every value travels as a
+// bound parameter and never gets spliced into the SQL text
+type selectQuery struct {
+	base  string
+	conds []string
+	args  []any
+}
+
+func newSelect(base string, args ...any) *selectQuery {
+	return &selectQuery{base: base, args: args}
+}
+
+// adds a condition, its '?' placeholders are bound to args in order
+func (q *selectQuery) where(cond string, args ...any) *selectQuery {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// adds "expr IN (?,?,...)", an empty list adds nothing
+func whereIn[T any](q *selectQuery, expr string, values []T) *selectQuery {
+	if len(values) == 0 {
+		return q
+	}
+
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return q.where(fmt.Sprintf("%s IN (%s)", expr, placeholders(len(values))), args...)
+}
+
+func (q *selectQuery) build() (string, []any) {
+	query := q.base
+	if len(q.conds) > 0 {
+		query += " WHERE " + strings.Join(q.conds, " AND ")
+	}
+	return query, q.args
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// adds cond with t bound as RFC3339 when t is set, the timestamps stored
+// as RFC3339 and as CURRENT_TIMESTAMP both compare through julianday()
+func whereTime(q *selectQuery, cond string, t *time.Time) {
+	if t != nil {
+		q.where(cond, t.UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"queueit/internal/recur"
+	"time"
+)
+
+// validates a recurrence rule, returning it in canonical form
+func normalizeRecurrence(rule string) (string, error) {
+	r, err := recur.Parse(rule)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// the deadline of the occurrence that follows the one due at deadline,
+// false once the series has run out
+func nextOccurrence(rule string, start, deadline time.Time) (time.Time, bool) {
+	r, err := recur.Parse(rule)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return r.After(start, deadline)
+}
+
+// up to n occurrences following the one due at deadline
+func upcomingOccurrences(rule string, start, deadline time.Time, n int) []time.Time {
+	r, err := recur.Parse(rule)
+	if err != nil {
+		return []time.Time{}
+	}
+	return r.Next(start, deadline, n)
+}
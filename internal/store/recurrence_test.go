@@ -0,0 +1,130 @@
+package store_test
+
+import (
+	"errors"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecurringTaskRollsOver(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		parent, err := s.Create(models.CreateTaskRequest{Title: "garden"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+		id, err := s.Create(models.CreateTaskRequest{
+			Title:        "water plants",
+			Description:  "the ones on the balcony",
+			Priority:     models.PRIORITY_HIGH,
+			ParentTaskID: &parent,
+			Tags:         []string{"home"},
+			DeadlineAt:   &deadline,
+			Recurrence:   "FREQ=MONTHLY;BYDAY=-1FR;COUNT=2",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CreateReminder(id, models.CreateReminderRequest{Before: "1h"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CreateReminder(id, models.CreateReminderRequest{At: ptr(deadline.Add(-24 * time.Hour))}); err != nil {
+			t.Fatal(err)
+		}
+
+		occurrences, err := s.Occurrences(id, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(occurrences) != 1 || !occurrences[0].Equal(time.Date(2026, 2, 27, 9, 0, 0, 0, time.UTC)) {
+			t.Errorf("occurrences = %v, want only Feb 27", occurrences)
+		}
+
+		// finishing the first occurrence brings up the last Friday of February
+		nextID, err := s.Update(id, models.UpdateTaskRequest{Status: ptr(models.STATUS_DONE)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nextID == 0 {
+			t.Fatal("completing the task created no next occurrence")
+		}
+
+		next, err := s.Get(nextID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case next.Title != "water plants" || next.Description != "the ones on the balcony" || next.Priority != models.PRIORITY_HIGH:
+			t.Errorf("next occurrence = %+v, want the fields of the first", next)
+		case next.Status != models.STATUS_PENDING:
+			t.Errorf("next occurrence has status %d, want pending", next.Status)
+		case next.DeadlineAt == nil || !next.DeadlineAt.Equal(occurrences[0]):
+			t.Errorf("next occurrence is due %v, want %v", next.DeadlineAt, occurrences[0])
+		case next.Recurrence != "FREQ=MONTHLY;BYDAY=-1FR;COUNT=2":
+			t.Errorf("next occurrence repeats %q, want the rule of the first", next.Recurrence)
+		case !slices.Equal(next.Tags, []string{"home"}):
+			t.Errorf("next occurrence is tagged %q, want home", next.Tags)
+		case next.ParentTaskID == nil || int64(*next.ParentTaskID) != parent:
+			t.Errorf("next occurrence has parent %v, want %d", next.ParentTaskID, parent)
+		}
+
+		// the rule moved over to the next occurrence
+		done, err := s.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done.Recurrence != "" || done.Status != models.STATUS_DONE {
+			t.Errorf("completed occurrence = %+v, want done without a rule", done)
+		}
+		siblings, err := s.Subtree(parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, c := range siblings {
+			ids = append(ids, c.TaskID)
+		}
+		if !slices.Equal(ids, []int{int(id), int(nextID)}) {
+			t.Errorf("subtasks of the parent = %v, want %d and %d", ids, id, nextID)
+		}
+
+		// only the reminder relative to the deadline came along
+		reminders, err := s.ListReminders(nextID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reminders) != 1 || reminders[0].Before != "1h0m0s" || reminders[0].FireAt == nil ||
+			!reminders[0].FireAt.Equal(occurrences[0].Add(-time.Hour)) {
+			t.Errorf("reminders of the next occurrence = %+v, want one an hour before Feb 27", reminders)
+		}
+
+		// COUNT=2 ends the series with the second occurrence
+		last, err := s.Update(nextID, models.UpdateTaskRequest{Status: ptr(models.STATUS_DONE)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last != 0 {
+			t.Errorf("completing the last occurrence created task %d", last)
+		}
+
+		// completing a done task again rolls nothing over
+		if again, err := s.Update(id, models.UpdateTaskRequest{Status: ptr(models.STATUS_DONE)}); err != nil || again != 0 {
+			t.Errorf("completing a done task again = %d, %v", again, err)
+		}
+	})
+}
+
+func TestRecurrenceNeedsADeadline(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		if _, err := s.Create(models.CreateTaskRequest{Title: "a", Recurrence: "FREQ=DAILY"}); !errors.Is(err, store.ErrRecurrenceDeadline) {
+			t.Errorf("Create without a deadline = %v, want %v", err, store.ErrRecurrenceDeadline)
+		}
+		deadline := time.Now()
+		if _, err := s.Create(models.CreateTaskRequest{Title: "a", DeadlineAt: &deadline, Recurrence: "FREQ=HOURLY"}); !errors.Is(err, store.ErrInvalidRecurrence) {
+			t.Errorf("Create with an invalid rule = %v, want %v", err, store.ErrInvalidRecurrence)
+		}
+	})
+}
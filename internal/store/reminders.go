@@ -0,0 +1,62 @@
+package store
+
+import (
+	"fmt"
+	"queueit/internal/models"
+	"sort"
+	"time"
+)
+
+// validates a reminder request into either an offset before the deadline
+// or an absolute time
+func parseReminder(req models.CreateReminderRequest) (*time.Duration, *time.Time, error) {
+	if (req.Before == "") == (req.At == nil) {
+		return nil, nil, fmt.Errorf("%w: set either before or at", ErrInvalidReminder)
+	}
+	if req.At != nil {
+		at := req.At.Truncate(time.Second)
+		return nil, &at, nil
+	}
+
+	d, err := time.ParseDuration(req.Before)
+	if err != nil || d < 0 {
+		return nil, nil, fmt.Errorf("%w: %q is not a duration like 1h or 15m", ErrInvalidReminder, req.Before)
+	}
+	d = d.Truncate(time.Second)
+	return &d, nil, nil
+}
+
+// when a reminder fires, nil for a relative one on a task without deadline
+func reminderFireAt(before *time.Duration, at, deadline *time.Time) *time.Time {
+	switch {
+	case at != nil:
+		return copyPtr(at)
+	case before != nil && deadline != nil:
+		t := deadline.Add(-*before)
+		return &t
+	}
+	return nil
+}
+
+// a reminder is pending until delivered for its current fire time
+func reminderPending(fireAt, deliveredFor *time.Time) bool {
+	return fireAt != nil && (deliveredFor == nil || !deliveredFor.Equal(*fireAt))
+}
+
+func reminderView(r models.Reminder, before *time.Duration, at, deadline *time.Time) models.Reminder {
+	if before != nil {
+		r.Before = before.String()
+	}
+	r.At = copyPtr(at)
+	r.FireAt = reminderFireAt(before, at, deadline)
+	return r
+}
+
+func sortDue(due []models.DueReminder) {
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].FireAt.Equal(due[j].FireAt) {
+			return due[i].FireAt.Before(due[j].FireAt)
+		}
+		return due[i].ReminderID < due[j].ReminderID
+	})
+}
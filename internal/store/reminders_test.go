@@ -0,0 +1,130 @@
+package store_test
+
+import (
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"testing"
+	"time"
+)
+
+// the ids of the due reminders, in order
+func dueIDs(t *testing.T, s store.Store, before time.Time) []int64 {
+	t.Helper()
+	due, err := s.PendingReminders(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int64{}
+	for _, d := range due {
+		ids = append(ids, d.ReminderID)
+	}
+	return ids
+}
+
+func TestPendingReminders(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		deadline := now.Add(2 * time.Hour)
+		task := func(title string) int64 {
+			t.Helper()
+			id, err := s.Create(models.CreateTaskRequest{Title: title, DeadlineAt: &deadline})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return id
+		}
+		remind := func(taskID int64, req models.CreateReminderRequest) int64 {
+			t.Helper()
+			id, err := s.CreateReminder(taskID, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return id
+		}
+
+		a, b := task("a"), task("b")
+		early := remind(a, models.CreateReminderRequest{Before: "3h"})                  // 11:00
+		late := remind(b, models.CreateReminderRequest{At: ptr(now.Add(-time.Minute))}) // 11:59
+		later := remind(a, models.CreateReminderRequest{Before: "1h"})                  // 13:00
+		undated, err := s.Create(models.CreateTaskRequest{Title: "no deadline"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		remind(undated, models.CreateReminderRequest{Before: "1h"})
+
+		if got := dueIDs(t, s, now); !equalIDs(got, early, late) {
+			t.Errorf("due at noon = %v, want %d and %d", got, early, late)
+		}
+		if got := dueIDs(t, s, now.Add(time.Hour+time.Second)); !equalIDs(got, early, late, later) {
+			t.Errorf("due at 13:00 = %v, want %d, %d and %d", got, early, late, later)
+		}
+
+		due, _ := s.PendingReminders(now)
+		if !due[0].FireAt.Equal(now.Add(-time.Hour)) || due[0].Title != "a" || due[0].DeadlineAt == nil || !due[0].DeadlineAt.Equal(deadline) {
+			t.Errorf("first due reminder = %+v, want task a's at 11:00", due[0])
+		}
+
+		// delivered ones are done with until the deadline moves
+		if err := s.MarkReminderDelivered(early, due[0].FireAt); err != nil {
+			t.Fatal(err)
+		}
+		if got := dueIDs(t, s, now); !equalIDs(got, late) {
+			t.Errorf("due after delivering %d = %v, want %d", early, got, late)
+		}
+		moved := deadline.Add(30 * time.Minute)
+		if _, err := s.Update(a, models.UpdateTaskRequest{DeadlineAt: &moved}); err != nil {
+			t.Fatal(err)
+		}
+		if got := dueIDs(t, s, now); !equalIDs(got, early, late) {
+			t.Errorf("due after moving the deadline = %v, want %d and %d", got, early, late)
+		}
+
+		// nor are those of finished and deleted tasks
+		if _, err := s.Update(a, models.UpdateTaskRequest{Status: ptr(models.STATUS_DONE)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Delete(b); err != nil {
+			t.Fatal(err)
+		}
+		if got := dueIDs(t, s, now.Add(24*time.Hour)); len(got) != 0 {
+			t.Errorf("due with every task done or deleted = %v", got)
+		}
+	})
+}
+
+// a reminder with a time nobody can read neither fires nor keeps the
+// others from firing
+func TestPendingRemindersSkipsBrokenRows(t *testing.T) {
+	s, di := openSQLite(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	id, err := s.Create(models.CreateTaskRequest{Title: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	good, err := s.CreateReminder(id, models.CreateReminderRequest{At: ptr(now.Add(-time.Hour))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SQLite can't read the first, Go can't read the Julian day number
+	for _, at := range []any{"yesterday", 2461330.5} {
+		if _, err := di.E(`INSERT INTO reminders (task_id, remind_at) VALUES (?, ?)`, id, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := dueIDs(t, s, now); !equalIDs(got, good) {
+		t.Errorf("due = %v, want only %d", got, good)
+	}
+}
+
+func equalIDs(got []int64, want ...int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
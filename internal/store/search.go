@@ -0,0 +1,76 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+)
+
+// one term of a search query, a word or a quoted phrase, matching by
+// prefix when it ends in *
+type searchTerm struct {
+	words  []string
+	prefix bool
+}
+
+// splits a query into terms, an unterminated quote runs to the end
+func parseSearch(query string) ([]searchTerm, error) {
+	var terms []searchTerm
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		var text string
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				text, rest = rest[1:], ""
+			} else {
+				text, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t\n\"")
+			if end < 0 {
+				end = len(rest)
+			}
+			text, rest = rest[:end], rest[end:]
+		}
+
+		t := searchTerm{words: searchWords(text)}
+		if strings.HasSuffix(text, "*") || strings.HasPrefix(rest, "*") {
+			t.prefix = true
+			rest = strings.TrimPrefix(rest, "*")
+		}
+		if len(t.words) > 0 {
+			terms = append(terms, t)
+		}
+		rest = strings.TrimLeft(rest, " \t\n")
+	}
+
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearch
+	}
+	return terms, nil
+}
+
+// lowercased runs of letters and digits, the way the FTS5 unicode61
+// tokenizer splits text ("foo-bar" is the phrase "foo bar")
+func searchWords(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return words
+}
+
+// the terms in FTS5 query syntax, every term quoted so no word is ever
+// read as an operator
+func ftsQuery(terms []searchTerm) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = `"` + strings.Join(t.words, " ") + `"`
+		if t.prefix {
+			parts[i] += "*"
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,973 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"queueit/internal/db"
+	"queueit/internal/models"
+	"strings"
+	"time"
+)
+
+// columns selected for every task read, the two counts roll up progress
+// from the direct children (archived and deleted children are left out of
+// the total) and the tag names come back comma-joined in alphabetical order
+const taskColumns = `
+	t.task_id, t.title, t.description, t.priority, t.status, t.project_id, t.parent_task_id, t.created_at, t.updated_at, t.version, t.deadline_at, t.rrule, t.deleted_at,
+	(SELECT COUNT(*) FROM tasksmaster c WHERE c.parent_task_id = t.task_id AND c.status != 4 AND c.deleted_at IS NULL),
+	(SELECT COUNT(*) FROM tasksmaster c WHERE c.parent_task_id = t.task_id AND c.status = 3 AND c.deleted_at IS NULL),
+	(SELECT GROUP_CONCAT(name, ',') FROM (
+		SELECT g.name FROM task_tags tt JOIN tags g ON g.tag_id = tt.tag_id
+		WHERE tt.task_id = t.task_id ORDER BY g.name
+	))
+`
+
+// recursive CTE yielding every descendant id of the task bound to '?'
+// that isn't in the trash, UNION (not UNION ALL) keeps it from looping on
+// a corrupted cycle
+const subtreeCTE = `
+	WITH RECURSIVE subtree(task_id) AS (
+		SELECT task_id FROM tasksmaster WHERE parent_task_id = ? AND deleted_at IS NULL
+		UNION
+		SELECT c.task_id FROM tasksmaster c JOIN subtree s ON c.parent_task_id = s.task_id
+		WHERE c.deleted_at IS NULL
+	)
+`
+
+// subtreeCTE with the deleted descendants as well, for the trash
+const trashSubtreeCTE = `
+	WITH RECURSIVE subtree(task_id) AS (
+		SELECT task_id FROM tasksmaster WHERE parent_task_id = ?
+		UNION
+		SELECT c.task_id FROM tasksmaster c JOIN subtree s ON c.parent_task_id = s.task_id
+	)
+`
+
+// Store backed by the queueit SQLite database
+type SQLiteStore struct {
+	db *db.DBInfo
+}
+
+func NewSQLiteStore(di *db.DBInfo) *SQLiteStore {
+	return &SQLiteStore{db: di}
+}
+
+// schema version of the underlying database, reported by the health check
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	return s.db.SchemaVersion()
+}
+
+// fn gets a store bound to one transaction, the store calls in it become
+// savepoints so a failing one leaves the rest of the transaction alone
+func (s *SQLiteStore) Tx(fn func(tx Store) error) error {
+	return s.db.InTx(func(di *db.DBInfo) error {
+		return fn(NewSQLiteStore(di))
+	})
+}
+
+// runs a change on a store bound to one transaction, so what it checks
+// before writing (a parent exists, no cycle, ...) still holds when it
+// writes; the Begin of the change becomes a savepoint in it
+func (s *SQLiteStore) checked(fn func(s *SQLiteStore) error) error {
+	return s.db.InTx(func(di *db.DBInfo) error {
+		return fn(NewSQLiteStore(di))
+	})
+}
+
+func (s *SQLiteStore) List(f TaskFilter, p Page) ([]models.GetTasksResponse, string, error) {
+	rows, next, err := s.selectTasks("tasksmaster t", nil, f, p, liveTasks)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks := []models.GetTasksResponse{}
+	for _, r := range rows {
+		tasks = append(tasks, r.task)
+	}
+	return tasks, next, nil
+}
+
+// a task along with the extra columns selectTasks was asked for
+type taskRow struct {
+	task  models.GetTasksResponse
+	extra []any
+}
+
+// one page of the tasks in from matching f, the extra columns are selected
+// after taskColumns and match adds conditions of its own, picking live or
+// deleted tasks among them
+func (s *SQLiteStore) selectTasks(from string, extra []string, f TaskFilter, p Page, match func(q *selectQuery)) ([]taskRow, string, error) {
+	exprs := taskOrderExprs(p.Sort)
+	var values []any
+	if p.Cursor != "" {
+		var err error
+		if values, err = decodeCursor(p.Cursor, p.Sort, len(exprs)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	columns := taskColumns
+	for _, c := range extra {
+		columns += ", " + c
+	}
+	for _, e := range exprs {
+		columns += ", " + e.expr
+	}
+
+	q := newSelect(fmt.Sprintf(`SELECT %s FROM %s`, columns, from))
+	match(q)
+	whereIn(q, "t.status", f.Statuses)
+	whereIn(q, "t.priority", f.Priorities)
+
+	if f.ProjectID != 0 {
+		q.where("t.project_id = ?", f.ProjectID)
+	}
+
+	if len(f.Tags) > 0 {
+		tags := newSelect(`SELECT tt.task_id FROM task_tags tt JOIN tags g ON g.tag_id = tt.tag_id`)
+		whereIn(tags, "g.name", f.Tags)
+		sub, args := tags.build()
+
+		// all-of: the task must carry every one of the requested tags
+		if f.AllTags {
+			sub += " GROUP BY tt.task_id HAVING COUNT(DISTINCT tt.tag_id) = ?"
+			args = append(args, len(f.Tags))
+		}
+		q.where(fmt.Sprintf("t.task_id IN (%s)", sub), args...)
+	}
+
+	whereTime(q, "julianday(t.deadline_at) >= julianday(?)", f.DeadlineAfter)
+	whereTime(q, "julianday(t.deadline_at) < julianday(?)", f.DeadlineBefore)
+	whereTime(q, "julianday(t.created_at) >= julianday(?)", f.CreatedAfter)
+	whereTime(q, "julianday(t.updated_at) >= julianday(?)", f.UpdatedAfter)
+	whereTime(q, "julianday(t.updated_at) < julianday(?)", f.UpdatedBefore)
+
+	if f.HasDeadline != nil {
+		if *f.HasDeadline {
+			q.where("t.deadline_at IS NOT NULL")
+		} else {
+			q.where("t.deadline_at IS NULL")
+		}
+	}
+
+	if f.Overdue != nil {
+		overdue := "julianday(t.deadline_at) < julianday('now') AND t.status NOT IN (?, ?)"
+		if !*f.Overdue {
+			overdue = "NOT COALESCE(" + overdue + ", 0)"
+		}
+		q.where(overdue, models.STATUS_DONE, models.STATUS_ARCHIVED)
+	}
+
+	query, args := q.paginate(exprs, values, p.Limit)
+
+	rows, err := s.db.Q(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var page []taskRow
+	var last []any
+	for rows.Next() {
+		if p.Limit > 0 && len(page) == p.Limit {
+			return page, encodeCursor(p.Sort, last), nil
+		}
+
+		r := taskRow{extra: make([]any, len(extra))}
+		keys := make([]any, len(exprs))
+		var dest []any
+		for i := range r.extra {
+			dest = append(dest, &r.extra[i])
+		}
+		for i := range keys {
+			dest = append(dest, &keys[i])
+		}
+		if r.task, err = scanTask(rows, dest...); err != nil {
+			return nil, "", err
+		}
+		page = append(page, r)
+		last = keys
+	}
+	return page, "", rows.Err()
+}
+
+// match conditions of selectTasks
+func liveTasks(q *selectQuery)    { q.where("t.deleted_at IS NULL") }
+func trashedTasks(q *selectQuery) { q.where("t.deleted_at IS NOT NULL") }
+
+// SQL for each sort key, task_id is appended as the tie breaker; none of
+// them yields NULL, tasks without a deadline sort last either way
+func taskOrderExprs(keys []SortKey) []orderExpr {
+	var exprs []orderExpr
+	byID := false
+	for _, k := range keys {
+		var expr string
+		switch k.Field {
+		case "task_id":
+			expr, byID = "t.task_id", true
+		case "title":
+			expr = "t.title COLLATE NOCASE"
+		case "status", "priority", "project_id":
+			expr = "t." + k.Field
+		case "rank":
+			expr = searchRank
+		case "created_at", "updated_at", "deleted_at":
+			expr = "julianday(t." + k.Field + ")"
+		case "deadline_at":
+			expr = "COALESCE(julianday(t.deadline_at), 1e9)"
+			if k.Desc {
+				expr = "COALESCE(julianday(t.deadline_at), -1e9)"
+			}
+		}
+		exprs = append(exprs, orderExpr{expr: expr, desc: k.Desc})
+	}
+	if !byID {
+		exprs = append(exprs, orderExpr{expr: "t.task_id"})
+	}
+	return exprs
+}
+
+func (s *SQLiteStore) Get(id int64) (models.GetTasksResponse, error) {
+	rows, err := s.db.Q(fmt.Sprintf(`SELECT %s FROM tasksmaster t WHERE t.task_id = ? AND t.deleted_at IS NULL`, taskColumns), id)
+	if err != nil {
+		return models.GetTasksResponse{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return models.GetTasksResponse{}, err
+		}
+		return models.GetTasksResponse{}, ErrTaskNotFound
+	}
+	return scanTask(rows)
+}
+
+func (s *SQLiteStore) Subtree(id int64) ([]models.GetTasksResponse, error) {
+	if _, err := s.taskProject(id); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`%s
+		SELECT %s
+		FROM tasksmaster t WHERE t.task_id IN (SELECT task_id FROM subtree)
+		ORDER BY t.task_id
+	`, subtreeCTE, taskColumns)
+
+	rows, err := s.db.Q(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.GetTasksResponse
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return nestSubtree(id, tasks), nil
+}
+
+func (s *SQLiteStore) Create(req models.CreateTaskRequest) (id int64, err error) {
+	err = s.checked(func(s *SQLiteStore) error {
+		id, err = s.create(req)
+		return err
+	})
+	return id, err
+}
+
+func (s *SQLiteStore) create(req models.CreateTaskRequest) (int64, error) {
+	tags, err := NormalizeTagNames(req.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	var rrule any
+	if req.Recurrence != "" {
+		if req.DeadlineAt == nil {
+			return 0, ErrRecurrenceDeadline
+		}
+		if rrule, err = normalizeRecurrence(req.Recurrence); err != nil {
+			return 0, err
+		}
+	}
+
+	// a subtask always lives in its parent's project
+	projectID := int64(models.PROJECT_INBOX)
+	if req.ParentTaskID != nil {
+		parentProject, err := s.taskProject(*req.ParentTaskID)
+		if err == ErrTaskNotFound {
+			return 0, ErrParentNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+		if req.ProjectID != nil && *req.ProjectID != parentProject {
+			return 0, ErrParentProject
+		}
+		projectID = parentProject
+	} else if req.ProjectID != nil {
+		projectID = *req.ProjectID
+	}
+
+	p, err := s.GetProject(projectID)
+	if err == ErrProjectNotFound || (err == nil && p.Archived) {
+		return 0, ErrProjectUnavailable
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if !models.ValidPriorities[req.Priority] {
+		req.Priority = p.DefaultPriority
+	}
+
+	query := `
+		INSERT into tasksmaster
+		(
+			title,
+			description,
+			priority,
+			status,
+			project_id,
+			parent_task_id,
+			deadline_at,
+			rrule,
+			rrule_start
+		)
+		VALUES
+		(
+			?,?,?,?,?,?,?,?,?
+		);
+	`
+
+	// RFC3339 like Update, the deadline sort relies on julianday() reading it
+	var deadline any
+	if req.DeadlineAt != nil {
+		deadline = req.DeadlineAt.Format(time.RFC3339)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	exec_result, err := tx.Exec(
+		query,
+		req.Title,
+		req.Description,
+		req.Priority,
+		models.STATUS_PENDING, // default status
+		projectID,
+		req.ParentTaskID,
+		deadline,
+		rrule,
+		deadline, // the first deadline starts the series
+	)
+	if err != nil {
+		return 0, err
+	}
+	taskID, _ := exec_result.LastInsertId()
+
+	if req.ParentTaskID != nil {
+		if err := linkChild(tx, *req.ParentTaskID, taskID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := setTaskTags(tx, taskID, tags); err != nil {
+		return 0, err
+	}
+
+	return taskID, tx.Commit()
+}
+
+func (s *SQLiteStore) Update(id int64, req models.UpdateTaskRequest) (nextID int64, err error) {
+	err = s.checked(func(s *SQLiteStore) error {
+		nextID, err = s.update(id, req)
+		return err
+	})
+	return nextID, err
+}
+
+func (s *SQLiteStore) update(id int64, req models.UpdateTaskRequest) (int64, error) {
+	// UPDATE tasksmaster SET title = ?, status = ? WHERE task_id = ?
+	var fields []string
+	var args []any
+
+	if req.Title != nil {
+		fields = append(fields, "title = ?")
+		args = append(args, *req.Title)
+	}
+
+	if req.Description != nil {
+		fields = append(fields, "description = ?")
+		args = append(args, *req.Description)
+	}
+
+	if req.Status != nil {
+		fields = append(fields, "status = ?")
+		args = append(args, *req.Status)
+	}
+
+	if req.Priority != nil {
+		fields = append(fields, "priority = ?")
+		args = append(args, *req.Priority)
+	}
+
+	if req.DeadlineAt != nil {
+		fields = append(fields, "deadline_at = ?")
+		args = append(args, req.DeadlineAt.Format(time.RFC3339))
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		var err error
+		if tags, err = NormalizeTagNames(*req.Tags); err != nil {
+			return 0, err
+		}
+		// the tasksmaster_touch trigger only sees changes to the row itself
+		fields = append(fields, "updated_at = CURRENT_TIMESTAMP")
+	}
+
+	cur, err := s.recurrenceOf(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if req.Recurrence != nil {
+		rrule, start := any(nil), any(nil)
+		if *req.Recurrence != "" {
+			// the series starts over from the task's (new) deadline
+			deadline := req.DeadlineAt
+			if deadline == nil {
+				deadline = cur.deadline
+			}
+			if deadline == nil {
+				return 0, ErrRecurrenceDeadline
+			}
+			if rrule, err = normalizeRecurrence(*req.Recurrence); err != nil {
+				return 0, err
+			}
+			start = deadline.Format(time.RFC3339)
+		}
+		fields = append(fields, "rrule = ?", "rrule_start = ?")
+		args = append(args, rrule, start)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if len(fields) > 0 {
+		query := fmt.Sprintf(`UPDATE tasksmaster SET %s WHERE task_id = ?`, strings.Join(fields, ", "))
+		if _, err := tx.Exec(query, append(args, id)...); err != nil {
+			return 0, err
+		}
+	}
+
+	if req.Tags != nil {
+		if err := setTaskTags(tx, id, tags); err != nil {
+			return 0, err
+		}
+	}
+
+	// archiving a parent archives its whole subtree
+	if req.Status != nil && *req.Status == models.STATUS_ARCHIVED {
+		query := subtreeCTE + `
+			UPDATE tasksmaster SET status = ?
+			WHERE task_id IN (SELECT task_id FROM subtree)
+		`
+		if _, err := tx.Exec(query, id, models.STATUS_ARCHIVED); err != nil {
+			return 0, err
+		}
+	}
+
+	// completing a recurring task rolls the series over to a new occurrence
+	var nextID int64
+	if req.Status != nil && *req.Status == models.STATUS_DONE && cur.status != models.STATUS_DONE {
+		if nextID, err = spawnOccurrence(tx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return nextID, tx.Commit()
+}
+
+func (s *SQLiteStore) Occurrences(id int64, n int) ([]time.Time, error) {
+	cur, err := s.recurrenceOf(id)
+	if err != nil {
+		return nil, err
+	}
+	if cur.rrule == "" || cur.start == nil || cur.deadline == nil {
+		return []time.Time{}, nil
+	}
+	return upcomingOccurrences(cur.rrule, *cur.start, *cur.deadline, n), nil
+}
+
+// what Update needs to know about a task before changing it
+type taskRecurrence struct {
+	status   int
+	rrule    string
+	start    *time.Time
+	deadline *time.Time
+}
+
+func (s *SQLiteStore) recurrenceOf(id int64) (taskRecurrence, error) {
+	var r taskRecurrence
+	var rrule, start, deadline sql.NullString
+	rows, err := s.db.Q(`SELECT status, rrule, rrule_start, deadline_at FROM tasksmaster WHERE task_id = ? AND deleted_at IS NULL`, id)
+	if err != nil {
+		return r, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return r, err
+		}
+		return r, ErrTaskNotFound
+	}
+	if err := rows.Scan(&r.status, &rrule, &start, &deadline); err != nil {
+		return r, err
+	}
+
+	r.rrule = rrule.String
+	if r.start, err = parseDeadline(start); err != nil {
+		return r, err
+	}
+	if r.deadline, err = parseDeadline(deadline); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// creates the occurrence following a just completed recurring task and
+// hands the rule over to it, returns 0 when the task doesn't repeat or its
+// series has ended
+func spawnOccurrence(tx *db.Tx, id int64) (int64, error) {
+	var rrule, start, deadline sql.NullString
+	query := `SELECT rrule, rrule_start, deadline_at FROM tasksmaster WHERE task_id = ?`
+	if err := tx.QueryRow(query, id).Scan(&rrule, &start, &deadline); err != nil {
+		return 0, err
+	}
+	if !rrule.Valid {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE tasksmaster SET rrule = NULL, rrule_start = NULL WHERE task_id = ?`, id); err != nil {
+		return 0, err
+	}
+
+	st, err := parseDeadline(start)
+	if err != nil || st == nil {
+		return 0, err
+	}
+	dl, err := parseDeadline(deadline)
+	if err != nil || dl == nil {
+		return 0, err
+	}
+	next, ok := nextOccurrence(rrule.String, *st, *dl)
+	if !ok {
+		return 0, nil
+	}
+
+	query = `
+		INSERT INTO tasksmaster (title, description, priority, status, project_id, parent_task_id, deadline_at, rrule, rrule_start)
+		SELECT title, description, priority, ?, project_id, parent_task_id, ?, ?, ?
+		FROM tasksmaster WHERE task_id = ?
+	`
+	result, err := tx.Exec(query, models.STATUS_PENDING, next.Format(time.RFC3339), rrule.String, start.String, id)
+	if err != nil {
+		return 0, err
+	}
+	nextID, _ := result.LastInsertId()
+
+	query = `INSERT INTO task_tags (task_id, tag_id) SELECT ?, tag_id FROM task_tags WHERE task_id = ?`
+	if _, err := tx.Exec(query, nextID, id); err != nil {
+		return 0, err
+	}
+	query = `INSERT INTO task_children (parent_id, child_id) SELECT parent_id, ? FROM task_children WHERE child_id = ?`
+	if _, err := tx.Exec(query, nextID, id); err != nil {
+		return 0, err
+	}
+	// "1h before" reminders follow the series, absolute ones stay behind
+	query = `INSERT INTO reminders (task_id, offset_seconds) SELECT ?, offset_seconds FROM reminders WHERE task_id = ? AND offset_seconds IS NOT NULL`
+	if _, err := tx.Exec(query, nextID, id); err != nil {
+		return 0, err
+	}
+	return nextID, nil
+}
+
+func (s *SQLiteStore) Delete(id int64) error {
+	// subtasks go along with their parent, all with the same deleted_at
+	query := subtreeCTE + `
+		UPDATE tasksmaster SET deleted_at = CURRENT_TIMESTAMP
+		WHERE deleted_at IS NULL AND (task_id = ? OR task_id IN (SELECT task_id FROM subtree))
+	`
+	result, err := s.db.E(query, id, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Move(id int64, parentID *int64) error {
+	return s.checked(func(s *SQLiteStore) error { return s.move(id, parentID) })
+}
+
+func (s *SQLiteStore) move(id int64, parentID *int64) error {
+	if _, err := s.taskProject(id); err != nil {
+		return err
+	}
+
+	var parentProject int64
+	if parentID != nil {
+		var err error
+		if parentProject, err = s.taskProject(*parentID); err == ErrTaskNotFound {
+			return ErrParentNotFound
+		} else if err != nil {
+			return err
+		}
+
+		cycle, err := s.isInSubtree(id, *parentID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrCycle
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE tasksmaster SET parent_task_id = ? WHERE task_id = ?`, parentID, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM task_children WHERE child_id = ?`, id); err != nil {
+		return err
+	}
+	if parentID != nil {
+		if err := linkChild(tx, *parentID, id); err != nil {
+			return err
+		}
+
+		// the subtree follows the new parent into its project
+		if err := setSubtreeProject(tx, id, parentProject); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) MoveToProject(id, projectID int64) error {
+	return s.checked(func(s *SQLiteStore) error { return s.moveToProject(id, projectID) })
+}
+
+func (s *SQLiteStore) moveToProject(id, projectID int64) error {
+	current, err := s.taskProject(id)
+	if err != nil {
+		return err
+	}
+
+	p, err := s.GetProject(projectID)
+	if err == ErrProjectNotFound || (err == nil && p.Archived) {
+		return ErrProjectUnavailable
+	}
+	if err != nil {
+		return err
+	}
+	if current == projectID {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE tasksmaster SET parent_task_id = NULL WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM task_children WHERE child_id = ?`, id); err != nil {
+		return err
+	}
+	if err := setSubtreeProject(tx, id, projectID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) PutTask(t models.GetTasksResponse) error {
+	tags, err := NormalizeTagNames(t.Tags)
+	if err != nil {
+		return err
+	}
+	id := int64(t.TaskID)
+
+	var deadline, rrule any
+	if t.DeadlineAt != nil {
+		deadline = t.DeadlineAt.Format(time.RFC3339)
+	}
+	if t.Recurrence != "" {
+		rrule = t.Recurrence
+	}
+	var parentID any
+	if t.ParentTaskID != nil {
+		parentID = *t.ParentTaskID
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// a series keeps its start as long as its rule is the same, a rule
+	// coming back starts over from the deadline like Update does
+	result, err := tx.Exec(`
+		UPDATE tasksmaster
+		SET title = ?, description = ?, status = ?, priority = ?, project_id = ?, parent_task_id = ?,
+			deadline_at = ?, rrule_start = CASE WHEN rrule IS ? THEN rrule_start ELSE ? END, rrule = ?,
+			deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE task_id = ?
+	`, t.Title, t.Description, t.Status, t.Priority, t.ProjectID, parentID,
+		deadline, rrule, recurrenceStart(rrule, deadline), rrule, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		if _, err := tx.Exec(`
+			INSERT INTO tasksmaster
+				(task_id, title, description, status, priority, project_id, parent_task_id, deadline_at, rrule, rrule_start)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, t.Title, t.Description, t.Status, t.Priority, t.ProjectID, parentID,
+			deadline, rrule, recurrenceStart(rrule, deadline)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM task_children WHERE child_id = ?`, id); err != nil {
+		return err
+	}
+	if t.ParentTaskID != nil {
+		if err := linkChild(tx, int64(*t.ParentTaskID), id); err != nil {
+			return err
+		}
+	}
+	if err := setTaskTags(tx, id, tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func recurrenceStart(rrule, deadline any) any {
+	if rrule == nil {
+		return nil
+	}
+	return deadline
+}
+
+// fetches the project a task belongs to
+func (s *SQLiteStore) taskProject(id int64) (int64, error) {
+	rows, err := s.db.Q(`SELECT project_id FROM tasksmaster WHERE task_id = ? AND deleted_at IS NULL`, id)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, ErrTaskNotFound
+	}
+	var projectID int64
+	err = rows.Scan(&projectID)
+	return projectID, err
+}
+
+// reports whether candidate is root itself or one of its descendants
+func (s *SQLiteStore) isInSubtree(root, candidate int64) (bool, error) {
+	if root == candidate {
+		return true, nil
+	}
+	return s.exists(subtreeCTE+`SELECT 1 FROM subtree WHERE task_id = ?`, root, candidate)
+}
+
+func (s *SQLiteStore) exists(query string, args ...any) (bool, error) {
+	rows, err := s.db.Q(query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scans one row selected with taskColumns, followed by any extra columns
+func scanTask(rs rowScanner, extra ...any) (models.GetTasksResponse, error) {
+	var t models.GetTasksResponse
+	var parent sql.NullInt64
+	var deadline, rrule, tags sql.NullString
+	var deleted sql.NullTime
+	var total, done int
+	dest := []any{
+		&t.TaskID,
+		&t.Title,
+		&t.Description,
+		&t.Priority,
+		&t.Status,
+		&t.ProjectID,
+		&parent,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.Version,
+		&deadline,
+		&rrule,
+		&deleted,
+		&total,
+		&done,
+		&tags,
+	}
+	if err := rs.Scan(append(dest, extra...)...); err != nil {
+		return t, err
+	}
+
+	if parent.Valid {
+		p := int(parent.Int64)
+		t.ParentTaskID = &p
+	}
+
+	t.Tags = []string{}
+	if tags.Valid {
+		t.Tags = strings.Split(tags.String, ",")
+	}
+
+	var err error
+	if t.DeadlineAt, err = parseDeadline(deadline); err != nil {
+		return t, err
+	}
+	t.Recurrence = rrule.String
+	if deleted.Valid {
+		t.DeletedAt = &deleted.Time
+	}
+
+	if total > 0 {
+		t.Progress = &models.TaskProgress{Done: done, Total: total}
+	}
+	return t, nil
+}
+
+// validate deadline (else NIL)
+func parseDeadline(deadline sql.NullString) (*time.Time, error) {
+	if !deadline.Valid {
+		return nil, nil
+	}
+	ct, err := time.Parse(time.RFC3339, deadline.String)
+	if err != nil {
+		return nil, err
+	}
+	return &ct, nil
+}
+
+// every descendant id of a task, the deleted ones included
+func subtreeIDs(tx *db.Tx, id int64) ([]int64, error) {
+	rows, err := tx.Query(trashSubtreeCTE+`SELECT task_id FROM subtree`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var cid int64
+		if err := rows.Scan(&cid); err != nil {
+			return nil, err
+		}
+		ids = append(ids, cid)
+	}
+	return ids, rows.Err()
+}
+
+func linkChild(tx *db.Tx, parent, child int64) error {
+	_, err := tx.Exec(`INSERT OR IGNORE INTO task_children (parent_id, child_id) VALUES (?, ?)`, parent, child)
+	return err
+}
+
+// moves a task and every descendant into a project
+func setSubtreeProject(tx *db.Tx, id, projectID int64) error {
+	query := subtreeCTE + `
+		UPDATE tasksmaster SET project_id = ?
+		WHERE task_id = ? OR task_id IN (SELECT task_id FROM subtree)
+	`
+	_, err := tx.Exec(query, id, projectID, id)
+	return err
+}
+
+// replaces the tag set of a task, unknown tag names are created on the fly
+func setTaskTags(tx *db.Tx, taskID int64, names []string) error {
+	if _, err := tx.Exec(`DELETE FROM task_tags WHERE task_id = ?`, taskID); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, name); err != nil {
+			return err
+		}
+		query := `
+			INSERT OR IGNORE INTO task_tags (task_id, tag_id)
+			SELECT ?, tag_id FROM tags WHERE name = ?
+		`
+		if _, err := tx.Exec(query, taskID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nests a flat list of descendants of root under their own parents
+func nestSubtree(root int64, tasks []models.GetTasksResponse) []models.GetTasksResponse {
+	byParent := map[int][]models.GetTasksResponse{}
+	for _, t := range tasks {
+		byParent[*t.ParentTaskID] = append(byParent[*t.ParentTaskID], t)
+	}
+
+	visited := map[int]bool{}
+	var attach func(parent int) []models.GetTasksResponse
+	attach = func(parent int) []models.GetTasksResponse {
+		if visited[parent] {
+			return nil
+		}
+		visited[parent] = true
+
+		children := byParent[parent]
+		for i := range children {
+			children[i].Children = attach(children[i].TaskID)
+		}
+		return children
+	}
+	return attach(int(root))
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"queueit/internal/db"
+	"queueit/internal/models"
+)
+
+const activityColumns = `activity_id, task_id, action, changes, actor, source, operation_id, via, created_at`
+
+func scanActivity(rs interface{ Scan(...any) error }) (models.Activity, error) {
+	var a models.Activity
+	var changes string
+	var opID sql.NullInt64
+	if err := rs.Scan(&a.ActivityID, &a.TaskID, &a.Action, &changes, &a.Actor, &a.Source, &opID, &a.Via, &a.CreatedAt); err != nil {
+		return a, err
+	}
+	a.OperationID = opID.Int64
+	return a, json.Unmarshal([]byte(changes), &a.Changes)
+}
+
+func insertActivity(tx *db.Tx, opID int64, via string, entries []models.Activity) error {
+	for _, a := range entries {
+		changes, err := json.Marshal(a.Changes)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO task_activity (task_id, action, changes, actor, source, operation_id, via)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, a.TaskID, a.Action, string(changes), a.Actor, a.Source, opID, via); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) RecordOperation(session, kind string, entries []models.Activity) (int64, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if session != "" {
+		if _, err := tx.Exec(`UPDATE operations SET state = ? WHERE session = ? AND state = ?`,
+			models.OP_DISCARDED, session, models.OP_UNDONE); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.Exec(`INSERT INTO operations (session, kind) VALUES (?, ?)`, session, kind)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := insertActivity(tx, id, "", entries); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (s *SQLiteStore) SessionOperations(session, state string, n int) ([]models.Operation, error) {
+	order := "DESC"
+	if state == models.OP_UNDONE {
+		order = "ASC"
+	}
+	rows, err := s.db.Q(`
+		SELECT operation_id, kind, state, created_at FROM operations
+		WHERE session = ? AND state = ?
+		ORDER BY operation_id `+order+`
+		LIMIT ?
+	`, session, state, n)
+	if err != nil {
+		return nil, err
+	}
+	ops := []models.Operation{}
+	for rows.Next() {
+		var op models.Operation
+		if err := rows.Scan(&op.OperationID, &op.Kind, &op.State, &op.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ops) == 0 {
+		return ops, err
+	}
+
+	byID := map[int64]*models.Operation{}
+	ids := make([]any, len(ops))
+	for i := range ops {
+		ops[i].Changes = []models.Activity{}
+		byID[ops[i].OperationID] = &ops[i]
+		ids[i] = ops[i].OperationID
+	}
+	query := `SELECT ` + activityColumns + ` FROM task_activity
+		WHERE via = '' AND operation_id IN (` + placeholders(len(ids)) + `)
+		ORDER BY activity_id`
+	rows, err = s.db.Q(query, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		op := byID[a.OperationID]
+		op.Changes = append(op.Changes, a)
+	}
+	return ops, rows.Err()
+}
+
+func (s *SQLiteStore) SetOperationState(id int64, state string, entries []models.Activity) error {
+	via := models.VIA_UNDO
+	if state == models.OP_DONE {
+		via = models.VIA_REDO
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE operations SET state = ? WHERE operation_id = ?`, state, id); err != nil {
+		return err
+	}
+	if err := insertActivity(tx, id, via, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ListActivity(taskID int64, p Page) ([]models.Activity, string, error) {
+	before, err := activityCursor(p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	q := newSelect(`SELECT ` + activityColumns + ` FROM task_activity`)
+	if taskID != 0 {
+		q.where("task_id = ?", taskID)
+	}
+	if before != 0 {
+		q.where("activity_id < ?", before)
+	}
+	query, args := q.build()
+	query += " ORDER BY activity_id DESC"
+	if p.Limit > 0 {
+		// one extra row tells whether there is a next page
+		query += " LIMIT ?"
+		args = append(args, p.Limit+1)
+	}
+
+	rows, err := s.db.Q(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	entries := []models.Activity{}
+	for rows.Next() {
+		if p.Limit > 0 && len(entries) == p.Limit {
+			return entries, nextActivityCursor(entries[len(entries)-1].ActivityID), nil
+		}
+		a, err := scanActivity(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, a)
+	}
+	return entries, "", rows.Err()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"queueit/internal/models"
+	"time"
+)
+
+func (s *SQLiteStore) AppendEvent(typ string, task models.GetTasksResponse) (models.Event, error) {
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	e := models.Event{
+		Type:      typ,
+		TaskID:    int64(task.TaskID),
+		Task:      task,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	result, err := s.db.E(`INSERT INTO events (type, task_id, payload, created_at) VALUES (?, ?, ?, ?)`,
+		e.Type, e.TaskID, string(payload), e.CreatedAt.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return models.Event{}, err
+	}
+	if e.EventID, err = result.LastInsertId(); err != nil {
+		return models.Event{}, err
+	}
+
+	if _, err := s.db.E(`DELETE FROM events WHERE event_id <= ?`, e.EventID-EventLogSize); err != nil {
+		return models.Event{}, err
+	}
+	return e, nil
+}
+
+func (s *SQLiteStore) EventsAfter(id int64, limit int) ([]models.Event, error) {
+	rows, err := s.db.Q(`SELECT MIN(event_id) FROM events`)
+	if err != nil {
+		return nil, err
+	}
+	var oldest sql.NullInt64
+	for rows.Next() {
+		if err := rows.Scan(&oldest); err != nil {
+			rows.Close()
+			return nil, err
+		}
+	}
+	rows.Close()
+	if oldest.Valid && id < oldest.Int64-1 {
+		return nil, ErrEventsPruned
+	}
+
+	query := `
+		SELECT event_id, type, task_id, payload, created_at
+		FROM events
+		WHERE event_id > ?
+		ORDER BY event_id
+		LIMIT ?
+	`
+	rows, err = s.db.Q(query, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var e models.Event
+		var payload string
+		if err := rows.Scan(&e.EventID, &e.Type, &e.TaskID, &payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(payload), &e.Task); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (s *SQLiteStore) LastEventID() (int64, error) {
+	rows, err := s.db.Q(`SELECT COALESCE(MAX(event_id), 0) FROM events`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var id int64
+	for rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+	}
+	return id, rows.Err()
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"queueit/internal/models"
+	"strings"
+)
+
+const projectColumns = `
+	p.project_id, p.name, p.color, p.default_priority, p.archived, p.created_at,
+	(SELECT COUNT(*) FROM tasksmaster t WHERE t.project_id = p.project_id AND t.deleted_at IS NULL)
+`
+
+func (s *SQLiteStore) ListProjects(includeArchived bool) ([]models.Project, error) {
+	query := fmt.Sprintf(`SELECT %s FROM projects p`, projectColumns)
+	if !includeArchived {
+		query += " WHERE p.archived = 0"
+	}
+	query += " ORDER BY p.project_id"
+
+	rows, err := s.db.Q(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+func (s *SQLiteStore) GetProject(id int64) (models.Project, error) {
+	rows, err := s.db.Q(fmt.Sprintf(`SELECT %s FROM projects p WHERE p.project_id = ?`, projectColumns), id)
+	if err != nil {
+		return models.Project{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return models.Project{}, err
+		}
+		return models.Project{}, ErrProjectNotFound
+	}
+	return scanProject(rows)
+}
+
+func (s *SQLiteStore) CreateProject(req models.CreateProjectRequest) (int64, error) {
+	if taken, err := s.projectNameTaken(req.Name, 0); err != nil {
+		return 0, err
+	} else if taken {
+		return 0, ErrProjectExists
+	}
+
+	query := `INSERT INTO projects (name, color, default_priority) VALUES (?, ?, ?)`
+	exec_result, err := s.db.E(query, req.Name, req.Color, req.DefaultPriority)
+	if err != nil {
+		return 0, err
+	}
+	return exec_result.LastInsertId()
+}
+
+func (s *SQLiteStore) UpdateProject(id int64, req models.UpdateProjectRequest) error {
+	var fields []string
+	var args []any
+
+	if req.Name != nil {
+		if taken, err := s.projectNameTaken(*req.Name, id); err != nil {
+			return err
+		} else if taken {
+			return ErrProjectExists
+		}
+		fields = append(fields, "name = ?")
+		args = append(args, *req.Name)
+	}
+
+	if req.Color != nil {
+		fields = append(fields, "color = ?")
+		args = append(args, *req.Color)
+	}
+
+	if req.DefaultPriority != nil {
+		fields = append(fields, "default_priority = ?")
+		args = append(args, *req.DefaultPriority)
+	}
+
+	if req.Archived != nil {
+		if *req.Archived && id == models.PROJECT_INBOX {
+			return ErrInboxProtected
+		}
+		fields = append(fields, "archived = ?")
+		args = append(args, *req.Archived)
+	}
+
+	if len(fields) == 0 {
+		_, err := s.GetProject(id)
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE projects SET %s WHERE project_id = ?`, strings.Join(fields, ", "))
+	result, err := s.db.E(query, append(args, id)...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteProject(id int64) error {
+	if id == models.PROJECT_INBOX {
+		return ErrInboxProtected
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM projects WHERE project_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrProjectNotFound
+	}
+
+	query := `UPDATE tasksmaster SET project_id = ? WHERE project_id = ?`
+	if _, err := tx.Exec(query, models.PROJECT_INBOX, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// reports whether another project (other than exclude) already has this name
+func (s *SQLiteStore) projectNameTaken(name string, exclude int64) (bool, error) {
+	return s.exists(`SELECT 1 FROM projects WHERE name = ? AND project_id != ?`, name, exclude)
+}
+
+func scanProject(rs rowScanner) (models.Project, error) {
+	var p models.Project
+	var color sql.NullString
+	err := rs.Scan(&p.ProjectID, &p.Name, &color, &p.DefaultPriority, &p.Archived, &p.CreatedAt, &p.TaskCount)
+	p.Color = color.String
+	return p, err
+}
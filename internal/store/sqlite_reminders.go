@@ -0,0 +1,153 @@
+package store
+
+import (
+	"database/sql"
+	"queueit/internal/models"
+	"queueit/pkg/logger"
+	"time"
+)
+
+func (s *SQLiteStore) ListReminders(taskID int64) ([]models.Reminder, error) {
+	if _, err := s.taskProject(taskID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT r.reminder_id, r.task_id, r.offset_seconds, r.remind_at, r.delivered_at, r.created_at, t.deadline_at
+		FROM reminders r JOIN tasksmaster t ON t.task_id = r.task_id
+		WHERE r.task_id = ?
+		ORDER BY r.reminder_id
+	`
+	rows, err := s.db.Q(query, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []models.Reminder{}
+	for rows.Next() {
+		var r models.Reminder
+		var offset sql.NullInt64
+		var at, delivered, deadline sql.NullString
+		if err := rows.Scan(&r.ReminderID, &r.TaskID, &offset, &at, &delivered, &r.CreatedAt, &deadline); err != nil {
+			return nil, err
+		}
+
+		before := offsetDuration(offset)
+		times, err := parseTimes(at, delivered, deadline)
+		if err != nil {
+			return nil, err
+		}
+		r.DeliveredAt = times[1]
+		reminders = append(reminders, reminderView(r, before, times[0], times[2]))
+	}
+	return reminders, rows.Err()
+}
+
+func (s *SQLiteStore) CreateReminder(taskID int64, req models.CreateReminderRequest) (int64, error) {
+	before, at, err := parseReminder(req)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.taskProject(taskID); err != nil {
+		return 0, err
+	}
+
+	var offset, remindAt any
+	if before != nil {
+		offset = int64(before.Seconds())
+	} else {
+		remindAt = at.Format(time.RFC3339)
+	}
+
+	result, err := s.db.E(`INSERT INTO reminders (task_id, offset_seconds, remind_at) VALUES (?, ?, ?)`, taskID, offset, remindAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (s *SQLiteStore) DeleteReminder(id int64) error {
+	result, err := s.db.E(`DELETE FROM reminders WHERE reminder_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrReminderNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) PendingReminders(before time.Time) ([]models.DueReminder, error) {
+	// the fire time is worked out in seconds so only the due reminders are
+	// read, rows whose times SQLite can't read never come due
+	query := `
+		WITH fire AS (
+			SELECT r.reminder_id, r.task_id, t.title, t.deadline_at, r.offset_seconds, r.remind_at, r.delivered_for,
+				COALESCE(unixepoch(r.remind_at), unixepoch(t.deadline_at) - r.offset_seconds) AS fire_at
+			FROM reminders r JOIN tasksmaster t ON t.task_id = r.task_id
+			WHERE t.status NOT IN (?, ?) AND t.deleted_at IS NULL
+		)
+		SELECT reminder_id, task_id, title, deadline_at, offset_seconds, remind_at
+		FROM fire
+		WHERE fire_at < ? AND unixepoch(delivered_for) IS NOT fire_at
+		ORDER BY fire_at, reminder_id
+	`
+	rows, err := s.db.Q(query, models.STATUS_DONE, models.STATUS_ARCHIVED, float64(before.UnixNano())/1e9)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []models.DueReminder{}
+	for rows.Next() {
+		var d models.DueReminder
+		var offset sql.NullInt64
+		var deadline, at sql.NullString
+		if err := rows.Scan(&d.ReminderID, &d.TaskID, &d.Title, &deadline, &offset, &at); err != nil {
+			return nil, err
+		}
+
+		// one broken row must not keep the others from firing
+		times, err := parseTimes(deadline, at)
+		if err != nil {
+			logger.Error(err, "PendingReminders ~ skipping reminder", d.ReminderID, "with an unreadable time")
+			continue
+		}
+		d.DeadlineAt, d.FireAt = times[0], *reminderFireAt(offsetDuration(offset), times[1], times[0])
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+func (s *SQLiteStore) MarkReminderDelivered(id int64, fireAt time.Time) error {
+	query := `UPDATE reminders SET delivered_for = ?, delivered_at = ? WHERE reminder_id = ?`
+	result, err := s.db.E(query, fireAt.Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrReminderNotFound
+	}
+	return nil
+}
+
+func offsetDuration(offset sql.NullInt64) *time.Duration {
+	if !offset.Valid {
+		return nil
+	}
+	d := time.Duration(offset.Int64) * time.Second
+	return &d
+}
+
+// parses RFC3339 columns that may be NULL
+func parseTimes(values ...sql.NullString) ([]*time.Time, error) {
+	times := make([]*time.Time, len(values))
+	for i, v := range values {
+		var err error
+		if times[i], err = parseDeadline(v); err != nil {
+			return nil, err
+		}
+	}
+	return times, nil
+}
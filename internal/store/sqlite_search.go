@@ -0,0 +1,74 @@
+package store
+
+import (
+	"database/sql"
+	"html"
+	"queueit/internal/models"
+	"strings"
+)
+
+// bm25 with title matches weighing ten times description matches
+const searchRank = "bm25(tasks_fts, 10.0, 1.0)"
+
+// FTS5 marks the matches with these control characters, the text is
+// HTML-escaped before they are turned into <mark>
+const (
+	markOpen  = "\x02"
+	markClose = "\x03"
+)
+
+var searchColumns = []string{
+	searchRank,
+	"highlight(tasks_fts, 0, char(2), char(3))",
+	"snippet(tasks_fts, 1, char(2), char(3), '…', 12)",
+}
+
+var markReplacer = strings.NewReplacer(markOpen, "<mark>", markClose, "</mark>")
+
+func (s *SQLiteStore) Search(query string, f TaskFilter, p Page) ([]models.SearchResult, string, error) {
+	terms, err := parseSearch(query)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(p.Sort) == 0 {
+		p.Sort = []SortKey{{Field: "rank"}}
+	}
+
+	from := "tasks_fts JOIN tasksmaster t ON t.task_id = tasks_fts.rowid"
+	match := func(q *selectQuery) {
+		liveTasks(q)
+		q.where("tasks_fts MATCH ?", ftsQuery(terms))
+	}
+	rows, next, err := s.selectTasks(from, searchColumns, f, p, match)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results := []models.SearchResult{}
+	for _, r := range rows {
+		res := models.SearchResult{GetTasksResponse: r.task}
+		res.Rank, _ = r.extra[0].(float64)
+		res.Highlight = markup(nullString(r.extra[1]), r.task.Title)
+		res.Snippet = markup(nullString(r.extra[2]), r.task.Description)
+		results = append(results, res)
+	}
+	return results, next, nil
+}
+
+// the marked text of FTS5 as HTML; a source text with the marker
+// characters of its own can't be told apart from the marks, so it is
+// returned escaped without any
+func markup(marked, source string) string {
+	if strings.ContainsAny(source, markOpen+markClose) {
+		marked = strings.NewReplacer(markOpen, "", markClose, "").Replace(marked)
+		return html.EscapeString(marked)
+	}
+	return markReplacer.Replace(html.EscapeString(marked))
+}
+
+// a text column scanned into an any, NULL reads as ""
+func nullString(v any) string {
+	var s sql.NullString
+	s.Scan(v)
+	return s.String
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"queueit/internal/models"
+	"strings"
+)
+
+const tagColumns = `
+	g.tag_id, g.name, g.color, g.created_at,
+	(SELECT COUNT(*) FROM task_tags tt JOIN tasksmaster t ON t.task_id = tt.task_id
+	 WHERE tt.tag_id = g.tag_id AND t.deleted_at IS NULL)
+`
+
+func (s *SQLiteStore) ListTags() ([]models.Tag, error) {
+	rows, err := s.db.Q(fmt.Sprintf(`SELECT %s FROM tags g ORDER BY g.name`, tagColumns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func (s *SQLiteStore) GetTag(id int64) (models.Tag, error) {
+	rows, err := s.db.Q(fmt.Sprintf(`SELECT %s FROM tags g WHERE g.tag_id = ?`, tagColumns), id)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return models.Tag{}, err
+		}
+		return models.Tag{}, ErrTagNotFound
+	}
+	return scanTag(rows)
+}
+
+func (s *SQLiteStore) CreateTag(req models.CreateTagRequest) (int64, error) {
+	name, err := NormalizeTagName(req.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	if taken, err := s.tagNameTaken(name, 0); err != nil {
+		return 0, err
+	} else if taken {
+		return 0, ErrTagExists
+	}
+
+	exec_result, err := s.db.E(`INSERT INTO tags (name, color) VALUES (?, ?)`, name, req.Color)
+	if err != nil {
+		return 0, err
+	}
+	return exec_result.LastInsertId()
+}
+
+func (s *SQLiteStore) UpdateTag(id int64, req models.UpdateTagRequest) error {
+	var fields []string
+	var args []any
+
+	if req.Name != nil {
+		name, err := NormalizeTagName(*req.Name)
+		if err != nil {
+			return err
+		}
+		if taken, err := s.tagNameTaken(name, id); err != nil {
+			return err
+		} else if taken {
+			return ErrTagExists
+		}
+		fields = append(fields, "name = ?")
+		args = append(args, name)
+	}
+
+	if req.Color != nil {
+		fields = append(fields, "color = ?")
+		args = append(args, *req.Color)
+	}
+
+	if len(fields) == 0 {
+		_, err := s.GetTag(id)
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE tags SET %s WHERE tag_id = ?`, strings.Join(fields, ", "))
+	result, err := s.db.E(query, append(args, id)...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteTag(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM task_tags WHERE tag_id = ?`, id); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`DELETE FROM tags WHERE tag_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrTagNotFound
+	}
+
+	return tx.Commit()
+}
+
+// reports whether another tag (other than exclude) already has this name
+func (s *SQLiteStore) tagNameTaken(name string, exclude int64) (bool, error) {
+	return s.exists(`SELECT 1 FROM tags WHERE name = ? AND tag_id != ?`, name, exclude)
+}
+
+func scanTag(rs rowScanner) (models.Tag, error) {
+	var t models.Tag
+	var color sql.NullString
+	err := rs.Scan(&t.TagID, &t.Name, &color, &t.CreatedAt, &t.TaskCount)
+	t.Color = color.String
+	return t, err
+}
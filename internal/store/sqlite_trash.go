@@ -0,0 +1,152 @@
+package store
+
+import (
+	"database/sql"
+	"queueit/internal/db"
+	"queueit/internal/models"
+	"time"
+)
+
+func (s *SQLiteStore) ListTrash(f TaskFilter, p Page) ([]models.GetTasksResponse, string, error) {
+	// most recently deleted first unless p.Sort says otherwise
+	if len(p.Sort) == 0 {
+		p.Sort = []SortKey{{Field: "deleted_at", Desc: true}}
+	}
+
+	rows, next, err := s.selectTasks("tasksmaster t", nil, f, p, trashedTasks)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks := []models.GetTasksResponse{}
+	for _, r := range rows {
+		tasks = append(tasks, r.task)
+	}
+	return tasks, next, nil
+}
+
+func (s *SQLiteStore) Restore(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var parentID sql.NullInt64
+	var projectID int64
+	query := `SELECT parent_task_id, project_id FROM tasksmaster WHERE task_id = ? AND deleted_at IS NOT NULL`
+	err = tx.QueryRow(query, id).Scan(&parentID, &projectID)
+	if err == sql.ErrNoRows {
+		return ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if parentID.Valid {
+		var live bool
+		query = `SELECT deleted_at IS NULL FROM tasksmaster WHERE task_id = ?`
+		if err := tx.QueryRow(query, parentID.Int64).Scan(&live); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if !live {
+			return ErrParentTrashed
+		}
+	}
+
+	var archived bool
+	if err := tx.QueryRow(`SELECT archived FROM projects WHERE project_id = ?`, projectID).Scan(&archived); err == sql.ErrNoRows || archived {
+		return ErrProjectUnavailable
+	} else if err != nil {
+		return err
+	}
+
+	// the subtasks deleted along with the task, not the ones deleted before
+	query = trashSubtreeCTE + `
+		UPDATE tasksmaster SET deleted_at = NULL
+		WHERE deleted_at = (SELECT deleted_at FROM tasksmaster WHERE task_id = ?)
+			AND (task_id = ? OR task_id IN (SELECT task_id FROM subtree))
+	`
+	if _, err := tx.Exec(query, id, id, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Purge(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var trashed bool
+	err = tx.QueryRow(`SELECT deleted_at IS NOT NULL FROM tasksmaster WHERE task_id = ?`, id).Scan(&trashed)
+	if err == sql.ErrNoRows || (err == nil && !trashed) {
+		return ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	ids, err := subtreeIDs(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := purgeTasks(tx, append(ids, id)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) PurgeTrash(before time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// the subtasks of a deleted task were deleted no later than it was
+	query := `SELECT task_id FROM tasksmaster WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?)`
+	rows, err := tx.Query(query, before.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := purgeTasks(tx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), tx.Commit()
+}
+
+// deletes the tasks for good along with their links, tags and reminders
+func purgeTasks(tx *db.Tx, ids []int64) error {
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM task_children WHERE parent_id = ? OR child_id = ?`, id, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM task_tags WHERE task_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM reminders WHERE task_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM tasksmaster WHERE task_id = ?`, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
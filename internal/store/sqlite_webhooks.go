@@ -0,0 +1,341 @@
+package store
+
+import (
+	"database/sql"
+	"queueit/internal/models"
+	"strings"
+	"time"
+)
+
+const webhookColumns = `webhook_id, url, events, active, created_at, updated_at`
+
+func scanWebhook(rs interface{ Scan(...any) error }) (models.Webhook, error) {
+	var w models.Webhook
+	var events string
+	if err := rs.Scan(&w.WebhookID, &w.URL, &events, &w.Active, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return w, err
+	}
+	w.Events = splitEvents(events)
+	return w, nil
+}
+
+func splitEvents(events string) []string {
+	if events == "" {
+		return []string{}
+	}
+	return strings.Split(events, ",")
+}
+
+func (s *SQLiteStore) ListWebhooks() ([]models.Webhook, error) {
+	rows, err := s.db.Q(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY webhook_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (s *SQLiteStore) GetWebhook(id int64) (models.Webhook, error) {
+	rows, err := s.db.Q(`SELECT `+webhookColumns+` FROM webhooks WHERE webhook_id = ?`, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return models.Webhook{}, err
+		}
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return scanWebhook(rows)
+}
+
+func (s *SQLiteStore) CreateWebhook(req models.CreateWebhookRequest) (int64, string, error) {
+	req, generated, err := parseCreateWebhook(req)
+	if err != nil {
+		return 0, "", err
+	}
+	active := req.Active == nil || *req.Active
+
+	result, err := s.db.E(`INSERT INTO webhooks (url, secret, events, active) VALUES (?, ?, ?, ?)`,
+		req.URL, req.Secret, strings.Join(req.Events, ","), active)
+	if err != nil {
+		return 0, "", err
+	}
+	id, err := result.LastInsertId()
+	return id, generated, err
+}
+
+func (s *SQLiteStore) UpdateWebhook(id int64, req models.UpdateWebhookRequest) error {
+	req, err := parseUpdateWebhook(req)
+	if err != nil {
+		return err
+	}
+
+	fields := []string{"updated_at = CURRENT_TIMESTAMP"}
+	args := []any{}
+	if req.URL != nil {
+		fields = append(fields, "url = ?")
+		args = append(args, *req.URL)
+	}
+	if req.Secret != nil {
+		fields = append(fields, "secret = ?")
+		args = append(args, *req.Secret)
+	}
+	if req.Events != nil {
+		fields = append(fields, "events = ?")
+		args = append(args, strings.Join(*req.Events, ","))
+	}
+	if req.Active != nil {
+		fields = append(fields, "active = ?")
+		args = append(args, *req.Active)
+	}
+	args = append(args, id)
+
+	result, err := s.db.E(`UPDATE webhooks SET `+strings.Join(fields, ", ")+` WHERE webhook_id = ?`, args...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteWebhook(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM webhooks WHERE webhook_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) WebhookCursor() (int64, error) {
+	rows, err := s.db.Q(`SELECT last_event_id FROM webhook_cursor WHERE id = 1`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var id int64
+	for rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+	}
+	return id, rows.Err()
+}
+
+func (s *SQLiteStore) QueueDeliveries(e models.Event) error {
+	payload, err := webhookPayload(e)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT webhook_id, events FROM webhooks WHERE active = 1`)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var events string
+		if err := rows.Scan(&id, &events); err != nil {
+			rows.Close()
+			return err
+		}
+		if webhookWants(splitEvents(events), e.Type) {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, id := range ids {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, id, e.EventID, e.Type, string(payload), now); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE webhook_cursor SET last_event_id = MAX(last_event_id, ?) WHERE id = 1`, e.EventID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]models.DueDelivery, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT d.delivery_id, d.webhook_id, d.event_type, w.url, w.secret, d.payload, d.attempts
+		FROM webhook_deliveries d JOIN webhooks w ON w.webhook_id = d.webhook_id
+		WHERE d.status = ? AND d.next_attempt_at <= ? AND w.active = 1
+		ORDER BY d.next_attempt_at, d.delivery_id
+		LIMIT ?
+	`
+	rows, err := tx.Query(query, models.DELIVERY_PENDING, now.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
+	}
+	due := []models.DueDelivery{}
+	for rows.Next() {
+		var d models.DueDelivery
+		var payload string
+		if err := rows.Scan(&d.DeliveryID, &d.WebhookID, &d.EventType, &d.URL, &d.Secret, &payload, &d.Attempts); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		due = append(due, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leased := now.Add(lease).UTC().Format(time.RFC3339)
+	for _, d := range due {
+		if _, err := tx.Exec(`UPDATE webhook_deliveries SET next_attempt_at = ? WHERE delivery_id = ?`, leased, d.DeliveryID); err != nil {
+			return nil, err
+		}
+	}
+	return due, tx.Commit()
+}
+
+func (s *SQLiteStore) RecordAttempt(deliveryID int64, a models.DeliveryAttempt) error {
+	status, next, delivered := attemptOutcome(a, time.Now())
+
+	var nextAt, deliveredAt any
+	if next != nil {
+		nextAt = next.UTC().Format(time.RFC3339)
+	}
+	if delivered != nil {
+		deliveredAt = delivered.UTC().Format(time.RFC3339)
+	}
+	var code any
+	if a.StatusCode != 0 {
+		code = a.StatusCode
+	}
+
+	result, err := s.db.E(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, next_attempt_at = ?, last_status_code = ?, last_error = ?, delivered_at = ?
+		WHERE delivery_id = ?
+	`, status, nextAt, code, a.Error, deliveredAt, deliveryID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListDeliveries(webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT delivery_id, webhook_id, event_id, event_type, payload, status, attempts,
+			last_status_code, last_error, next_attempt_at, redelivery_of, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY delivery_id DESC
+		LIMIT ?
+	`
+	rows, err := s.db.Q(query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload string
+		var code sql.NullInt64
+		var lastError sql.NullString
+		var nextAt, deliveredAt sql.NullString
+		var redeliveryOf sql.NullInt64
+		if err := rows.Scan(&d.DeliveryID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&code, &lastError, &nextAt, &redeliveryOf, &d.CreatedAt, &deliveredAt); err != nil {
+			return nil, err
+		}
+
+		times, err := parseTimes(nextAt, deliveredAt)
+		if err != nil {
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		d.LastStatusCode = int(code.Int64)
+		d.LastError = lastError.String
+		d.DeliveredAt = times[1]
+		if d.Status == models.DELIVERY_PENDING {
+			d.NextAttemptAt = times[0]
+		}
+		if redeliveryOf.Valid {
+			d.RedeliveryOf = &redeliveryOf.Int64
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (s *SQLiteStore) Redeliver(webhookID, deliveryID int64) (int64, error) {
+	if _, err := s.GetWebhook(webhookID); err != nil {
+		return 0, err
+	}
+
+	result, err := s.db.E(`
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at, redelivery_of)
+		SELECT webhook_id, event_id, event_type, payload, ?, delivery_id
+		FROM webhook_deliveries
+		WHERE delivery_id = ? AND webhook_id = ?
+	`, time.Now().UTC().Format(time.RFC3339), deliveryID, webhookID)
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return 0, ErrDeliveryNotFound
+	}
+	return result.LastInsertId()
+}
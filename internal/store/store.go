@@ -0,0 +1,276 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"queueit/internal/models"
+	"queueit/internal/recur"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrParentNotFound     = errors.New("parent task not found")
+	ErrParentTrashed      = errors.New("parent task is in the trash, restore it first")
+	ErrCycle              = errors.New("task cannot be moved under itself or its own subtask")
+	ErrParentProject      = errors.New("subtask must belong to its parent's project")
+	ErrTagNotFound        = errors.New("tag not found")
+	ErrTagExists          = errors.New("tag already exists")
+	ErrInvalidTag         = errors.New("invalid tag name")
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrProjectUnavailable = errors.New("project not found or archived")
+	ErrProjectExists      = errors.New("project already exists")
+	ErrInboxProtected     = errors.New("the Inbox cannot be deleted or archived")
+	ErrInvalidSearch      = errors.New("search query contains no words to match")
+	ErrInvalidRecurrence  = recur.ErrInvalidRule
+	ErrRecurrenceDeadline = errors.New("a recurring task needs a deadline")
+	ErrReminderNotFound   = errors.New("reminder not found")
+	ErrInvalidReminder    = errors.New("invalid reminder")
+	ErrEventsPruned       = errors.New("events after the given id were pruned from the log")
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrDeliveryNotFound   = errors.New("webhook delivery not found")
+	ErrInvalidWebhook     = errors.New("invalid webhook")
+)
+
+// how many of the newest events the event log keeps
+const EventLogSize = 10000
+
+// fields TaskStore.List can sort by
+var TaskSortFields = map[string]bool{
+	"task_id":     true,
+	"title":       true,
+	"status":      true,
+	"priority":    true,
+	"project_id":  true,
+	"created_at":  true,
+	"updated_at":  true,
+	"deadline_at": true,
+}
+
+// fields SearchStore.Search can sort by, it sorts by rank when none is given
+var SearchSortFields = map[string]bool{"rank": true}
+
+// fields TrashStore.ListTrash can sort by
+var TrashSortFields = map[string]bool{"deleted_at": true}
+
+func init() {
+	for f := range TaskSortFields {
+		SearchSortFields[f] = true
+		TrashSortFields[f] = true
+	}
+}
+
+// narrows down TaskStore.List, zero values don't filter
+type TaskFilter struct {
+	Statuses   []int
+	Priorities []int
+	ProjectID  int64
+	Tags       []string
+	AllTags    bool // task must carry every tag instead of any of them
+
+	// date ranges are half-open, After is inclusive and Before exclusive
+	DeadlineAfter  *time.Time
+	DeadlineBefore *time.Time
+	CreatedAfter   *time.Time
+	UpdatedAfter   *time.Time
+	UpdatedBefore  *time.Time
+
+	HasDeadline *bool
+	Overdue     *bool // deadline passed while neither done nor archived
+}
+
+// persistence of tasks and their hierarchy
+//
+// Rules every implementation follows:
+//   - deleting a task moves its whole subtree to the trash, deleted tasks
+//     are left out of everything but TrashStore
+//   - archiving a task archives its whole subtree
+//   - a subtree never spans projects, moving a task drags its subtree along
+//   - an invalid priority on Create falls back to the project's default
+//   - unknown tag names are created on the fly
+//   - a recurring task needs a deadline, the first one starts the series;
+//     completing it creates the next occurrence (same fields and tags,
+//     deadline moved forward) which takes the rule over, along with the
+//     reminders set relative to the deadline
+//   - purging a task from the trash deletes its reminders
+type TaskStore interface {
+	// one page of tasks ordered by p.Sort (task_id breaks ties), along with
+	// the cursor of the next page, blank on the last one
+	List(f TaskFilter, p Page) ([]models.GetTasksResponse, string, error)
+	Get(id int64) (models.GetTasksResponse, error)
+	// every descendant of a task nested under its own parent
+	Subtree(id int64) ([]models.GetTasksResponse, error)
+	Create(req models.CreateTaskRequest) (int64, error)
+	// returns the id of the next occurrence when the update completed a
+	// recurring task, 0 otherwise
+	Update(id int64, req models.UpdateTaskRequest) (int64, error)
+	Delete(id int64) error
+	// re-parents a task, a nil parent moves it to the top level
+	Move(id int64, parentID *int64) error
+	// moves a task to another project, detaching it from its parent
+	MoveToProject(id, projectID int64) error
+	// writes the task's fields as given, taking it out of the trash or
+	// recreating it under its own id when it is gone; unlike Create and
+	// Update nothing is defaulted, cascaded or rolled over, it puts back
+	// states undo and redo recorded
+	PutTask(t models.GetTasksResponse) error
+	// up to n deadlines of a recurring task following its current one,
+	// empty for tasks that don't repeat
+	Occurrences(id int64, n int) ([]time.Time, error)
+}
+
+type TagStore interface {
+	ListTags() ([]models.Tag, error)
+	GetTag(id int64) (models.Tag, error)
+	CreateTag(req models.CreateTagRequest) (int64, error)
+	UpdateTag(id int64, req models.UpdateTagRequest) error
+	// detaches the tag from every task, the tasks themselves are kept
+	DeleteTag(id int64) error
+}
+
+type ProjectStore interface {
+	ListProjects(includeArchived bool) ([]models.Project, error)
+	GetProject(id int64) (models.Project, error)
+	CreateProject(req models.CreateProjectRequest) (int64, error)
+	UpdateProject(id int64, req models.UpdateProjectRequest) error
+	// moves the project's tasks to the Inbox before deleting it
+	DeleteProject(id int64) error
+}
+
+// full-text search over task titles and descriptions
+type SearchStore interface {
+	// one page of the tasks matching query and f, best match first unless
+	// p.Sort says otherwise; query is made of words, "quoted phrases" and
+	// either of them ending in * to match by prefix, every term must match
+	Search(query string, f TaskFilter, p Page) ([]models.SearchResult, string, error)
+}
+
+// reminders on tasks and their delivery bookkeeping, a reminder is
+// pending until it was delivered for its current fire time, so moving a
+// deadline re-arms the reminders relative to it
+type ReminderStore interface {
+	ListReminders(taskID int64) ([]models.Reminder, error)
+	CreateReminder(taskID int64, req models.CreateReminderRequest) (int64, error)
+	DeleteReminder(id int64) error
+	// pending reminders firing before the given time, soonest first, the
+	// reminders of done, archived and deleted tasks are left out, as are
+	// the ones whose times can't be read
+	PendingReminders(before time.Time) ([]models.DueReminder, error)
+	// records that the reminder was delivered for the given fire time
+	MarkReminderDelivered(id int64, fireAt time.Time) error
+}
+
+// the log of task changes behind the change feed, event ids only grow
+type EventStore interface {
+	// appends an event of the given type carrying the task, pruning the
+	// log down to the newest EventLogSize events
+	AppendEvent(typ string, task models.GetTasksResponse) (models.Event, error)
+	// up to limit events following the given one, oldest first, fails with
+	// ErrEventsPruned when some of them are no longer in the log
+	EventsAfter(id int64, limit int) ([]models.Event, error)
+	// the id of the newest event, 0 while the log is empty
+	LastEventID() (int64, error)
+}
+
+// webhook registrations and their delivery queue
+type WebhookStore interface {
+	ListWebhooks() ([]models.Webhook, error)
+	GetWebhook(id int64) (models.Webhook, error)
+	// returns the secret as well when it was generated
+	CreateWebhook(req models.CreateWebhookRequest) (int64, string, error)
+	UpdateWebhook(id int64, req models.UpdateWebhookRequest) error
+	// deletes the webhook along with its deliveries
+	DeleteWebhook(id int64) error
+
+	// the newest event turned into deliveries
+	WebhookCursor() (int64, error)
+	// queues a delivery of the event for every active webhook it matches
+	// and moves the cursor past it, queueing an event twice is a no-op
+	QueueDeliveries(e models.Event) error
+	// up to limit pending deliveries due at now, soonest first; they are
+	// leased for the given time so nobody else attempts them meanwhile
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]models.DueDelivery, error)
+	RecordAttempt(deliveryID int64, a models.DeliveryAttempt) error
+	// the latest deliveries of a webhook, newest first
+	ListDeliveries(webhookID int64, limit int) ([]models.WebhookDelivery, error)
+	// queues a fresh delivery of the same payload
+	Redeliver(webhookID, deliveryID int64) (int64, error)
+}
+
+// the audit trail of task changes and the operations undo and redo work
+// on, entries are never changed and outlive the tasks they are about
+type ActivityStore interface {
+	// appends the entries as one operation of session, ids and times are
+	// assigned here; the session's undone operations are discarded as they
+	// can't be redone on top of it. Returns 0 without entries
+	RecordOperation(session, kind string, entries []models.Activity) (int64, error)
+	// up to n operations of session in state, along with their entries:
+	// done ones newest first (the undo order), undone ones oldest first
+	// (the redo order)
+	SessionOperations(session, state string, n int) ([]models.Operation, error)
+	// moves an operation that was just undone or redone to state, appending
+	// the entries that did it
+	SetOperationState(id int64, state string, entries []models.Activity) error
+	// one page of entries newest first, of a single task or of every task
+	// when taskID is 0; p.Sort is not supported
+	ListActivity(taskID int64, p Page) ([]models.Activity, string, error)
+}
+
+// a run of store calls that succeed or fail together
+type TxStore interface {
+	// runs fn against a store whose changes are committed once fn returns
+	// nil and thrown away when it returns an error, other writers wait
+	// until it is done; a call that fails inside fn changes nothing, so fn
+	// may carry on after it. Tx inside fn nests
+	Tx(fn func(tx Store) error) error
+}
+
+// everything the API needs, implemented by SQLiteStore and MemoryStore
+type Store interface {
+	TaskStore
+	TagStore
+	ProjectStore
+	SearchStore
+	ReminderStore
+	EventStore
+	WebhookStore
+	ActivityStore
+	TrashStore
+	TxStore
+}
+
+// trims the name and rejects the ones that can't round-trip through ?tag=a,b
+func NormalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: tag name cannot be blank", ErrInvalidTag)
+	}
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("%w: tag name cannot contain commas", ErrInvalidTag)
+	}
+	return name, nil
+}
+
+// normalizes and de-duplicates (case-insensitively) a list of tag names
+func NormalizeTagNames(names []string) ([]string, error) {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, n := range names {
+		name, err := NormalizeTagName(n)
+		if err != nil {
+			return nil, err
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+var (
+	_ Store = (*SQLiteStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
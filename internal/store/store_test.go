@@ -0,0 +1,44 @@
+package store_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"queueit/internal/db"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// the rules of store.Store hold for both implementations, so every test
+// runs against each
+func forEachStore(t *testing.T, test func(t *testing.T, s store.Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, store.NewMemoryStore())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		s, _ := openSQLite(t)
+		test(t, s)
+	})
+}
+
+// a store on a fresh database, along with the database for the tests
+// going around the store
+func openSQLite(t *testing.T) (*store.SQLiteStore, *db.DBInfo) {
+	t.Helper()
+	if err := db.InitDB(filepath.Join(t.TempDir(), "queueit.db")); err != nil {
+		t.Fatal(err)
+	}
+	di := db.GetDBInfo()
+	t.Cleanup(func() { di.Close() })
+	return store.NewSQLiteStore(di), di
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
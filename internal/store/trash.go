@@ -0,0 +1,22 @@
+package store
+
+import (
+	"queueit/internal/models"
+	"time"
+)
+
+// the tasks TaskStore.Delete moved to the trash, kept along with their
+// tags, subtasks and reminders until they are restored or purged
+type TrashStore interface {
+	// one page of deleted tasks, f and p work the way they do for
+	// TaskStore.List but the most recently deleted come first by default
+	ListTrash(f TaskFilter, p Page) ([]models.GetTasksResponse, string, error)
+	// brings a deleted task back along with the subtasks deleted with it,
+	// its parent has to be back already and its project not archived
+	Restore(id int64) error
+	// deletes a task in the trash and its subtasks for good
+	Purge(id int64) error
+	// purges every task deleted before the given time, returns how many
+	// tasks are gone
+	PurgeTrash(before time.Time) (int, error)
+}
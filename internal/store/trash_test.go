@@ -0,0 +1,188 @@
+package store_test
+
+import (
+	"errors"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"slices"
+	"testing"
+	"time"
+)
+
+// the ids of the tasks in the trash, most recently deleted first
+func trashIDs(t *testing.T, s store.Store) []int64 {
+	t.Helper()
+	tasks, _, err := s.ListTrash(store.TaskFilter{}, store.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int64{}
+	for _, task := range tasks {
+		ids = append(ids, int64(task.TaskID))
+	}
+	return ids
+}
+
+func create(t *testing.T, s store.Store, req models.CreateTaskRequest) int64 {
+	t.Helper()
+	id, err := s.Create(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRestore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		parent := create(t, s, models.CreateTaskRequest{Title: "move house"})
+		child := create(t, s, models.CreateTaskRequest{Title: "pack books", ParentTaskID: &parent})
+		earlier := create(t, s, models.CreateTaskRequest{Title: "sell sofa", ParentTaskID: &parent})
+
+		if err := s.Delete(earlier); err != nil {
+			t.Fatal(err)
+		}
+		// deleted_at is kept to the second in SQLite
+		time.Sleep(time.Second)
+		if err := s.Delete(parent); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Get(child); !errors.Is(err, store.ErrTaskNotFound) {
+			t.Errorf("Get of a subtask of a deleted task = %v, want ErrTaskNotFound", err)
+		}
+		if got := trashIDs(t, s); len(got) != 3 || got[2] != earlier {
+			t.Errorf("trash = %v, want the 3 tasks with %d deleted first last", got, earlier)
+		}
+
+		// a subtask can't come back before its parent
+		if err := s.Restore(child); !errors.Is(err, store.ErrParentTrashed) {
+			t.Errorf("Restore of the subtask = %v, want ErrParentTrashed", err)
+		}
+		if err := s.Restore(parent); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range []int64{parent, child} {
+			if task, err := s.Get(id); err != nil || task.DeletedAt != nil {
+				t.Errorf("task %d after the restore: %+v %v", id, task.DeletedAt, err)
+			}
+		}
+		// the subtask deleted on its own stays in the trash
+		if got := trashIDs(t, s); !slices.Equal(got, []int64{earlier}) {
+			t.Errorf("trash = %v, want only %d", got, earlier)
+		}
+
+		if err := s.Restore(parent); !errors.Is(err, store.ErrTaskNotFound) {
+			t.Errorf("Restore of a live task = %v, want ErrTaskNotFound", err)
+		}
+	})
+}
+
+func TestRestoreIntoArchivedProject(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		project, err := s.CreateProject(models.CreateProjectRequest{Name: "garden", DefaultPriority: models.PRIORITY_MEDIUM})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := create(t, s, models.CreateTaskRequest{Title: "mow", ProjectID: &project})
+		if err := s.Delete(id); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpdateProject(project, models.UpdateProjectRequest{Archived: ptr(true)}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.Restore(id); !errors.Is(err, store.ErrProjectUnavailable) {
+			t.Errorf("Restore into an archived project = %v, want ErrProjectUnavailable", err)
+		}
+		if err := s.UpdateProject(project, models.UpdateProjectRequest{Archived: ptr(false)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Restore(id); err != nil {
+			t.Errorf("Restore once the project is back = %v", err)
+		}
+	})
+}
+
+func TestPurge(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		parent := create(t, s, models.CreateTaskRequest{Title: "move house"})
+		child := create(t, s, models.CreateTaskRequest{Title: "pack books", ParentTaskID: &parent})
+		kept := create(t, s, models.CreateTaskRequest{Title: "water plants"})
+
+		if err := s.Purge(parent); !errors.Is(err, store.ErrTaskNotFound) {
+			t.Errorf("Purge of a live task = %v, want ErrTaskNotFound", err)
+		}
+		for _, id := range []int64{parent, kept} {
+			if err := s.Delete(id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.Purge(parent); err != nil {
+			t.Fatal(err)
+		}
+		if got := trashIDs(t, s); !slices.Equal(got, []int64{kept}) {
+			t.Errorf("trash = %v, want only %d", got, kept)
+		}
+		for _, id := range []int64{parent, child} {
+			if err := s.Restore(id); !errors.Is(err, store.ErrTaskNotFound) {
+				t.Errorf("Restore of purged task %d = %v, want ErrTaskNotFound", id, err)
+			}
+		}
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.Store) {
+		parent := create(t, s, models.CreateTaskRequest{Title: "move house"})
+		create(t, s, models.CreateTaskRequest{Title: "pack books", ParentTaskID: &parent})
+		live := create(t, s, models.CreateTaskRequest{Title: "water plants"})
+		if err := s.Delete(parent); err != nil {
+			t.Fatal(err)
+		}
+
+		// nothing was deleted an hour ago
+		if n, err := s.PurgeTrash(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Errorf("PurgeTrash of an hour ago = %d %v, want 0", n, err)
+		}
+		if n, err := s.PurgeTrash(time.Now().Add(time.Second)); err != nil || n != 2 {
+			t.Errorf("PurgeTrash of now = %d %v, want the task and its subtask", n, err)
+		}
+		if got := trashIDs(t, s); len(got) != 0 {
+			t.Errorf("trash = %v, want it empty", got)
+		}
+		if _, err := s.Get(live); err != nil {
+			t.Errorf("the live task went along: %v", err)
+		}
+	})
+}
+
+// nothing of a purged task is left behind in the tables around it
+func TestPurgeTrashLeavesNoRows(t *testing.T) {
+	s, di := openSQLite(t)
+	parent := create(t, s, models.CreateTaskRequest{Title: "move house", Tags: []string{"home"}})
+	child := create(t, s, models.CreateTaskRequest{Title: "pack books", ParentTaskID: &parent, Tags: []string{"home"}})
+	for _, id := range []int64{parent, child} {
+		if _, err := s.CreateReminder(id, models.CreateReminderRequest{At: ptr(time.Now().Add(time.Hour))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete(parent); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PurgeTrash(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, table := range []string{"tasksmaster", "task_children", "task_tags", "reminders"} {
+		rows, err := di.Q(`SELECT COUNT(*) FROM ` + table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		rows.Next()
+		err = rows.Scan(&n)
+		rows.Close()
+		if err != nil || n != 0 {
+			t.Errorf("%d rows left in %s %v, want none", n, table, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"queueit/internal/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checks the webhook url, an absolute http(s) one
+func parseWebhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: url %q is not an absolute http or https URL", ErrInvalidWebhook, raw)
+	}
+	return raw, nil
+}
+
+// checks and de-duplicates the event filter
+func parseWebhookEvents(names []string) ([]string, error) {
+	out := []string{}
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if !models.ValidEventTypes[n] {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, n)
+		}
+		if !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func parseWebhookSecret(secret string) (string, error) {
+	if strings.TrimSpace(secret) == "" {
+		return "", fmt.Errorf("%w: secret cannot be blank", ErrInvalidWebhook)
+	}
+	return secret, nil
+}
+
+// a random 32 byte secret, hex encoded
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validates a new webhook, generating its secret when it has none; the
+// generated secret is returned as well
+func parseCreateWebhook(req models.CreateWebhookRequest) (models.CreateWebhookRequest, string, error) {
+	var err error
+	if req.URL, err = parseWebhookURL(req.URL); err != nil {
+		return req, "", err
+	}
+	if req.Events, err = parseWebhookEvents(req.Events); err != nil {
+		return req, "", err
+	}
+
+	generated := ""
+	if req.Secret != "" {
+		if _, err := parseWebhookSecret(req.Secret); err != nil {
+			return req, "", err
+		}
+	} else {
+		if req.Secret, err = newWebhookSecret(); err != nil {
+			return req, "", err
+		}
+		generated = req.Secret
+	}
+	return req, generated, nil
+}
+
+func parseUpdateWebhook(req models.UpdateWebhookRequest) (models.UpdateWebhookRequest, error) {
+	if req.URL != nil {
+		u, err := parseWebhookURL(*req.URL)
+		if err != nil {
+			return req, err
+		}
+		req.URL = &u
+	}
+	if req.Secret != nil {
+		if _, err := parseWebhookSecret(*req.Secret); err != nil {
+			return req, err
+		}
+	}
+	if req.Events != nil {
+		events, err := parseWebhookEvents(*req.Events)
+		if err != nil {
+			return req, err
+		}
+		req.Events = &events
+	}
+	return req, nil
+}
+
+// whether a webhook filtering on events wants an event of type typ
+func webhookWants(events []string, typ string) bool {
+	return len(events) == 0 || slices.Contains(events, typ)
+}
+
+// the body POSTed to webhooks, the event as the change feed has it
+func webhookPayload(e models.Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// the status of a delivery after an attempt, along with when it is retried
+// and when it got through
+func attemptOutcome(a models.DeliveryAttempt, now time.Time) (status string, next, delivered *time.Time) {
+	switch {
+	case a.Delivered:
+		return models.DELIVERY_DELIVERED, nil, &now
+	case a.NextAttemptAt != nil:
+		return models.DELIVERY_PENDING, a.NextAttemptAt, nil
+	}
+	return models.DELIVERY_FAILED, nil, nil
+}
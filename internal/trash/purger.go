@@ -0,0 +1,60 @@
+// background purge of the trash, the Purger removes deleted tasks for good
+// once they have been in the trash longer than the retention
+package trash
+
+import (
+	"context"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"time"
+)
+
+// how often the trash is looked through
+const purgeInterval = time.Hour
+
+type Purger struct {
+	store     store.TrashStore
+	retention time.Duration
+}
+
+// a retention of 0 keeps deleted tasks until they are purged by hand
+func NewPurger(s store.TrashStore, retention time.Duration) *Purger {
+	return &Purger{
+		store:     s,
+		retention: retention,
+	}
+}
+
+// purges the trash right away and then every purgeInterval (or retention
+// when that is shorter) until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	if p.retention <= 0 {
+		logger.Info("trash purge disabled, deleted tasks are kept")
+		return
+	}
+
+	logger.Info("trash purger started, deleted tasks are kept for", p.retention)
+	ticker := time.NewTicker(min(purgeInterval, p.retention))
+	defer ticker.Stop()
+	for {
+		p.purge()
+
+		select {
+		case <-ctx.Done():
+			logger.Info("trash purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge() {
+	n, err := p.store.PurgeTrash(time.Now().Add(-p.retention))
+	if err != nil {
+		logger.Error(err, "trash purger ~ purging failed")
+		return
+	}
+	if n > 0 {
+		logger.Info("trash purger ~ purged", n, "tasks")
+	}
+}
@@ -0,0 +1,249 @@
+// outgoing webhooks, the Dispatcher turns task events into deliveries and
+// POSTs them to the registered URLs, retrying failed ones with
+// exponential backoff
+//
+// Every request carries the event as its JSON body and these headers:
+//
+//	X-Queueit-Event            the event type, e.g. task.updated
+//	X-Queueit-Delivery         the delivery id, the same on every retry
+//	X-Queueit-Signature-256    sha256=<hex HMAC-SHA256 of the body keyed with the secret>
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Queueit-Event"
+	DeliveryHeader  = "X-Queueit-Delivery"
+	SignatureHeader = "X-Queueit-Signature-256"
+)
+
+const (
+	// attempts per delivery before it is marked failed
+	MaxAttempts = 8
+
+	// wait before the first retry, doubling with every further one up to
+	// maxRetryWait: 10s, 20s, 40s ... about 20 minutes in all
+	firstRetryWait = 10 * time.Second
+	maxRetryWait   = time.Hour
+
+	// deliveries attempted at once
+	claimBatch = 10
+
+	// how long a claimed delivery is left alone by other dispatchers, longer
+	// than an attempt can take
+	claimLease = time.Minute
+
+	requestTimeout = 10 * time.Second
+
+	// how often due retries are looked for
+	pollInterval = 5 * time.Second
+)
+
+// the signature header value of body, receivers compute the same to check
+// a request came from queueit
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// whether signature is the one of body
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// how long to wait after the given (1-based) failed attempt, the first
+// retry waiting first
+func retryWait(first time.Duration, attempt int) time.Duration {
+	wait := first
+	for i := 1; i < attempt && wait < maxRetryWait; i++ {
+		wait *= 2
+	}
+	return min(wait, maxRetryWait)
+}
+
+type Dispatcher struct {
+	store  store.WebhookStore
+	bus    *events.Bus
+	client *http.Client
+	wake   chan struct{}
+
+	// firstRetryWait and pollInterval, shortened by the tests
+	firstRetry time.Duration
+	poll       time.Duration
+}
+
+func NewDispatcher(s store.WebhookStore, bus *events.Bus) *Dispatcher {
+	return &Dispatcher{
+		store:  s,
+		bus:    bus,
+		client: &http.Client{Timeout: requestTimeout},
+		wake:   make(chan struct{}, 1),
+
+		firstRetry: firstRetryWait,
+		poll:       pollInterval,
+	}
+}
+
+// queues and delivers until ctx is cancelled, deliveries left pending are
+// picked up again on the next start
+func (d *Dispatcher) Run(ctx context.Context) {
+	logger.Info("webhook dispatcher started")
+	go d.queue(ctx)
+	d.deliver(ctx)
+	logger.Info("webhook dispatcher stopped")
+}
+
+// follows the event log from the stored cursor, queueing deliveries
+func (d *Dispatcher) queue(ctx context.Context) {
+	reader, err := d.bus.Reader()
+	if err != nil {
+		logger.Error(err, "webhook dispatcher ~ opening the event log failed")
+		return
+	}
+	defer reader.Close()
+
+	cursor, err := d.store.WebhookCursor()
+	if err != nil {
+		logger.Error(err, "webhook dispatcher ~ reading the cursor failed")
+		return
+	}
+	reader.ResumeAfter(cursor)
+
+	for {
+		batch, reset, err := reader.Next(ctx, time.Minute)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Error(err, "webhook dispatcher ~ reading the event log failed")
+			sleep(ctx, d.poll)
+			continue
+		}
+		if reset {
+			logger.Error("webhook dispatcher ~ events were pruned before they could be queued")
+		}
+
+		for _, e := range batch {
+			if err := d.store.QueueDeliveries(e); err != nil {
+				logger.Error(err, "webhook dispatcher ~ queueing event", e.EventID, "failed")
+				// picked up again from the event before it
+				reader.ResumeAfter(e.EventID - 1)
+				sleep(ctx, d.poll)
+				break
+			}
+		}
+		if len(batch) > 0 {
+			select {
+			case d.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// attempts due deliveries as they come up
+func (d *Dispatcher) deliver(ctx context.Context) {
+	poll := time.NewTicker(d.poll)
+	defer poll.Stop()
+
+	for {
+		due, err := d.store.ClaimDeliveries(time.Now(), claimLease, claimBatch)
+		if err != nil {
+			logger.Error(err, "webhook dispatcher ~ claiming deliveries failed")
+		}
+
+		var wg sync.WaitGroup
+		for _, dd := range due {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(ctx, dd)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) == claimBatch && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-poll.C:
+		}
+	}
+}
+
+// POSTs one delivery and records the outcome, an attempt cut short by
+// shutdown isn't recorded and is retried once its claim runs out
+func (d *Dispatcher) attempt(ctx context.Context, dd models.DueDelivery) {
+	a := d.post(ctx, dd)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if !a.Delivered {
+		if attempts := dd.Attempts + 1; attempts < MaxAttempts {
+			next := time.Now().Add(retryWait(d.firstRetry, attempts))
+			a.NextAttemptAt = &next
+		}
+		logger.Error("webhook dispatcher ~ delivery", dd.DeliveryID, "to", dd.URL, "failed:", a.Error)
+	}
+	if err := d.store.RecordAttempt(dd.DeliveryID, a); err != nil {
+		logger.Error(err, "webhook dispatcher ~ recording delivery", dd.DeliveryID, "failed")
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, dd models.DueDelivery) models.DeliveryAttempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dd.URL, bytes.NewReader(dd.Payload))
+	if err != nil {
+		return models.DeliveryAttempt{Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "queueit-webhooks")
+	req.Header.Set(EventHeader, dd.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(dd.DeliveryID, 10))
+	req.Header.Set(SignatureHeader, Sign(dd.Secret, dd.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return models.DeliveryAttempt{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+	// drained so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	a := models.DeliveryAttempt{StatusCode: resp.StatusCode}
+	if resp.StatusCode/100 == 2 {
+		a.Delivered = true
+	} else {
+		a.Error = fmt.Sprintf("receiver answered %s", resp.Status)
+	}
+	return a
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"queueit/internal/api"
+	"queueit/internal/events"
+	"queueit/internal/models"
+	"queueit/internal/store"
+	"queueit/pkg/logger"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+const testSecret = "s3cret"
+
+// a request the receiver got
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// a webhook receiver answering with the statuses of fail for the first
+// requests and 204 after them
+type receiver struct {
+	*httptest.Server
+	got chan received
+
+	mu   sync.Mutex
+	fail []int
+}
+
+func newReceiver(t *testing.T, fail ...int) *receiver {
+	rc := &receiver{got: make(chan received, 16), fail: fail}
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.got <- received{header: r.Header.Clone(), body: body}
+
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		if len(rc.fail) > 0 {
+			w.WriteHeader(rc.fail[0])
+			rc.fail = rc.fail[1:]
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func (rc *receiver) next(t *testing.T) received {
+	t.Helper()
+	select {
+	case r := <-rc.got:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery arrived")
+		return received{}
+	}
+}
+
+// the API and a running dispatcher on one in-memory store, with retries
+// and polls shortened so the test doesn't wait on them
+type env struct {
+	t *testing.T
+	h http.Handler
+}
+
+func newEnv(t *testing.T) *env {
+	st := store.NewMemoryStore()
+	bus := events.NewBus(st)
+
+	d := NewDispatcher(st, bus)
+	d.firstRetry = 50 * time.Millisecond
+	d.poll = 20 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return &env{t: t, h: api.NewRouter(st, bus).Handler()}
+}
+
+func (e *env) call(method, path string, body, out any) {
+	e.t.Helper()
+	var r io.Reader
+	if body != nil {
+		raw, _ := json.Marshal(body)
+		r = bytes.NewReader(raw)
+	}
+	rec := httptest.NewRecorder()
+	e.h.ServeHTTP(rec, httptest.NewRequest(method, path, r))
+	if rec.Code != http.StatusOK {
+		e.t.Fatalf("%s %s: status %d: %s", method, path, rec.Code, rec.Body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			e.t.Fatal(err)
+		}
+	}
+}
+
+func (e *env) register(url string, events ...string) int64 {
+	e.t.Helper()
+	var resp models.GenricWebhookResponse
+	e.call("POST", "/v1/webhooks", models.CreateWebhookRequest{URL: url, Secret: testSecret, Events: events}, &resp)
+	return resp.WebhookID
+}
+
+func (e *env) createTask(title string) int64 {
+	e.t.Helper()
+	var resp models.GenricTaskResponse
+	e.call("POST", "/v1/tasks", models.CreateTaskRequest{Title: title}, &resp)
+	return resp.TaskID
+}
+
+// the delivery log of the webhook once its newest delivery is settled
+func (e *env) deliveries(webhookID int64) []models.WebhookDelivery {
+	e.t.Helper()
+	path := fmt.Sprintf("/v1/webhooks/%d/deliveries", webhookID)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var log []models.WebhookDelivery
+		e.call("GET", path, nil, &log)
+		if len(log) > 0 && log[0].Status != models.DELIVERY_PENDING {
+			return log
+		}
+		if time.Now().After(deadline) {
+			e.t.Fatalf("deliveries of webhook %d still pending: %+v", webhookID, log)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// checks the headers of a delivery and returns its event
+func checkRequest(t *testing.T, r received, wantType string) models.Event {
+	t.Helper()
+	if got := r.header.Get(SignatureHeader); !Verify(testSecret, r.body, got) {
+		t.Errorf("signature %q doesn't verify the body", got)
+	}
+	if got := r.header.Get(EventHeader); got != wantType {
+		t.Errorf("%s = %q, want %q", EventHeader, got, wantType)
+	}
+	if _, err := strconv.ParseInt(r.header.Get(DeliveryHeader), 10, 64); err != nil {
+		t.Errorf("%s = %q, want a delivery id", DeliveryHeader, r.header.Get(DeliveryHeader))
+	}
+	if got := r.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var e models.Event
+	if err := json.Unmarshal(r.body, &e); err != nil {
+		t.Fatalf("body %s: %v", r.body, err)
+	}
+	if e.Type != wantType {
+		t.Errorf("event type %q, want %q", e.Type, wantType)
+	}
+	return e
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"type":"task.created"}`)
+	sig := Sign(testSecret, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{"matching", testSecret, body, sig, true},
+		{"other secret", "other", body, sig, false},
+		{"changed body", testSecret, []byte(`{"type":"task.deleted"}`), sig, false},
+		{"without prefix", testSecret, body, sig[len("sha256="):], false},
+		{"blank", testSecret, body, "", false},
+	}
+	for _, tt := range tests {
+		if got := Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		4:  80 * time.Second,
+		20: maxRetryWait,
+	} {
+		if got := retryWait(firstRetryWait, attempt); got != want {
+			t.Errorf("retryWait(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	e := newEnv(t)
+	rc := newReceiver(t)
+	id := e.register(rc.URL)
+	// only sees the deletes, so gets nothing here
+	other := newReceiver(t)
+	e.register(other.URL, events.TaskDeleted)
+
+	taskID := e.createTask("buy milk")
+
+	ev := checkRequest(t, rc.next(t), events.TaskCreated)
+	if ev.TaskID != taskID || ev.Task.Title != "buy milk" {
+		t.Errorf("event = %+v, want the creation of task %d", ev, taskID)
+	}
+
+	log := e.deliveries(id)
+	if len(log) != 1 || log[0].Status != models.DELIVERY_DELIVERED || log[0].Attempts != 1 || log[0].LastStatusCode != http.StatusNoContent {
+		t.Errorf("delivery log = %+v, want one delivered attempt", log)
+	}
+	select {
+	case r := <-other.got:
+		t.Errorf("webhook for %s got a %s delivery", events.TaskDeleted, r.header.Get(EventHeader))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRetry(t *testing.T) {
+	e := newEnv(t)
+	rc := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	id := e.register(rc.URL)
+
+	e.createTask("buy milk")
+
+	// every retry is the same delivery, signed the same
+	first := rc.next(t)
+	checkRequest(t, first, events.TaskCreated)
+	for range 2 {
+		retry := rc.next(t)
+		checkRequest(t, retry, events.TaskCreated)
+		if got, want := retry.header.Get(DeliveryHeader), first.header.Get(DeliveryHeader); got != want {
+			t.Errorf("retry of delivery %s came as %s", want, got)
+		}
+		if !bytes.Equal(retry.body, first.body) {
+			t.Errorf("retry body %s, want %s", retry.body, first.body)
+		}
+	}
+
+	log := e.deliveries(id)
+	if len(log) != 1 {
+		t.Fatalf("delivery log = %+v, want one delivery", log)
+	}
+	d := log[0]
+	if d.Status != models.DELIVERY_DELIVERED || d.Attempts != 3 || d.DeliveredAt == nil || d.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v, want delivered on the third attempt", d)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	e := newEnv(t)
+	rc := newReceiver(t)
+	id := e.register(rc.URL)
+
+	e.createTask("buy milk")
+	original := rc.next(t)
+	log := e.deliveries(id)
+
+	var resp models.GenricDeliveryResponse
+	e.call("POST", fmt.Sprintf("/v1/webhooks/%d/deliveries/%d/redeliver", id, log[0].DeliveryID), nil, &resp)
+
+	again := rc.next(t)
+	checkRequest(t, again, events.TaskCreated)
+	if got := again.header.Get(DeliveryHeader); got != strconv.FormatInt(resp.DeliveryID, 10) {
+		t.Errorf("%s = %s, want the new delivery %d", DeliveryHeader, got, resp.DeliveryID)
+	}
+	if !bytes.Equal(again.body, original.body) {
+		t.Errorf("redelivered body %s, want %s", again.body, original.body)
+	}
+
+	log = e.deliveries(id)
+	if len(log) != 2 {
+		t.Fatalf("delivery log = %+v, want the original and the redelivery", log)
+	}
+	for _, d := range log {
+		if d.DeliveryID != resp.DeliveryID {
+			continue
+		}
+		if d.RedeliveryOf == nil || *d.RedeliveryOf != log[1].DeliveryID || d.Status != models.DELIVERY_DELIVERED {
+			t.Errorf("redelivery = %+v, want a delivered redelivery of %d", d, log[1].DeliveryID)
+		}
+		return
+	}
+	t.Errorf("delivery log %+v has no delivery %d", log, resp.DeliveryID)
+}
@@ -1,24 +1,205 @@
+// leveled, structured logging on log/slog, as text or JSON lines on stdout
+// and optionally in a rotating file; Debug, Info, Warn, Error and Fatal take
+// println-style arguments, a leading error becomes the err field, With and
+// the *slog.Logger it returns take key-value fields
 package logger
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+)
+
+type Options struct {
+	Level  slog.Level
+	Format string    // FORMAT_TEXT (the default) or FORMAT_JSON
+	Output io.Writer // stdout when nil
+
+	// also log to this file, rotated once it grows past MaxSize bytes with
+	// MaxFiles rotated files (path.1 the newest) kept; blank for none
+	File     string
+	MaxSize  int64
+	MaxFiles int
+}
+
 var (
-	infologger  = log.New(os.Stdout, "INFO_: ", log.Ltime|log.Ldate)
-	errorlogger = log.New(os.Stdout, "ERROR: ", log.Ltime|log.Ldate)
-	fatallogger = log.New(os.Stdout, "FATAL: ", log.Ltime|log.Ldate)
+	level = new(slog.LevelVar)
+
+	mu      sync.Mutex // guards current and file
+	current = Options{Format: FORMAT_TEXT}
+	file    *rotatingFile
+
+	std atomic.Pointer[slog.Logger]
 )
 
+func init() {
+	std.Store(build(current, nil))
+}
+
+// applies o in place of the current options, closing the previous log file
+func Setup(o Options) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if o.Format == "" {
+		o.Format = FORMAT_TEXT
+	}
+	if o.Format != FORMAT_TEXT && o.Format != FORMAT_JSON {
+		return fmt.Errorf("invalid log format %q, expected text or json", o.Format)
+	}
+
+	var f *rotatingFile
+	if o.File != "" {
+		var err error
+		if f, err = openRotating(o.File, o.MaxSize, o.MaxFiles); err != nil {
+			return err
+		}
+	}
+	if file != nil {
+		file.Close()
+	}
+
+	current, file = o, f
+	level.Set(o.Level)
+	std.Store(build(o, f))
+	return nil
+}
+
+// sends the log lines to w instead of stdout, for tools embedding the API
+// whose stdout is their own output
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current.Output = w
+	std.Store(build(current, file))
+}
+
+// drops the lines below l
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// closes the log file, later lines only go to the output
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	std.Store(build(current, nil))
+	return err
+}
+
+func build(o Options, f *rotatingFile) *slog.Logger {
+	w := o.Output
+	if w == nil {
+		w = os.Stdout
+	}
+	if f != nil {
+		w = io.MultiWriter(w, f)
+	}
+
+	ho := &slog.HandlerOptions{Level: level}
+	if o.Format == FORMAT_JSON {
+		return slog.New(slog.NewJSONHandler(w, ho))
+	}
+	return slog.New(slog.NewTextHandler(w, ho))
+}
+
+// the logger behind the package functions
+func Default() *slog.Logger {
+	return std.Load()
+}
+
+// a child logger adding the given key-value fields to every line
+func With(args ...any) *slog.Logger {
+	return Default().With(args...)
+}
+
+type ctxKey struct{}
+
+// carries l along with ctx, for the logger of a request
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// the logger NewContext put in ctx, else the default one
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+func Debug(v ...any) {
+	logln(context.Background(), slog.LevelDebug, v)
+}
+
 func Info(v ...any) {
-	infologger.Println(v...)
+	logln(context.Background(), slog.LevelInfo, v)
+}
+
+func Warn(v ...any) {
+	logln(context.Background(), slog.LevelWarn, v)
 }
 
 func Error(v ...any) {
-	errorlogger.Println(v...)
+	logln(context.Background(), slog.LevelError, v)
 }
 
+// logs v at the error level, closes the log file and exits with status 1
 func Fatal(v ...any) {
-	fatallogger.Fatalln(v...)
+	logln(context.Background(), slog.LevelError, v)
+	Close()
+	os.Exit(1)
+}
+
+// the ...Context functions log through the logger of ctx, so the line gets
+// the fields of the request it belongs to
+func DebugContext(ctx context.Context, v ...any) {
+	logln(ctx, slog.LevelDebug, v)
+}
+
+func InfoContext(ctx context.Context, v ...any) {
+	logln(ctx, slog.LevelInfo, v)
+}
+
+func WarnContext(ctx context.Context, v ...any) {
+	logln(ctx, slog.LevelWarn, v)
+}
+
+func ErrorContext(ctx context.Context, v ...any) {
+	logln(ctx, slog.LevelError, v)
+}
+
+// logs v joined like fmt.Println, a leading error goes into the err field
+func logln(ctx context.Context, l slog.Level, v []any) {
+	log := FromContext(ctx)
+	if !log.Enabled(ctx, l) {
+		return
+	}
+
+	var attrs []slog.Attr
+	if len(v) > 0 {
+		if err, ok := v[0].(error); ok {
+			attrs = append(attrs, slog.Any("err", err))
+			v = v[1:]
+		}
+	}
+	msg := strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+	log.LogAttrs(ctx, l, msg, attrs...)
 }
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// the lines of Setup go to the output and the file, a leading error being
+// the err field
+func TestSetupFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queueit.log")
+	var out strings.Builder
+	if err := Setup(Options{Format: FORMAT_JSON, Output: &out, File: path}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Setup(Options{}) })
+
+	Debug("hidden")
+	Error(os.ErrNotExist, "opening", "queueit.db")
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	Info("only on the output")
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(raw)
+	for _, want := range []string{`"level":"ERROR"`, `"msg":"opening queueit.db"`, `"err":"file does not exist"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("file line %s doesn't have %s", line, want)
+		}
+	}
+	if strings.Contains(line, "hidden") || strings.Count(line, "\n") != 1 {
+		t.Errorf("file = %q, want only the error line", line)
+	}
+	if !strings.HasPrefix(out.String(), line) || !strings.Contains(out.String(), "only on the output") {
+		t.Errorf("output = %q, want the error line and the one after Close", out.String())
+	}
+
+	if err := Setup(Options{Format: "xml"}); err == nil {
+		t.Error("Setup took the xml format")
+	}
+}
+
+// Fatal logs and exits, so it runs in a child process of the test
+func TestFatal(t *testing.T) {
+	if os.Getenv("LOGGER_TEST_FATAL") == "1" {
+		Setup(Options{Output: os.Stdout})
+		Fatal(os.ErrNotExist, "startup failed")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal$")
+	cmd.Env = append(os.Environ(), "LOGGER_TEST_FATAL=1")
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || exit.ExitCode() != 1 {
+		t.Fatalf("Fatal ended the process with %v, want exit status 1", err)
+	}
+	if !strings.Contains(string(out), `level=ERROR msg="startup failed" err="file does not exist"`) {
+		t.Errorf("Fatal logged %q", out)
+	}
+}
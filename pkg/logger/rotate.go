@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// how big a log file grows when Options.MaxSize isn't set
+const defaultMaxSize = 10 << 20
+
+// a log file that is renamed to path.1 (path.1 to path.2 and so on) once
+// a line would take it past maxSize
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	f    *os.File
+	size int64
+}
+
+func openRotating(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: max(maxFiles, 0)}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// every slog line is a single Write, so lines never straddle two files
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil && r.f == nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// shifts the rotated files up by one, dropping the oldest, and starts a
+// new file; when a rename fails the current file is reopened and grows on
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	var err error
+	if r.maxFiles == 0 {
+		err = os.Remove(r.path)
+	} else {
+		for i := r.maxFiles - 1; i >= 1 && ignoreMissing(err) == nil; i-- {
+			err = os.Rename(r.rotated(i), r.rotated(i+1))
+		}
+		if ignoreMissing(err) == nil {
+			err = os.Rename(r.path, r.rotated(1))
+		}
+	}
+	if oerr := r.open(); oerr != nil {
+		return oerr
+	}
+	return ignoreMissing(err)
+}
+
+func ignoreMissing(err error) error {
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *rotatingFile) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
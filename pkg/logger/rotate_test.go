@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// the contents of path and its rotated files, "" for the missing ones
+func logFiles(t *testing.T, path string, rotated int) []string {
+	t.Helper()
+	var contents []string
+	for i := 0; i <= rotated; i++ {
+		name := path
+		if i > 0 {
+			name = (&rotatingFile{path: path}).rotated(i)
+		}
+		raw, err := os.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(raw))
+	}
+	return contents
+}
+
+func write(t *testing.T, r *rotatingFile, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := r.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "queueit.log")
+	// room for two lines of 5 bytes
+	r, err := openRotating(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	write(t, r, "aaaa", "bbbb", "cccc", "dddd", "eeee", "ffff", "gggg")
+
+	// the oldest lines are gone with the third file
+	want := []string{"gggg\n", "eeee\nffff\n", "cccc\ndddd\n", ""}
+	for i, got := range logFiles(t, path, 3) {
+		if got != want[i] {
+			t.Errorf("file %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// a line longer than the limit still goes in a file of its own
+func TestRotationLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queueit.log")
+	r, err := openRotating(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	long := strings.Repeat("x", 20)
+	write(t, r, long, "aaaa")
+	want := []string{"aaaa\n", long + "\n"}
+	for i, got := range logFiles(t, path, 1) {
+		if got != want[i] {
+			t.Errorf("file %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestRotationWithoutKeeping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queueit.log")
+	r, err := openRotating(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	write(t, r, "aaaa", "bbbb", "cccc")
+	want := []string{"cccc\n", ""}
+	for i, got := range logFiles(t, path, 1) {
+		if got != want[i] {
+			t.Errorf("file %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// reopening carries on with the size the file already has
+func TestRotationAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queueit.log")
+	if err := os.WriteFile(path, []byte("aaaa\nbbbb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := openRotating(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	write(t, r, "cccc")
+	want := []string{"cccc\n", "aaaa\nbbbb\n"}
+	for i, got := range logFiles(t, path, 1) {
+		if got != want[i] {
+			t.Errorf("file %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	r.Close()
+	if _, err := r.Write([]byte("dddd\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}